@@ -5,7 +5,7 @@
  * @documentReference: /docs/api-health-controller.md
  * @stateFlow: 无状态，实时查询系统状态
  * @rules: 健康检查接口必须快速响应，系统信息不包含敏感数据，监控指标格式标准化
- * @dependencies: controllers/response.go
+ * @dependencies: controllers/response.go, service/workflow_engine.go
  * @refs: api/controllers/
  */
 
@@ -16,6 +16,8 @@ import (
 	"runtime"
 	"time"
 
+	"flow-service/service"
+
 	"github.com/go-chi/render"
 )
 
@@ -113,8 +115,16 @@ func (hc *HealthController) GetVersion(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} APIResponse{data=map[string]interface{}} "服务就绪"
+// @Failure 503 {object} APIResponse "服务正在关闭"
 // @Router /ready [get]
 func (hc *HealthController) GetReadiness(w http.ResponseWriter, r *http.Request) {
+	// 优雅关闭开始后立即返回未就绪，使流量不再路由到本实例
+	if service.IsShuttingDown() {
+		render.Status(r, http.StatusServiceUnavailable)
+		render.Render(w, r, ErrorResponse(http.StatusServiceUnavailable, "服务正在关闭", nil))
+		return
+	}
+
 	readinessStatus := map[string]interface{}{
 		"ready":     true,
 		"timestamp": time.Now().Unix(),
@@ -180,6 +190,13 @@ func (hc *HealthController) GetMetrics(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
+	// 执行引擎指标，包含各命名并发池的当前占用和上限
+	if engine := service.GlobalEngine; engine != nil {
+		metrics["engine"] = map[string]interface{}{
+			"concurrency_pools": engine.GetConcurrencyPoolUsage(),
+		}
+	}
+
 	response := SuccessResponse("获取监控指标成功", metrics)
 	render.Status(r, http.StatusOK)
 	render.Render(w, r, response)
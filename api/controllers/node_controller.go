@@ -12,7 +12,10 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"sync"
@@ -24,11 +27,23 @@ import (
 	"github.com/go-chi/render"
 )
 
+// 单节点测试执行限制
+const (
+	defaultNodeTestTimeout     = 30 * time.Second
+	maxNodeTestTimeout         = 2 * time.Minute
+	defaultNodeTestOutputBytes = 1 << 20 // 1MB
+	maxNodeTestOutputBytes     = 8 << 20 // 8MB
+	maxConcurrentNodeTests     = 4
+)
+
 // NodeController 节点控制器
 type NodeController struct {
 	registry     *nodes.NodeRegistry
 	dynamicCache map[string]*CacheEntry
 	cacheMutex   sync.RWMutex
+
+	// 进行中的测试执行槽位，插件实际返回后才释放，不响应取消的插件超时后仍占用槽位
+	testSlots chan struct{}
 }
 
 // CacheEntry 缓存条目
@@ -43,6 +58,7 @@ func NewNodeController() *NodeController {
 	return &NodeController{
 		registry:     nodes.GetRegistry(),
 		dynamicCache: make(map[string]*CacheEntry),
+		testSlots:    make(chan struct{}, maxConcurrentNodeTests),
 	}
 }
 
@@ -239,6 +255,202 @@ func (nc *NodeController) GetDynamicData(w http.ResponseWriter, r *http.Request)
 	render.Render(w, r, SuccessResponse("获取动态数据成功", response))
 }
 
+// ExecuteNode 测试执行单个节点
+// @Summary 测试执行单个节点
+// @Description 使用给定的插件配置和示例输入数据执行节点，执行时间、读取的数据量和输出大小受限，同时进行的测试执行数受限，返回节点输出、日志、指标和耗时
+// @Tags nodes
+// @Accept json
+// @Produce json
+// @Param id path string true "节点ID"
+// @Param request body NodeExecuteRequest true "节点测试执行请求"
+// @Success 200 {object} controllers.APIResponse{data=NodeExecuteResult}
+// @Failure 400 {object} controllers.APIResponse
+// @Failure 404 {object} controllers.APIResponse
+// @Failure 429 {object} controllers.APIResponse
+// @Router /nodes/{id}/execute [post]
+func (nc *NodeController) ExecuteNode(w http.ResponseWriter, r *http.Request) {
+	nodeID := chi.URLParam(r, "id")
+	if nodeID == "" {
+		render.Status(r, http.StatusBadRequest)
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "节点ID不能为空", nil))
+		return
+	}
+
+	var request NodeExecuteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "无效的JSON格式", err))
+		return
+	}
+
+	// 获取节点插件
+	plugin, err := nc.registry.Get(nodeID)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.Render(w, r, ErrorResponse(http.StatusNotFound, "节点不存在", err))
+		return
+	}
+
+	if request.Config == nil {
+		request.Config = make(map[string]interface{})
+	}
+	if err := plugin.Validate(request.Config); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "配置验证失败", err))
+		return
+	}
+
+	timeout := request.Timeout
+	if timeout <= 0 {
+		timeout = defaultNodeTestTimeout
+	}
+	if timeout > maxNodeTestTimeout {
+		timeout = maxNodeTestTimeout
+	}
+	maxOutputBytes := request.MaxOutputBytes
+	if maxOutputBytes <= 0 {
+		maxOutputBytes = defaultNodeTestOutputBytes
+	}
+	if maxOutputBytes > maxNodeTestOutputBytes {
+		maxOutputBytes = maxNodeTestOutputBytes
+	}
+
+	select {
+	case nc.testSlots <- struct{}{}:
+	default:
+		render.Status(r, http.StatusTooManyRequests)
+		render.Render(w, r, ErrorResponse(http.StatusTooManyRequests, "节点测试执行过多，请稍后重试", nil))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	// 插件读取的外部数据不超过输出上限，避免先在内存中构建完整输出再截断
+	ctx = nodes.WithReadLimit(ctx, int64(maxOutputBytes))
+
+	input := &nodes.NodeInput{
+		Data:      request.Data,
+		Config:    request.Config,
+		Context:   map[string]interface{}{"test": true},
+		Variables: request.Variables,
+	}
+	if input.Data == nil {
+		input.Data = make(map[string]interface{})
+	}
+	if input.Variables == nil {
+		input.Variables = make(map[string]interface{})
+	}
+
+	start := time.Now()
+	output, err := executeNodePlugin(ctx, plugin, input, func() { <-nc.testSlots })
+	duration := time.Since(start)
+
+	if errors.Is(err, nodes.ErrEngineExecuted) {
+		render.Status(r, http.StatusBadRequest)
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "控制节点由执行引擎执行，不支持单独测试", err))
+		return
+	}
+
+	if output == nil {
+		output = &nodes.NodeOutput{}
+	}
+	if err != nil {
+		output.Success = false
+		if output.Error == "" {
+			output.Error = err.Error()
+		}
+	}
+	output.Duration = duration
+
+	result := NodeExecuteResult{
+		NodeID:    nodeID,
+		Output:    output,
+		Truncated: limitNodeOutputSize(output, maxOutputBytes),
+	}
+
+	message := "节点执行成功"
+	if !output.Success {
+		message = "节点执行失败"
+	}
+
+	render.Render(w, r, SuccessResponse(message, result))
+}
+
+// executeNodePlugin 在超时控制下执行节点插件，插件不响应取消时按超时返回，插件异常转换为错误；
+// release 在插件实际返回后调用，超时返回时插件仍在运行则继续占用测试槽位
+func executeNodePlugin(ctx context.Context, plugin nodes.NodePlugin, input *nodes.NodeInput, release func()) (*nodes.NodeOutput, error) {
+	type executeResult struct {
+		output *nodes.NodeOutput
+		err    error
+	}
+
+	done := make(chan executeResult, 1)
+	go func() {
+		defer release()
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- executeResult{err: fmt.Errorf("node panicked: %v", recovered)}
+			}
+		}()
+		output, err := plugin.Execute(ctx, input)
+		done <- executeResult{output: output, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		if result.err == nil && result.output == nil {
+			return nil, fmt.Errorf("node plugin returned nil output")
+		}
+		return result.output, result.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("node execution timed out: %w", ctx.Err())
+	}
+}
+
+// limitNodeOutputSize 限制输出数据的JSON大小，超出时逐步截短数组类型的端口数据，仍超出时丢弃输出数据，返回是否截断
+func limitNodeOutputSize(output *nodes.NodeOutput, maxBytes int) bool {
+	if output.Data == nil || encodedSize(output.Data) <= maxBytes {
+		return false
+	}
+
+	for {
+		shrunk := false
+		for port, value := range output.Data {
+			switch list := value.(type) {
+			case []interface{}:
+				if len(list) > 1 {
+					output.Data[port] = list[:len(list)/2]
+					shrunk = true
+				}
+			case []map[string]interface{}:
+				if len(list) > 1 {
+					output.Data[port] = list[:len(list)/2]
+					shrunk = true
+				}
+			}
+		}
+		if encodedSize(output.Data) <= maxBytes {
+			break
+		}
+		if !shrunk {
+			output.Data = nil
+			break
+		}
+	}
+
+	output.Logs = append(output.Logs, fmt.Sprintf("输出数据超过 %d 字节，已截断", maxBytes))
+	return true
+}
+
+// encodedSize 计算数据的JSON编码大小，无法编码时视为无限大
+func encodedSize(data interface{}) int {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return int(^uint(0) >> 1)
+	}
+	return len(encoded)
+}
+
 // getCacheKey 生成缓存键
 func (nc *NodeController) getCacheKey(nodeID, method string, params map[string]interface{}) string {
 	paramsBytes, _ := json.Marshal(params)
@@ -321,6 +533,22 @@ type ValidationResult struct {
 	Error  string                 `json:"error,omitempty"`
 }
 
+// NodeExecuteRequest 节点测试执行请求
+type NodeExecuteRequest struct {
+	Config         map[string]interface{} `json:"config"`                                  // 插件配置
+	Data           map[string]interface{} `json:"data"`                                    // 示例输入数据，按输入端口ID索引
+	Variables      map[string]interface{} `json:"variables,omitempty"`                     // 执行变量
+	Timeout        time.Duration          `json:"timeout,omitempty" swaggertype:"integer"` // 执行超时，默认30秒，最长2分钟
+	MaxOutputBytes int                    `json:"max_output_bytes,omitempty"`              // 输出数据JSON大小上限，默认1MB，最大8MB
+}
+
+// NodeExecuteResult 节点测试执行结果
+type NodeExecuteResult struct {
+	NodeID    string            `json:"node_id"`
+	Output    *nodes.NodeOutput `json:"output"`
+	Truncated bool              `json:"truncated"` // 输出数据是否因超出大小上限被截断
+}
+
 // CategoriesResponse 分类响应
 type CategoriesResponse struct {
 	Categories []CategoryInfo `json:"categories"`
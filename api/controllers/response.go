@@ -72,6 +72,15 @@ func ErrorResponse(httpStatus int, msg string, err error) render.Renderer {
 	return response
 }
 
+// ValidationErrorResponse 创建校验失败响应，Data 为完整的校验结果，便于前端逐项定位
+func ValidationErrorResponse(msg string, result interface{}) render.Renderer {
+	return &APIResponse{
+		Status: http.StatusBadRequest,
+		Msg:    msg,
+		Data:   result,
+	}
+}
+
 // PaginatedSuccessResponse 创建分页成功响应
 func PaginatedSuccessResponse(msg string, data interface{}, total int64, page, size int) render.Renderer {
 	return &PaginatedResponse{
@@ -13,6 +13,8 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
@@ -66,6 +68,9 @@ func (c *WorkflowController) CreateWorkflow(w http.ResponseWriter, r *http.Reque
 
 	// 创建工作流
 	if err := c.workflowService.CreateWorkflow(&workflow); err != nil {
+		if renderValidationError(w, r, "工作流校验失败", err) {
+			return
+		}
 		render.Render(w, r, ErrorResponse(http.StatusInternalServerError, "创建工作流失败", err))
 		return
 	}
@@ -73,6 +78,37 @@ func (c *WorkflowController) CreateWorkflow(w http.ResponseWriter, r *http.Reque
 	render.Render(w, r, SuccessResponse("工作流创建成功", workflow))
 }
 
+// ValidateWorkflow 校验工作流
+// @Summary 校验工作流
+// @Description 校验工作流图：环、悬空边、未注册插件、插件配置、未连接的必需输入端口和端口数据类型，错误和警告按节点/边ID定位
+// @Tags workflows
+// @Accept json
+// @Produce json
+// @Param workflow body models.Workflow true "工作流定义"
+// @Success 200 {object} APIResponse{data=service.WorkflowValidationResult}
+// @Failure 400 {object} APIResponse
+// @Router /workflows/validate [post]
+func (c *WorkflowController) ValidateWorkflow(w http.ResponseWriter, r *http.Request) {
+	var workflow models.Workflow
+	if err := json.NewDecoder(r.Body).Decode(&workflow); err != nil {
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "无效的JSON格式", err))
+		return
+	}
+
+	result := c.workflowService.ValidateWorkflow(&workflow)
+	render.Render(w, r, SuccessResponse("工作流校验完成", result))
+}
+
+// renderValidationError 工作流图校验失败时返回 400 和完整的校验结果
+func renderValidationError(w http.ResponseWriter, r *http.Request, msg string, err error) bool {
+	var validationErr *service.WorkflowValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+	render.Render(w, r, ValidationErrorResponse(msg, validationErr.Result))
+	return true
+}
+
 // GetWorkflow 获取工作流
 // @Summary 获取工作流
 // @Description 根据ID获取工作流详情
@@ -127,6 +163,9 @@ func (c *WorkflowController) UpdateWorkflow(w http.ResponseWriter, r *http.Reque
 
 	workflow.ID = id
 	if err := c.workflowService.UpdateWorkflow(&workflow); err != nil {
+		if renderValidationError(w, r, "工作流校验失败", err) {
+			return
+		}
 		render.Render(w, r, ErrorResponse(http.StatusInternalServerError, "更新工作流失败", err))
 		return
 	}
@@ -219,6 +258,9 @@ func (c *WorkflowController) ActivateWorkflow(w http.ResponseWriter, r *http.Req
 	}
 
 	if err := c.workflowService.ActivateWorkflow(id); err != nil {
+		if renderValidationError(w, r, "工作流校验失败，无法激活", err) {
+			return
+		}
 		render.Render(w, r, ErrorResponse(http.StatusInternalServerError, "激活工作流失败", err))
 		return
 	}
@@ -308,16 +350,17 @@ func (c *WorkflowController) ResumeWorkflow(w http.ResponseWriter, r *http.Reque
 
 // TriggerExecution 触发执行
 // @Summary 触发工作流执行
-// @Description 手动触发工作流执行
+// @Description 手动触发工作流执行，工作流声明了输入参数时按参数定义校验 input 并补齐默认值，校验失败返回字段级错误
 // @Tags executions
 // @Accept json
 // @Produce json
 // @Param id path string true "工作流ID"
 // @Param request body TriggerExecutionRequest true "触发执行请求"
 // @Success 200 {object} APIResponse{data=models.Execution}
-// @Failure 400 {object} APIResponse
+// @Failure 400 {object} APIResponse{data=models.InputValidationError}
 // @Failure 404 {object} APIResponse
 // @Failure 500 {object} APIResponse
+// @Failure 503 {object} APIResponse
 // @Router /workflows/{id}/trigger [post]
 func (c *WorkflowController) TriggerExecution(w http.ResponseWriter, r *http.Request) {
 	workflowID := chi.URLParam(r, "id")
@@ -354,9 +397,28 @@ func (c *WorkflowController) TriggerExecution(w http.ResponseWriter, r *http.Req
 			Input:     request.Input,
 		},
 		Priority: request.Priority,
+		Timeout:  request.Timeout,
+
+		LogicalDate:       request.LogicalDate,
+		DataIntervalStart: request.DataIntervalStart,
+		DataIntervalEnd:   request.DataIntervalEnd,
 	}
 
 	if err := c.executionService.CreateExecution(execution); err != nil {
+		if errors.Is(err, models.ErrInvalidDataInterval) {
+			render.Render(w, r, ErrorResponse(http.StatusBadRequest, "数据区间无效", err))
+			return
+		}
+		var inputErr *models.InputValidationError
+		if errors.As(err, &inputErr) {
+			render.Render(w, r, ValidationErrorResponse("输入参数校验失败", inputErr))
+			return
+		}
+		if errors.Is(err, service.ErrEngineDraining) {
+			render.Status(r, http.StatusServiceUnavailable)
+			render.Render(w, r, ErrorResponse(http.StatusServiceUnavailable, "服务正在关闭，暂不接收新执行", err))
+			return
+		}
 		render.Render(w, r, ErrorResponse(http.StatusInternalServerError, "创建执行记录失败", err))
 		return
 	}
@@ -370,6 +432,66 @@ func (c *WorkflowController) TriggerExecution(w http.ResponseWriter, r *http.Req
 	render.Render(w, r, SuccessResponse("执行触发成功", execution))
 }
 
+// SimulateWorkflow 模拟运行工作流
+// @Summary 模拟运行工作流
+// @Description 使用真实调度逻辑同步运行工作流，mocks 中的节点直接返回模拟输出，输出类节点、子流程节点和非只读的数据源节点（如非 GET 的 API 请求、写入型 SQL）不实际执行，不创建执行记录；返回每个节点的输入输出和选中的分支
+// @Tags executions
+// @Accept json
+// @Produce json
+// @Param id path string true "工作流ID"
+// @Param request body SimulateWorkflowRequest true "模拟运行请求"
+// @Success 200 {object} APIResponse{data=service.SimulationResult}
+// @Failure 400 {object} APIResponse{data=models.InputValidationError}
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /workflows/{id}/simulate [post]
+func (c *WorkflowController) SimulateWorkflow(w http.ResponseWriter, r *http.Request) {
+	workflowID := chi.URLParam(r, "id")
+	if workflowID == "" {
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "工作流ID不能为空", nil))
+		return
+	}
+
+	var request SimulateWorkflowRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "无效的JSON格式", err))
+		return
+	}
+
+	workflow, err := c.workflowService.GetWorkflow(workflowID)
+	if err != nil {
+		render.Render(w, r, ErrorResponse(http.StatusNotFound, "工作流不存在", err))
+		return
+	}
+
+	// 模拟执行只存在于内存中，不写入数据库
+	execution := &models.Execution{
+		ID:          "simulation-" + uuid.New().String(),
+		WorkflowID:  workflowID,
+		WorkflowVer: workflow.Version,
+		Status:      models.ExecutionStatusRunning,
+		TriggerType: models.TriggerTypeManual,
+		Context: &models.ExecutionContext{
+			Variables: request.Variables,
+			Input:     request.Input,
+		},
+		Timeout: request.Timeout,
+	}
+
+	result, err := c.executionService.SimulateWorkflow(r.Context(), workflow, execution, request.Mocks)
+	if err != nil {
+		var inputErr *models.InputValidationError
+		if errors.As(err, &inputErr) {
+			render.Render(w, r, ValidationErrorResponse("输入参数校验失败", inputErr))
+			return
+		}
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "模拟运行失败", err))
+		return
+	}
+
+	render.Render(w, r, SuccessResponse("模拟运行完成", result))
+}
+
 // GetExecution 获取执行记录
 // @Summary 获取执行记录
 // @Description 根据ID获取执行记录详情
@@ -463,12 +585,66 @@ func (c *WorkflowController) CancelExecution(w http.ResponseWriter, r *http.Requ
 	render.Render(w, r, SuccessResponse("执行取消成功", nil))
 }
 
+// PauseExecution 暂停执行
+// @Summary 暂停执行
+// @Description 暂停运行中的执行，不再派发新节点，在途节点继续执行完毕
+// @Tags executions
+// @Produce json
+// @Param id path string true "执行ID"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /executions/{id}/pause [post]
+func (c *WorkflowController) PauseExecution(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "执行ID不能为空", nil))
+		return
+	}
+
+	if err := c.executionService.PauseExecution(id); err != nil {
+		render.Render(w, r, ErrorResponse(http.StatusInternalServerError, "暂停执行失败", err))
+		return
+	}
+
+	render.Render(w, r, SuccessResponse("执行暂停成功", nil))
+}
+
+// ResumeExecution 恢复执行
+// @Summary 恢复执行
+// @Description 恢复已暂停的执行，继续派发准备队列中的节点
+// @Tags executions
+// @Produce json
+// @Param id path string true "执行ID"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /executions/{id}/resume [post]
+func (c *WorkflowController) ResumeExecution(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "执行ID不能为空", nil))
+		return
+	}
+
+	if err := c.executionService.ResumeExecution(id); err != nil {
+		render.Render(w, r, ErrorResponse(http.StatusInternalServerError, "恢复执行失败", err))
+		return
+	}
+
+	render.Render(w, r, SuccessResponse("执行恢复成功", nil))
+}
+
 // RetryExecution 重试执行
 // @Summary 重试执行
-// @Description 重试失败的执行
+// @Description 重试失败的执行，mode 为 full 时从头执行，为 from_failure 时复用已完成节点的输出，只重新执行失败节点及其下游节点
 // @Tags executions
+// @Accept json
 // @Produce json
 // @Param id path string true "执行ID"
+// @Param request body RetryExecutionRequest false "重试参数"
 // @Success 200 {object} APIResponse{data=models.Execution}
 // @Failure 400 {object} APIResponse
 // @Failure 404 {object} APIResponse
@@ -481,7 +657,24 @@ func (c *WorkflowController) RetryExecution(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if err := c.executionService.RetryExecution(id); err != nil {
+	// 请求体可选，默认完整重试
+	var request RetryExecutionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "无效的JSON格式", err))
+		return
+	}
+
+	var err error
+	switch request.Mode {
+	case "", RetryModeFull:
+		err = c.executionService.RetryExecution(id)
+	case RetryModeFromFailure:
+		err = c.executionService.RetryExecutionFromFailure(id)
+	default:
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "无效的重试模式", nil))
+		return
+	}
+	if err != nil {
 		render.Render(w, r, ErrorResponse(http.StatusInternalServerError, "重试执行失败", err))
 		return
 	}
@@ -496,6 +689,96 @@ func (c *WorkflowController) RetryExecution(w http.ResponseWriter, r *http.Reque
 	render.Render(w, r, SuccessResponse("执行重试成功", execution))
 }
 
+// RerunExecution 从指定节点重新运行
+// @Summary 从指定节点重新运行
+// @Description 使指定节点及其所有下游节点失效并重新执行，其余节点复用上次运行的输出
+// @Tags executions
+// @Accept json
+// @Produce json
+// @Param id path string true "执行ID"
+// @Param request body RerunExecutionRequest true "重新运行参数"
+// @Success 200 {object} APIResponse{data=models.Execution}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /executions/{id}/rerun [post]
+func (c *WorkflowController) RerunExecution(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "执行ID不能为空", nil))
+		return
+	}
+
+	var request RerunExecutionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "无效的JSON格式", err))
+		return
+	}
+
+	if request.NodeID == "" {
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "节点ID不能为空", nil))
+		return
+	}
+
+	if err := c.executionService.RerunExecutionFromNode(id, request.NodeID); err != nil {
+		render.Render(w, r, ErrorResponse(http.StatusInternalServerError, "重新运行执行失败", err))
+		return
+	}
+
+	execution, err := c.executionService.GetExecution(id)
+	if err != nil {
+		render.Render(w, r, ErrorResponse(http.StatusInternalServerError, "重新运行后获取执行记录失败", err))
+		return
+	}
+
+	render.Render(w, r, SuccessResponse("执行重新运行成功", execution))
+}
+
+// ListQueuedExecutions 列出排队中的执行
+// @Summary 列出排队中的执行
+// @Description 按预计准入顺序列出引擎并发数已满时排队等待的执行
+// @Tags executions
+// @Produce json
+// @Success 200 {object} APIResponse{data=[]service.QueuedExecution}
+// @Router /executions/queue [get]
+func (c *WorkflowController) ListQueuedExecutions(w http.ResponseWriter, r *http.Request) {
+	queued := c.executionService.ListQueuedExecutions()
+	render.Render(w, r, SuccessResponse("获取排队执行列表成功", queued))
+}
+
+// ReorderQueuedExecution 调整排队中执行的顺序
+// @Summary 调整排队中执行的顺序
+// @Description 修改排队中执行的优先级，和/或将其移到同一优先级内另一个排队执行之前（保证先于该执行准入，不修改优先级），返回调整后的预计准入顺序
+// @Tags executions
+// @Accept json
+// @Produce json
+// @Param id path string true "执行ID"
+// @Param request body ReorderQueuedExecutionRequest true "调整参数"
+// @Success 200 {object} APIResponse{data=service.QueuedExecution}
+// @Failure 400 {object} APIResponse
+// @Router /executions/{id}/queue [put]
+func (c *WorkflowController) ReorderQueuedExecution(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "执行ID不能为空", nil))
+		return
+	}
+
+	var request ReorderQueuedExecutionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "无效的JSON格式", err))
+		return
+	}
+
+	entry, err := c.executionService.ReorderQueuedExecution(id, request.Priority, request.BeforeExecutionID)
+	if err != nil {
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "调整排队顺序失败", err))
+		return
+	}
+
+	render.Render(w, r, SuccessResponse("调整排队顺序成功", entry))
+}
+
 // GetExecutionProgress 获取执行进度
 // @Summary 获取执行进度
 // @Description 获取执行的当前进度
@@ -528,6 +811,61 @@ func (c *WorkflowController) GetExecutionProgress(w http.ResponseWriter, r *http
 	render.Render(w, r, SuccessResponse("获取执行进度成功", progressResponse))
 }
 
+// GetExecutionOutput 获取执行输出结果
+// @Summary 获取执行输出结果
+// @Description 获取已完成执行的输出结果；工作流声明了输出时按输出名返回，否则为出口节点的输出
+// @Tags executions
+// @Produce json
+// @Param id path string true "执行ID"
+// @Success 200 {object} APIResponse{data=map[string]interface{}}
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Router /executions/{id}/output [get]
+func (c *WorkflowController) GetExecutionOutput(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "执行ID不能为空", nil))
+		return
+	}
+
+	output, err := c.executionService.GetExecutionOutput(id)
+	if err != nil {
+		if errors.Is(err, service.ErrExecutionNotCompleted) {
+			render.Render(w, r, ErrorResponse(http.StatusConflict, "执行未完成，没有输出结果", err))
+			return
+		}
+		render.Render(w, r, ErrorResponse(http.StatusNotFound, "执行记录不存在", err))
+		return
+	}
+
+	render.Render(w, r, SuccessResponse("获取执行输出成功", output))
+}
+
+// GetWorkflowParameters 获取工作流输入参数定义
+// @Summary 获取工作流输入参数定义
+// @Description 获取工作流声明的输入参数，用于渲染触发表单；未声明参数时返回空列表
+// @Tags workflows
+// @Produce json
+// @Param id path string true "工作流ID"
+// @Success 200 {object} APIResponse{data=[]models.WorkflowParameter}
+// @Failure 404 {object} APIResponse
+// @Router /workflows/{id}/parameters [get]
+func (c *WorkflowController) GetWorkflowParameters(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		render.Render(w, r, ErrorResponse(http.StatusBadRequest, "工作流ID不能为空", nil))
+		return
+	}
+
+	parameters, err := c.workflowService.GetWorkflowParameters(id)
+	if err != nil {
+		render.Render(w, r, ErrorResponse(http.StatusNotFound, "工作流不存在", err))
+		return
+	}
+
+	render.Render(w, r, SuccessResponse("获取工作流输入参数成功", parameters))
+}
+
 // GetWorkflowStatistics 获取工作流统计信息
 // @Summary 获取工作流统计信息
 // @Description 获取工作流的统计信息
@@ -564,6 +902,42 @@ type TriggerExecutionRequest struct {
 	Variables   map[string]interface{} `json:"variables,omitempty"`
 	Input       map[string]interface{} `json:"input,omitempty"`
 	Priority    int                    `json:"priority,omitempty"`
+	Timeout     time.Duration          `json:"timeout,omitempty" swaggertype:"integer"` // 执行超时，覆盖工作流配置的超时时间
+
+	// 逻辑日期和数据区间，用于补数等手动执行；只指定区间一端时另一端与之相同，未指定逻辑日期时取区间开始，均未指定时为触发时间
+	LogicalDate       *time.Time `json:"logical_date,omitempty"`
+	DataIntervalStart *time.Time `json:"data_interval_start,omitempty"`
+	DataIntervalEnd   *time.Time `json:"data_interval_end,omitempty"`
+}
+
+// SimulateWorkflowRequest 模拟运行请求
+type SimulateWorkflowRequest struct {
+	Variables map[string]interface{}            `json:"variables,omitempty"`
+	Input     map[string]interface{}            `json:"input,omitempty"`
+	Mocks     map[string]map[string]interface{} `json:"mocks,omitempty"`                         // 节点ID -> 模拟输出（按输出端口ID索引）
+	Timeout   time.Duration                     `json:"timeout,omitempty" swaggertype:"integer"` // 模拟运行超时，未配置时默认5分钟
+}
+
+// 重试模式
+const (
+	RetryModeFull        = "full"         // 从头执行
+	RetryModeFromFailure = "from_failure" // 从失败节点继续
+)
+
+// RetryExecutionRequest 重试执行请求
+type RetryExecutionRequest struct {
+	Mode string `json:"mode,omitempty" enums:"full,from_failure"`
+}
+
+// ReorderQueuedExecutionRequest 调整排队顺序请求，同时指定时先修改 priority 再移到 before_execution_id 之前
+type ReorderQueuedExecutionRequest struct {
+	Priority          *int   `json:"priority,omitempty"`            // 新的执行优先级，数值越大越优先
+	BeforeExecutionID string `json:"before_execution_id,omitempty"` // 移到该排队执行之前，目标须处于同一优先级
+}
+
+// RerunExecutionRequest 从指定节点重新运行请求
+type RerunExecutionRequest struct {
+	NodeID string `json:"node_id"`
 }
 
 // ProgressResponse 进度响应
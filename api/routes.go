@@ -74,6 +74,7 @@ func InitRoute(r chi.Router) {
 		// 工作流 CRUD
 		r.Post("/", workflowController.CreateWorkflow)
 		r.Get("/", workflowController.ListWorkflows)
+		r.Post("/validate", workflowController.ValidateWorkflow)
 		r.Get("/{id}", workflowController.GetWorkflow)
 		r.Put("/{id}", workflowController.UpdateWorkflow)
 		r.Delete("/{id}", workflowController.DeleteWorkflow)
@@ -86,17 +87,25 @@ func InitRoute(r chi.Router) {
 
 		// 工作流执行管理
 		r.Post("/{id}/trigger", workflowController.TriggerExecution)
+		r.Post("/{id}/simulate", workflowController.SimulateWorkflow)
 		r.Get("/{id}/executions", workflowController.ListExecutions)
 		r.Get("/{id}/statistics", workflowController.GetWorkflowStatistics)
+		r.Get("/{id}/parameters", workflowController.GetWorkflowParameters)
 	})
 
 	// 执行记录管理路由
 	r.Route("/executions", func(r chi.Router) {
 		r.Get("/", workflowController.ListExecutions)
+		r.Get("/queue", workflowController.ListQueuedExecutions)
 		r.Get("/{id}", workflowController.GetExecution)
 		r.Post("/{id}/cancel", workflowController.CancelExecution)
+		r.Post("/{id}/pause", workflowController.PauseExecution)
+		r.Post("/{id}/resume", workflowController.ResumeExecution)
 		r.Post("/{id}/retry", workflowController.RetryExecution)
+		r.Post("/{id}/rerun", workflowController.RerunExecution)
 		r.Get("/{id}/progress", workflowController.GetExecutionProgress)
+		r.Get("/{id}/output", workflowController.GetExecutionOutput)
+		r.Put("/{id}/queue", workflowController.ReorderQueuedExecution)
 	})
 
 	// 节点管理路由
@@ -104,6 +113,7 @@ func InitRoute(r chi.Router) {
 		r.Get("/", nodeController.GetNodes)
 		r.Post("/{id}/validate", nodeController.ValidateNodeConfig)
 		r.Post("/{id}/dynamic-data", nodeController.GetDynamicData)
+		r.Post("/{id}/execute", nodeController.ExecuteNode)
 	})
 
 }
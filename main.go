@@ -8,7 +8,8 @@
  *   - 使用Dapr HTTP服务模式
  *   - 支持Prometheus监控
  *   - 集成Swagger文档
- *   - 统一服务初始化和依赖注入
+ *   - 统一服务初始化和依赖注入，注册路由前调用 service.InitServices
+ *   - 收到 SIGTERM/SIGINT 时优雅关闭：就绪检查失败、排空执行引擎、停止调度器和数据库后关闭HTTP服务
  * @dependencies:
  *   - Dapr sidecar
  *   - Chi路由框架
@@ -22,14 +23,20 @@
 package main
 
 import (
+	"context"
 	"flow-service/api"
 	_ "flow-service/docs"
+	"flow-service/service"
+	"flow-service/service/config"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 
 	// 导入节点包以触发init函数
+	_ "flow-service/service/nodes/control"
 	_ "flow-service/service/nodes/datasource"
 	_ "flow-service/service/nodes/output"
 	_ "flow-service/service/nodes/transform"
@@ -60,6 +67,10 @@ func init() {
 // @description 流程服务，提供流程编排、执行、调度功能
 // @BasePath /swagger/flow-service
 func main() {
+	// 控制器在注册路由时获取全局服务实例，须先完成初始化
+	if err := service.InitServices(); err != nil {
+		log.Fatalf("%v", err)
+	}
 
 	mux := chi.NewRouter()
 
@@ -80,7 +91,25 @@ func main() {
 	}
 
 	s := daprd.NewServiceWithMux(":"+strconv.Itoa(PORT), mux)
-	if err := s.Start(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("error: %v", err)
+	go func() {
+		if err := s.Start(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("error: %v", err)
+		}
+	}()
+
+	// 等待退出信号后优雅关闭，排空期间HTTP服务继续响应状态查询
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	sig := <-signals
+	log.Printf("received signal %v, shutting down", sig)
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.LoadAppConfig().GracefulShutdownTimeout)
+	defer cancel()
+	if err := service.Shutdown(ctx); err != nil {
+		log.Printf("service shutdown error: %v", err)
+	}
+
+	if err := s.GracefulStop(); err != nil {
+		log.Printf("http server shutdown error: %v", err)
 	}
 }
@@ -0,0 +1,215 @@
+/**
+ * @module concurrency_pool
+ * @description 命名并发池，跨所有执行限制访问同一外部资源（如同一数据库）的节点同时运行的数量
+ * @architecture 执行引擎组件，每个池是一个容量为上限的信号量，节点获取全局执行槽位前先获取所需池的槽位
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow pool_slot_flow: waiting -> acquired -> released
+ * @rules 池在引擎配置中定义，也可由节点资源配置的并发限制隐式声明；节点需要多个池时按名称顺序获取，避免互相等待而死锁
+ * @dependencies service/config/engine.go, service/models/node.go
+ * @refs service/workflow_engine.go, api/controllers/health.go
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"flow-service/service/config"
+	"flow-service/service/models"
+)
+
+// 并发池来源
+const (
+	ConcurrencyPoolSourceConfig   = "config"   // 引擎配置中定义
+	ConcurrencyPoolSourceDeclared = "declared" // 由节点资源配置的并发限制声明
+)
+
+// nodeConcurrencyPoolPrefix 未声明池名但配置了并发限制的节点使用的隐式池名前缀
+const nodeConcurrencyPoolPrefix = "node:"
+
+// ConcurrencyPoolUsage 并发池使用情况
+type ConcurrencyPoolUsage struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`
+	InUse   int    `json:"in_use"`  // 当前占用的槽位数
+	Max     int    `json:"max"`     // 最大槽位数
+	Waiting int    `json:"waiting"` // 等待槽位的节点数
+}
+
+// concurrencyPool 命名并发池
+type concurrencyPool struct {
+	name    string
+	source  string
+	max     int
+	slots   chan struct{}
+	waiting int // 由管理器的锁保护
+}
+
+// newConcurrencyPool 创建并发池
+func newConcurrencyPool(name string, source string, limit int) *concurrencyPool {
+	return &concurrencyPool{
+		name:   name,
+		source: source,
+		max:    limit,
+		slots:  make(chan struct{}, limit),
+	}
+}
+
+// ConcurrencyPoolManager 并发池管理器
+type ConcurrencyPoolManager struct {
+	mu          sync.Mutex
+	pools       map[string]*concurrencyPool
+	pluginPools map[string][]string // 插件 -> 自动加入的池
+}
+
+// NewConcurrencyPoolManager 按引擎配置创建并发池管理器
+func NewConcurrencyPoolManager(configs []config.ConcurrencyPoolConfig) *ConcurrencyPoolManager {
+	m := &ConcurrencyPoolManager{
+		pools:       make(map[string]*concurrencyPool),
+		pluginPools: make(map[string][]string),
+	}
+	for _, cfg := range configs {
+		if cfg.Name == "" || cfg.MaxConcurrency < 1 {
+			continue
+		}
+		m.pools[cfg.Name] = newConcurrencyPool(cfg.Name, ConcurrencyPoolSourceConfig, cfg.MaxConcurrency)
+		for _, plugin := range cfg.Plugins {
+			m.pluginPools[plugin] = append(m.pluginPools[plugin], cfg.Name)
+		}
+	}
+	return m
+}
+
+// resolve 解析节点需要获取的并发池，按名称排序
+func (m *ConcurrencyPoolManager) resolve(workflowID string, nodeID string, node *models.Node) ([]*concurrencyPool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var resource *models.ResourceConfig
+	if node.Config != nil {
+		resource = node.Config.ResourceConfig
+	}
+
+	selected := make(map[string]*concurrencyPool)
+	for _, name := range m.pluginPools[node.Plugin] {
+		selected[name] = m.pools[name]
+	}
+
+	if resource != nil {
+		if resource.ConcurrencyLimit < 0 {
+			return nil, fmt.Errorf("invalid concurrency limit: %d", resource.ConcurrencyLimit)
+		}
+		name := resource.ConcurrencyPool
+		if name == "" && resource.ConcurrencyLimit > 0 {
+			name = nodeConcurrencyPoolPrefix + workflowID + "/" + nodeID
+		}
+		if name != "" {
+			pool, err := m.declaredPool(name, resource.ConcurrencyLimit)
+			if err != nil {
+				return nil, err
+			}
+			selected[name] = pool
+		}
+	}
+
+	pools := make([]*concurrencyPool, 0, len(selected))
+	for _, pool := range selected {
+		pools = append(pools, pool)
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].name < pools[j].name
+	})
+	return pools, nil
+}
+
+// declaredPool 获取节点声明的并发池，未在配置中定义时按节点的并发限制创建（调用方需持有锁）
+func (m *ConcurrencyPoolManager) declaredPool(name string, limit int) (*concurrencyPool, error) {
+	pool, exists := m.pools[name]
+	if exists && (pool.source == ConcurrencyPoolSourceConfig || limit == 0 || limit == pool.max) {
+		return pool, nil
+	}
+	if limit == 0 {
+		return nil, fmt.Errorf("concurrency pool %s is not configured and node declares no concurrency limit", name)
+	}
+
+	// 上限变化时只替换空闲的池，占用中的池保持原上限直到空闲
+	if exists && (len(pool.slots) > 0 || pool.waiting > 0) {
+		return pool, nil
+	}
+	pool = newConcurrencyPool(name, ConcurrencyPoolSourceDeclared, limit)
+	m.pools[name] = pool
+	return pool, nil
+}
+
+// Acquire 按顺序获取节点所需的全部并发池槽位，返回释放函数；上下文取消或 stop 关闭时释放已获取的槽位
+func (m *ConcurrencyPoolManager) Acquire(ctx context.Context, stop <-chan struct{}, workflowID string, nodeID string, node *models.Node) (func(), error) {
+	pools, err := m.resolve(workflowID, nodeID, node)
+	if err != nil {
+		return nil, err
+	}
+
+	acquired := make([]*concurrencyPool, 0, len(pools))
+	release := func() {
+		for i := len(acquired) - 1; i >= 0; i-- {
+			<-acquired[i].slots
+		}
+	}
+
+	for _, pool := range pools {
+		// 有空闲槽位时直接获取，不计入等待
+		select {
+		case pool.slots <- struct{}{}:
+			acquired = append(acquired, pool)
+			continue
+		default:
+		}
+
+		m.setWaiting(pool, 1)
+		select {
+		case pool.slots <- struct{}{}:
+			m.setWaiting(pool, -1)
+			acquired = append(acquired, pool)
+		case <-ctx.Done():
+			m.setWaiting(pool, -1)
+			release()
+			return nil, ctx.Err()
+		case <-stop:
+			m.setWaiting(pool, -1)
+			release()
+			return nil, errExecutionStopped
+		}
+	}
+
+	return release, nil
+}
+
+// setWaiting 调整池的等待数
+func (m *ConcurrencyPoolManager) setWaiting(pool *concurrencyPool, delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pool.waiting += delta
+}
+
+// Usage 返回各并发池的当前占用和上限，按名称排序
+func (m *ConcurrencyPoolManager) Usage() []ConcurrencyPoolUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usage := make([]ConcurrencyPoolUsage, 0, len(m.pools))
+	for _, pool := range m.pools {
+		usage = append(usage, ConcurrencyPoolUsage{
+			Name:    pool.name,
+			Source:  pool.source,
+			InUse:   len(pool.slots),
+			Max:     pool.max,
+			Waiting: pool.waiting,
+		})
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Name < usage[j].Name
+	})
+	return usage
+}
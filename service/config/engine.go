@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -50,6 +51,21 @@ type EngineConfig struct {
 
 	// 性能配置
 	Performance PerformanceConfig `json:"performance"`
+
+	// 命名并发池配置，跨所有执行限制声明了该池的节点同时运行的数量
+	ConcurrencyPools []ConcurrencyPoolConfig `json:"concurrency_pools"`
+}
+
+// ConcurrencyPoolConfig 命名并发池配置
+type ConcurrencyPoolConfig struct {
+	// 并发池名称，如 warehouse-db
+	Name string `json:"name" validate:"required"`
+
+	// 池内同时运行的最大节点数
+	MaxConcurrency int `json:"max_concurrency" validate:"min=1"`
+
+	// 自动加入该池的插件，这些插件的节点无需在资源配置中声明
+	Plugins []string `json:"plugins,omitempty"`
 }
 
 // ExecutorConfig DAG执行器配置
@@ -311,6 +327,9 @@ type StorageConfig struct {
 
 	// 批量操作大小
 	BatchSize int `json:"batch_size" validate:"min=1"`
+
+	// 重启恢复策略：resume 从检查点继续执行，fail 将中断的执行标记为失败
+	RecoveryPolicy string `json:"recovery_policy" validate:"oneof=resume fail"`
 }
 
 // PerformanceConfig 性能配置
@@ -485,6 +504,7 @@ var DefaultEngineConfig = &EngineConfig{
 		StateCompression:      true,
 		ResultCompression:     true,
 		BatchSize:             100,
+		RecoveryPolicy:        "resume",
 	},
 
 	Performance: PerformanceConfig{
@@ -565,6 +585,30 @@ func LoadEngineConfig() *EngineConfig {
 		}
 	}
 
+	// 存储配置
+	if persistInterval := os.Getenv("ENGINE_STATE_PERSIST_INTERVAL"); persistInterval != "" {
+		if val, err := time.ParseDuration(persistInterval); err == nil && val >= 0 {
+			config.Storage.StatePersistInterval = val
+		}
+	}
+
+	if compression := os.Getenv("ENGINE_STATE_COMPRESSION"); compression != "" {
+		if val, err := strconv.ParseBool(compression); err == nil {
+			config.Storage.StateCompression = val
+		}
+	}
+
+	if policy := os.Getenv("ENGINE_RECOVERY_POLICY"); policy == "resume" || policy == "fail" {
+		config.Storage.RecoveryPolicy = policy
+	}
+
+	// 并发池配置，格式：name=max[:plugin1|plugin2],name=max
+	if pools := os.Getenv("ENGINE_CONCURRENCY_POOLS"); pools != "" {
+		if val, err := ParseConcurrencyPools(pools); err == nil {
+			config.ConcurrencyPools = val
+		}
+	}
+
 	// 监控配置
 	if monitorEnabled := os.Getenv("ENGINE_MONITOR_ENABLED"); monitorEnabled != "" {
 		if val, err := strconv.ParseBool(monitorEnabled); err == nil {
@@ -597,6 +641,68 @@ func (c *EngineConfig) Validate() error {
 		return fmt.Errorf("task config error: %w", err)
 	}
 
+	// 验证并发池配置
+	names := make(map[string]bool, len(c.ConcurrencyPools))
+	for i := range c.ConcurrencyPools {
+		pool := &c.ConcurrencyPools[i]
+		if err := pool.Validate(); err != nil {
+			return fmt.Errorf("concurrency pool config error: %w", err)
+		}
+		if names[pool.Name] {
+			return fmt.Errorf("concurrency pool config error: duplicate pool name: %s", pool.Name)
+		}
+		names[pool.Name] = true
+	}
+
+	return nil
+}
+
+// ParseConcurrencyPools 解析并发池配置字符串，格式：name=max[:plugin1|plugin2],name=max
+func ParseConcurrencyPools(value string) ([]ConcurrencyPoolConfig, error) {
+	var pools []ConcurrencyPoolConfig
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, spec, found := strings.Cut(item, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid concurrency pool: %s", item)
+		}
+		limit, plugins, _ := strings.Cut(spec, ":")
+		maxConcurrency, err := strconv.Atoi(strings.TrimSpace(limit))
+		if err != nil {
+			return nil, fmt.Errorf("invalid concurrency pool limit: %s", item)
+		}
+
+		pool := ConcurrencyPoolConfig{
+			Name:           strings.TrimSpace(name),
+			MaxConcurrency: maxConcurrency,
+		}
+		for _, plugin := range strings.Split(plugins, "|") {
+			if plugin = strings.TrimSpace(plugin); plugin != "" {
+				pool.Plugins = append(pool.Plugins, plugin)
+			}
+		}
+		if err := pool.Validate(); err != nil {
+			return nil, err
+		}
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+// Validate 验证并发池配置
+func (c *ConcurrencyPoolConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("pool name cannot be empty")
+	}
+
+	if c.MaxConcurrency < 1 {
+		return fmt.Errorf("max concurrency of pool %s must be positive", c.Name)
+	}
+
 	return nil
 }
 
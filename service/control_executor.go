@@ -0,0 +1,863 @@
+/**
+ * @module control_executor
+ * @description 控制节点执行器，由执行引擎直接执行循环、条件、子流程、延迟等影响调度的控制节点
+ * @architecture 循环节点在子执行上下文中复用引擎的依赖调度、边决议和节点执行逻辑，条件节点通过剪枝出边选择分支，子流程节点通过执行服务启动关联的子执行并等待结束，延迟等待以定时器重新入队实现
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow loop_states: resolving -> iterating -> collecting -> completed/failed; condition_states: evaluating -> branch_selected; subdag_states: child_running -> child_finished; wait_states: parked -> woken
+ * @rules 子图节点执行记录与父执行共享执行ID，以迭代后缀区分；子图状态不单独保存检查点
+ * @dependencies service/workflow_engine.go, service/models/node.go, service/nodes/types.go
+ * @refs service/nodes/control/loop.go, service/nodes/control/condition.go, service/nodes/control/subdag.go, service/nodes/control/delay.go
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"flow-service/service/expression"
+	"flow-service/service/models"
+	"flow-service/service/nodes"
+	"flow-service/service/nodes/control"
+)
+
+const (
+	// LoopTypeForEach 遍历数组
+	LoopTypeForEach = "foreach"
+	// LoopTypeFor 按次数循环
+	LoopTypeFor = "for"
+	// LoopTypeWhile 条件成立时循环
+	LoopTypeWhile = "while"
+
+	// defaultLoopVariable 默认循环变量名
+	defaultLoopVariable = "item"
+	// loopIndexVariable 循环索引变量名
+	loopIndexVariable = "loop_index"
+	// defaultLoopMaxIterations 未配置最大迭代次数时的上限
+	defaultLoopMaxIterations = 1000
+
+	// subExecutionPollInterval 子执行不在本引擎中运行时查询其状态的间隔
+	subExecutionPollInterval = 2 * time.Second
+)
+
+// isControlNode 判断节点是否由引擎直接执行
+func isControlNode(node *models.Node) bool {
+	if node == nil {
+		return false
+	}
+	switch node.Type {
+	case models.NodeTypeLoop, models.NodeTypeCondition, models.NodeTypeSubDAG, models.NodeTypeDelay, models.NodeTypeTimer:
+		return true
+	default:
+		return false
+	}
+}
+
+// decodePluginConfig 将插件配置解析为控制节点配置结构
+func decodePluginConfig(node *models.Node, target interface{}) error {
+	if node.Config == nil || node.Config.PluginConfig == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(node.Config.PluginConfig)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// findLoopBodyNodes 查找工作流中所有循环节点的循环体节点
+func (e *WorkflowEngine) findLoopBodyNodes(workflow *models.Workflow) map[string]bool {
+	bodyNodes := make(map[string]bool)
+	for nodeID, node := range workflow.Nodes {
+		if node.Type != models.NodeTypeLoop {
+			continue
+		}
+		for bodyID := range e.loopBodyOf(workflow, nodeID) {
+			bodyNodes[bodyID] = true
+		}
+	}
+	return bodyNodes
+}
+
+// loopBodyOf 查找循环节点的循环体：从循环边目标节点出发沿启用的边可达的节点
+func (e *WorkflowEngine) loopBodyOf(workflow *models.Workflow, loopNodeID string) map[string]bool {
+	body := make(map[string]bool)
+	var queue []string
+	for _, edge := range workflow.Edges {
+		if edge.FromNodeID == loopNodeID && edge.Type == models.EdgeTypeLoop && edge.IsEnabled() {
+			queue = append(queue, edge.ToNodeID)
+		}
+	}
+
+	for len(queue) > 0 {
+		nodeID := queue[0]
+		queue = queue[1:]
+		if nodeID == loopNodeID || body[nodeID] {
+			continue
+		}
+		if _, exists := workflow.Nodes[nodeID]; !exists {
+			continue
+		}
+		body[nodeID] = true
+
+		for _, edge := range workflow.Edges {
+			if edge.FromNodeID == nodeID && edge.IsEnabled() {
+				queue = append(queue, edge.ToNodeID)
+			}
+		}
+	}
+
+	return body
+}
+
+// buildLoopBody 构建循环体子图，循环节点以占位控制节点的形式作为子图入口，返回子图和出口节点
+func (e *WorkflowEngine) buildLoopBody(workflow *models.Workflow, loopNodeID string, body map[string]bool) (*models.Workflow, []string) {
+	loopNode := workflow.Nodes[loopNodeID]
+	subWorkflow := &models.Workflow{
+		ID:      workflow.ID,
+		Name:    workflow.Name,
+		Version: workflow.Version,
+		Config:  workflow.Config,
+		Nodes: map[string]*models.Node{
+			loopNodeID: {
+				ID:     loopNodeID,
+				Name:   loopNode.Name,
+				Type:   models.NodeTypeControl,
+				Plugin: loopNode.Plugin,
+			},
+		},
+	}
+
+	hasOutgoing := make(map[string]bool)
+	for nodeID := range body {
+		subWorkflow.Nodes[nodeID] = workflow.Nodes[nodeID]
+	}
+	for _, edge := range workflow.Edges {
+		if !edge.IsEnabled() {
+			continue
+		}
+		isLoopEdge := edge.FromNodeID == loopNodeID && edge.Type == models.EdgeTypeLoop && body[edge.ToNodeID]
+		if isLoopEdge || (body[edge.FromNodeID] && body[edge.ToNodeID]) {
+			subWorkflow.Edges = append(subWorkflow.Edges, edge)
+		}
+		if body[edge.FromNodeID] && body[edge.ToNodeID] {
+			hasOutgoing[edge.FromNodeID] = true
+		}
+	}
+
+	var sinks []string
+	for nodeID := range body {
+		if !hasOutgoing[nodeID] {
+			sinks = append(sinks, nodeID)
+		}
+	}
+
+	return subWorkflow, sinks
+}
+
+// getLoopConfig 获取循环配置，未设置 LoopConfig 时从插件配置解析
+func (e *WorkflowEngine) getLoopConfig(node *models.Node) (*models.LoopConfig, error) {
+	if node.Config != nil && node.Config.LoopConfig != nil {
+		return node.Config.LoopConfig, nil
+	}
+
+	loopConfig := &models.LoopConfig{}
+	if err := decodePluginConfig(node, loopConfig); err != nil {
+		return nil, fmt.Errorf("invalid loop config: %w", err)
+	}
+	return loopConfig, nil
+}
+
+// executeLoopNode 执行循环节点：每次迭代在独立的子执行上下文中运行循环体，汇总迭代结果
+func (e *WorkflowEngine) executeLoopNode(ctx context.Context, execCtx *ExecutionContext, nodeID string, node *models.Node, inputData map[string]interface{}) (*nodes.NodeOutput, error) {
+	loopConfig, err := e.getLoopConfig(node)
+	if err != nil {
+		return nil, err
+	}
+
+	maxIterations := loopConfig.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = defaultLoopMaxIterations
+	}
+
+	body := e.loopBodyOf(execCtx.Workflow, nodeID)
+	subWorkflow, sinks := e.buildLoopBody(execCtx.Workflow, nodeID, body)
+
+	execCtx.mu.RLock()
+	baseVariables := e.buildVariableContext(execCtx)
+	execCtx.mu.RUnlock()
+
+	var results []interface{}
+	switch loopConfig.Type {
+	case LoopTypeWhile:
+		results, err = e.runWhileLoop(ctx, execCtx, nodeID, subWorkflow, sinks, loopConfig, baseVariables, maxIterations)
+	case "", LoopTypeForEach, LoopTypeFor:
+		var items []interface{}
+		items, err = e.resolveLoopItems(loopConfig, inputData)
+		if err != nil {
+			break
+		}
+		if len(items) > maxIterations {
+			err = fmt.Errorf("loop has %d iterations, exceeds max iterations %d", len(items), maxIterations)
+			break
+		}
+		results, err = e.runItemLoop(ctx, execCtx, nodeID, subWorkflow, sinks, loopConfig, baseVariables, items)
+	default:
+		err = fmt.Errorf("invalid loop type: %s", loopConfig.Type)
+	}
+
+	// 主图中循环体节点的状态随循环整体结束
+	bodyState := models.NodeStatusCompleted
+	switch {
+	case err != nil:
+		bodyState = models.NodeStatusCancelled
+	case len(results) == 0:
+		bodyState = models.NodeStatusSkipped
+	}
+	execCtx.mu.Lock()
+	for bodyID := range body {
+		execCtx.NodeStates[bodyID] = bodyState
+	}
+	execCtx.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if results == nil {
+		results = []interface{}{}
+	}
+	return &nodes.NodeOutput{
+		Data: map[string]interface{}{
+			nodes.PortLoopResults: results,
+		},
+		Logs:    []string{fmt.Sprintf("循环完成，共执行 %d 次迭代", len(results))},
+		Metrics: map[string]interface{}{"iterations": len(results)},
+		Success: true,
+	}, nil
+}
+
+// resolveLoopItems 解析 foreach 的遍历数组或 for 的循环次数
+func (e *WorkflowEngine) resolveLoopItems(loopConfig *models.LoopConfig, inputData map[string]interface{}) ([]interface{}, error) {
+	data := loopConfig.Data
+	if loopConfig.Type != LoopTypeFor {
+		if value, exists := inputData[nodes.PortLoopItems]; exists && value != nil {
+			data = value
+		}
+	}
+
+	// 配置中以JSON字符串填写的数据
+	if text, ok := data.(string); ok {
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(text), &decoded); err == nil {
+			data = decoded
+		}
+	}
+
+	if loopConfig.Type == LoopTypeFor {
+		count, err := loopCount(data)
+		if err != nil {
+			return nil, err
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			items[i] = i
+		}
+		return items, nil
+	}
+
+	if data == nil {
+		return nil, nil
+	}
+	value := reflect.ValueOf(data)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, fmt.Errorf("foreach loop data must be an array, got %T", data)
+	}
+	items := make([]interface{}, value.Len())
+	for i := range items {
+		items[i] = value.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// loopCount 解析 for 循环次数
+func loopCount(data interface{}) (int, error) {
+	var count int
+	switch v := data.(type) {
+	case int:
+		count = v
+	case int64:
+		count = int(v)
+	case float64:
+		count = int(v)
+	case string:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid for loop count: %s", v)
+		}
+		count = n
+	default:
+		return 0, fmt.Errorf("for loop data must be a number, got %T", data)
+	}
+	if count < 0 {
+		return 0, fmt.Errorf("for loop count must not be negative: %d", count)
+	}
+	return count, nil
+}
+
+// runItemLoop 执行 foreach/for 循环，并行时最多同时运行 Concurrency 个迭代，任一迭代失败时取消其余迭代
+func (e *WorkflowEngine) runItemLoop(ctx context.Context, execCtx *ExecutionContext, nodeID string, subWorkflow *models.Workflow, sinks []string, loopConfig *models.LoopConfig, baseVariables map[string]interface{}, items []interface{}) ([]interface{}, error) {
+	concurrency := 1
+	if loopConfig.Parallel && loopConfig.Concurrency > 1 {
+		concurrency = loopConfig.Concurrency
+	}
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]interface{}, len(items))
+	slots := make(chan struct{}, concurrency)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+
+dispatch:
+	for index, item := range items {
+		select {
+		case slots <- struct{}{}:
+		case <-loopCtx.Done():
+			break dispatch
+		}
+
+		wg.Add(1)
+		go func(index int, item interface{}) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			result, _, err := e.runLoopIteration(loopCtx, execCtx, nodeID, subWorkflow, sinks, loopConfig, baseVariables, index, item)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("loop iteration %d failed: %w", index, err)
+					cancel()
+				}
+				return
+			}
+			results[index] = result
+		}(index, item)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// runWhileLoop 执行 while 循环，条件可引用 loop_index 和上一次迭代的节点输出
+func (e *WorkflowEngine) runWhileLoop(ctx context.Context, execCtx *ExecutionContext, nodeID string, subWorkflow *models.Workflow, sinks []string, loopConfig *models.LoopConfig, baseVariables map[string]interface{}, maxIterations int) ([]interface{}, error) {
+	if loopConfig.Condition == "" {
+		return nil, fmt.Errorf("while loop requires condition")
+	}
+
+	var results []interface{}
+	conditionContext := copyVariables(baseVariables)
+	for index := 0; ; index++ {
+		conditionContext[loopIndexVariable] = index
+		matched, err := expression.EvaluateBool(ctx, loopConfig.Condition, conditionContext)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate loop condition: %w", err)
+		}
+		if !matched {
+			return results, nil
+		}
+		if index >= maxIterations {
+			return nil, fmt.Errorf("loop exceeds max iterations %d", maxIterations)
+		}
+
+		result, child, err := e.runLoopIteration(ctx, execCtx, nodeID, subWorkflow, sinks, loopConfig, baseVariables, index, index)
+		if err != nil {
+			return nil, fmt.Errorf("loop iteration %d failed: %w", index, err)
+		}
+		results = append(results, result)
+
+		child.mu.RLock()
+		conditionContext = e.buildExpressionEnv(child)
+		child.mu.RUnlock()
+	}
+}
+
+// runLoopIteration 在子执行上下文中执行一次循环体，返回出口节点输出：单个出口时为其输出，多个出口时按节点ID索引
+func (e *WorkflowEngine) runLoopIteration(ctx context.Context, execCtx *ExecutionContext, nodeID string, subWorkflow *models.Workflow, sinks []string, loopConfig *models.LoopConfig, baseVariables map[string]interface{}, index int, item interface{}) (interface{}, *ExecutionContext, error) {
+	variable := loopConfig.Variable
+	if variable == "" {
+		variable = defaultLoopVariable
+	}
+
+	variables := copyVariables(baseVariables)
+	variables[variable] = item
+	variables[loopIndexVariable] = index
+
+	// 以检查点的形式预置循环节点已完成、循环边已激活，由调度逻辑派发循环体入口节点
+	checkpoint := &models.ExecutionCheckpoint{
+		NodeStates: map[string]models.NodeStatusEnum{nodeID: models.NodeStatusCompleted},
+		EdgeStates: make(map[string]string),
+		NodeOutputs: map[string]map[string]interface{}{
+			nodeID: {nodes.PortLoopItem: item, loopIndexVariable: index},
+		},
+		Variables: variables,
+	}
+	for _, edge := range subWorkflow.Edges {
+		if edge.FromNodeID == nodeID {
+			checkpoint.EdgeStates[edge.ID] = string(EdgeStateTaken)
+		}
+	}
+
+	child := newExecutionContext(ctx, subWorkflow, execCtx.Execution)
+	child.WorkflowID = execCtx.WorkflowID
+	child.parent = execCtx
+	child.simulator = execCtx.simulator
+	child.recordSuffix = fmt.Sprintf("%s[%d]", execCtx.recordSuffix, index)
+	defer child.cancel()
+
+	log.Printf("Running loop %s iteration %d for execution %s", nodeID, index, execCtx.ExecutionID)
+	if err := e.executeWorkflowInternal(child, checkpoint); err != nil {
+		return nil, child, err
+	}
+
+	child.mu.RLock()
+	defer child.mu.RUnlock()
+
+	if len(sinks) == 1 {
+		return child.NodeOutputs[sinks[0]], child, nil
+	}
+	result := make(map[string]interface{}, len(sinks))
+	for _, sinkID := range sinks {
+		result[sinkID] = child.NodeOutputs[sinkID]
+	}
+	return result, child, nil
+}
+
+// copyVariables 复制变量表
+func copyVariables(variables map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(variables))
+	for k, v := range variables {
+		copied[k] = v
+	}
+	return copied
+}
+
+// getConditionConfig 获取条件配置，未设置 ConditionConfig 时从插件配置解析，并补齐默认分支名
+func (e *WorkflowEngine) getConditionConfig(node *models.Node) (*models.ConditionConfig, error) {
+	conditionConfig := &models.ConditionConfig{}
+	if node.Config != nil && node.Config.ConditionConfig != nil {
+		*conditionConfig = *node.Config.ConditionConfig
+	} else if err := decodePluginConfig(node, conditionConfig); err != nil {
+		return nil, fmt.Errorf("invalid condition config: %w", err)
+	}
+
+	if conditionConfig.TrueBranch == "" {
+		conditionConfig.TrueBranch = nodes.PortConditionTrue
+	}
+	if conditionConfig.FalseBranch == "" {
+		conditionConfig.FalseBranch = nodes.PortConditionFalse
+	}
+	if conditionConfig.DefaultBranch == "" {
+		conditionConfig.DefaultBranch = nodes.PortConditionDefault
+	}
+	return conditionConfig, nil
+}
+
+// executeConditionNode 执行条件节点：按多路分支或条件表达式选择分支，输入数据原样输出到选中分支的端口
+func (e *WorkflowEngine) executeConditionNode(ctx context.Context, execCtx *ExecutionContext, node *models.Node, inputData map[string]interface{}) (*nodes.NodeOutput, error) {
+	conditionConfig, err := e.getConditionConfig(node)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload interface{} = inputData
+	if value, exists := inputData[nodes.PortConditionInput]; exists {
+		payload = value
+	}
+
+	// 表达式可直接引用输入数据字段
+	execCtx.mu.RLock()
+	variables := e.buildExpressionEnv(execCtx)
+	execCtx.mu.RUnlock()
+	variables[nodes.PortConditionInput] = payload
+	if fields, ok := payload.(map[string]interface{}); ok {
+		for key, value := range fields {
+			variables[key] = value
+		}
+	}
+
+	branch, err := selectConditionBranch(ctx, conditionConfig, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nodes.NodeOutput{
+		Data: map[string]interface{}{
+			nodes.PortConditionOutput: payload,
+			nodes.PortConditionBranch: branch,
+			branch:                    payload,
+		},
+		Logs:    []string{fmt.Sprintf("选中分支: %s", branch)},
+		Success: true,
+	}, nil
+}
+
+// selectConditionBranch 选择分支：配置多路分支时取第一个成立的分支，均不成立时取默认分支
+func selectConditionBranch(ctx context.Context, conditionConfig *models.ConditionConfig, variables map[string]interface{}) (string, error) {
+	if len(conditionConfig.Cases) > 0 {
+		for _, branchCase := range conditionConfig.Cases {
+			matched, err := expression.EvaluateBool(ctx, branchCase.Expression, variables)
+			if err != nil {
+				return "", fmt.Errorf("failed to evaluate case %s: %w", branchCase.Branch, err)
+			}
+			if matched {
+				return branchCase.Branch, nil
+			}
+		}
+		return conditionConfig.DefaultBranch, nil
+	}
+
+	if conditionConfig.Expression == "" {
+		return "", fmt.Errorf("condition node requires expression or cases")
+	}
+	matched, err := expression.EvaluateBool(ctx, conditionConfig.Expression, variables)
+	if err != nil {
+		return "", fmt.Errorf("failed to evaluate condition: %w", err)
+	}
+	if matched {
+		return conditionConfig.TrueBranch, nil
+	}
+	return conditionConfig.FalseBranch, nil
+}
+
+// isSelectedBranch 判断条件节点的出边是否属于选中分支：
+// 出边的源端口或目标节点ID为分支名时受分支控制，其余出边总是激活
+func (e *WorkflowEngine) isSelectedBranch(execCtx *ExecutionContext, edge *models.Edge) bool {
+	node := execCtx.Workflow.Nodes[edge.FromNodeID]
+	if node == nil || node.Type != models.NodeTypeCondition {
+		return true
+	}
+
+	conditionConfig, err := e.getConditionConfig(node)
+	if err != nil {
+		return false
+	}
+	branches := map[string]bool{
+		conditionConfig.TrueBranch:    true,
+		conditionConfig.FalseBranch:   true,
+		conditionConfig.DefaultBranch: true,
+	}
+	for _, branchCase := range conditionConfig.Cases {
+		branches[branchCase.Branch] = true
+	}
+
+	var edgeBranch string
+	switch {
+	case branches[edge.FromPort]:
+		edgeBranch = edge.FromPort
+	case branches[edge.ToNodeID]:
+		edgeBranch = edge.ToNodeID
+	default:
+		return true
+	}
+
+	execCtx.mu.RLock()
+	selected, _ := execCtx.NodeOutputs[edge.FromNodeID][nodes.PortConditionBranch].(string)
+	execCtx.mu.RUnlock()
+	return edgeBranch == selected
+}
+
+// executeSubWorkflowNode 执行子流程节点：启动关联的子执行并等待结束，节点被取消时级联取消子执行
+func (e *WorkflowEngine) executeSubWorkflowNode(ctx context.Context, execCtx *ExecutionContext, nodeID string, node *models.Node, inputData map[string]interface{}) (*nodes.NodeOutput, error) {
+	var pluginConfig map[string]interface{}
+	if node.Config != nil {
+		pluginConfig = node.Config.PluginConfig
+	}
+
+	workflowID, _ := pluginConfig["workflow_id"].(string)
+	if workflowID == "" {
+		return nil, fmt.Errorf("sub workflow node requires workflow_id")
+	}
+	if workflowID == execCtx.WorkflowID {
+		return nil, fmt.Errorf("sub workflow node cannot invoke its own workflow: %s", workflowID)
+	}
+	version, _ := pluginConfig["version"].(string)
+
+	// 固定输入参数在前，节点输入数据覆盖同名参数
+	input := make(map[string]interface{})
+	if static, ok := pluginConfig["input"].(map[string]interface{}); ok {
+		for key, value := range static {
+			input[key] = value
+		}
+	}
+	if mapped, ok := inputData[nodes.PortSubWorkflowInput].(map[string]interface{}); ok {
+		for key, value := range mapped {
+			input[key] = value
+		}
+	} else {
+		for key, value := range inputData {
+			input[key] = value
+		}
+	}
+
+	callback := e.callbackFor(execCtx)
+	if callback == nil {
+		return nil, fmt.Errorf("execution callback is not configured")
+	}
+
+	childID, err := callback.StartSubExecution(&SubExecutionRequest{
+		ParentExecutionID: execCtx.ExecutionID,
+		ParentNodeID:      nodeID + execCtx.recordSuffix,
+		WorkflowID:        workflowID,
+		Version:           version,
+		Input:             input,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start sub workflow %s: %w", workflowID, err)
+	}
+	log.Printf("Node %s started sub execution %s of workflow %s", nodeID, childID, workflowID)
+
+	result, err := e.waitExecution(ctx, callback, childID)
+	if err != nil {
+		if ctx.Err() != nil {
+			if cancelErr := callback.CancelExecution(childID); cancelErr != nil {
+				log.Printf("Failed to cancel sub execution %s: %v", childID, cancelErr)
+			}
+		}
+		return nil, err
+	}
+
+	if result.Status != models.ExecutionStatusCompleted {
+		return nil, fmt.Errorf("sub execution %s %s: %s", childID, result.Status, result.ErrorMsg)
+	}
+
+	return &nodes.NodeOutput{
+		Data: map[string]interface{}{
+			nodes.PortSubWorkflowOutput:      result.Output,
+			nodes.PortSubWorkflowExecutionID: childID,
+		},
+		Logs:    []string{fmt.Sprintf("子执行 %s 已完成", childID)},
+		Success: true,
+	}, nil
+}
+
+// waitExecution 等待执行结束：执行在本引擎中运行时等待结束通知，否则按间隔查询持久化状态
+func (e *WorkflowEngine) waitExecution(ctx context.Context, callback ExecutionCallback, executionID string) (*ExecutionResult, error) {
+	ticker := time.NewTicker(subExecutionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		e.mu.Lock()
+		if _, active := e.executions[executionID]; active {
+			waiter := make(chan *ExecutionResult, 1)
+			e.waiters[executionID] = append(e.waiters[executionID], waiter)
+			e.mu.Unlock()
+
+			select {
+			case result := <-waiter:
+				if result != nil {
+					return result, nil
+				}
+				// 执行未产生结果时回退到查询持久化状态
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		} else {
+			e.mu.Unlock()
+		}
+
+		// 执行不在本引擎中运行（如服务重启后尚未恢复），查询持久化状态
+		execution, err := callback.GetExecution(executionID)
+		if err != nil {
+			return nil, err
+		}
+		if execution.Status.IsFinished() {
+			result := &ExecutionResult{
+				Status:    execution.Status,
+				ErrorMsg:  execution.ErrorMsg,
+				ErrorCode: execution.ErrorCode,
+				Metrics:   execution.Metrics,
+			}
+			if execution.Context != nil {
+				result.Output = execution.Context.Output
+			}
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// deferUntilWake 节点未到唤醒时间时挂起并设置定时器，返回true表示节点已挂起。
+// 唤醒时间首次计算后写入检查点：入边延迟取已激活入边中的最大延迟，延迟/定时器节点在此基础上继续等待
+func (e *WorkflowEngine) deferUntilWake(execCtx *ExecutionContext, nodeID string, node *models.Node) bool {
+	execCtx.mu.Lock()
+	wakeAt, exists := execCtx.wakeTimes[nodeID]
+	if !exists {
+		var err error
+		wakeAt, err = e.computeWakeTime(execCtx, nodeID, node)
+		if err != nil {
+			// 配置错误时不等待，由节点执行报告错误
+			execCtx.mu.Unlock()
+			return false
+		}
+		execCtx.wakeTimes[nodeID] = wakeAt
+	}
+
+	// 模拟运行只计算唤醒时间，不实际等待
+	wait := time.Until(wakeAt)
+	if wait <= 0 || execCtx.simulator != nil {
+		execCtx.mu.Unlock()
+		return false
+	}
+
+	// 定时器持有一份待处理工作，唤醒前执行不会结束
+	execCtx.pendingWork++
+	execCtx.timers[nodeID] = time.AfterFunc(wait, func() {
+		e.wakeNode(execCtx, nodeID)
+	})
+	execCtx.mu.Unlock()
+
+	log.Printf("Node %s waiting until %s", nodeID, wakeAt.Format(time.RFC3339))
+	if !exists {
+		e.markCheckpointDirty(execCtx)
+	}
+	return true
+}
+
+// computeWakeTime 计算节点的唤醒时间（调用方需持有锁）
+func (e *WorkflowEngine) computeWakeTime(execCtx *ExecutionContext, nodeID string, node *models.Node) (time.Time, error) {
+	var edgeDelay time.Duration
+	for _, edge := range execCtx.Workflow.Edges {
+		if edge.ToNodeID != nodeID || !edge.IsEnabled() || execCtx.EdgeStates[edge.ID] != EdgeStateTaken {
+			continue
+		}
+		if delay := edge.GetDelay(); delay > edgeDelay {
+			edgeDelay = delay
+		}
+	}
+	wakeAt := time.Now().Add(edgeDelay)
+
+	if node == nil || (node.Type != models.NodeTypeDelay && node.Type != models.NodeTypeTimer) {
+		return wakeAt, nil
+	}
+
+	var pluginConfig map[string]interface{}
+	if node.Config != nil {
+		pluginConfig = node.Config.PluginConfig
+	}
+
+	if node.Type == models.NodeTypeTimer {
+		at, _ := pluginConfig["at"].(string)
+		timezone, _ := pluginConfig["timezone"].(string)
+		return control.NextTimeOfDay(at, timezone, wakeAt)
+	}
+
+	if value, exists := pluginConfig["duration"]; exists && value != nil && value != "" {
+		duration, err := control.ParseDelayDuration(value)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return wakeAt.Add(duration), nil
+	}
+
+	value := pluginConfig["until"]
+	if field, _ := pluginConfig["until_field"].(string); field != "" {
+		value = e.buildVariableContext(execCtx)[field]
+	}
+	if value == nil || value == "" {
+		return time.Time{}, fmt.Errorf("delay node requires duration, until or until_field")
+	}
+	until, err := control.ParseWaitTime(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if until.After(wakeAt) {
+		wakeAt = until
+	}
+	return wakeAt, nil
+}
+
+// wakeNode 唤醒等待中的节点，重新加入准备队列
+func (e *WorkflowEngine) wakeNode(execCtx *ExecutionContext, nodeID string) {
+	execCtx.mu.Lock()
+	delete(execCtx.timers, nodeID)
+	execCtx.mu.Unlock()
+	defer e.finishWork(execCtx)
+
+	select {
+	case <-execCtx.stopCh:
+		return
+	default:
+	}
+	if execCtx.ctx.Err() != nil {
+		return
+	}
+
+	log.Printf("Node %s woke up", nodeID)
+	e.enqueueNode(execCtx, nodeID)
+}
+
+// stopWakeTimers 停止所有唤醒定时器，执行结束或取消时中断等待
+func (e *WorkflowEngine) stopWakeTimers(execCtx *ExecutionContext) {
+	execCtx.mu.Lock()
+	defer execCtx.mu.Unlock()
+
+	for nodeID, timer := range execCtx.timers {
+		timer.Stop()
+		delete(execCtx.timers, nodeID)
+	}
+}
+
+// executeWaitNode 执行延迟/定时器节点：唤醒时间已到，原样输出输入数据
+func (e *WorkflowEngine) executeWaitNode(execCtx *ExecutionContext, nodeID string, inputData map[string]interface{}) (*nodes.NodeOutput, error) {
+	execCtx.mu.RLock()
+	wakeAt, exists := execCtx.wakeTimes[nodeID]
+	execCtx.mu.RUnlock()
+
+	if !exists {
+		// 唤醒时间未能计算，重新计算以返回配置错误
+		execCtx.mu.Lock()
+		_, err := e.computeWakeTime(execCtx, nodeID, execCtx.Workflow.Nodes[nodeID])
+		execCtx.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var payload interface{} = inputData
+	if value, ok := inputData[nodes.PortWaitInput]; ok {
+		payload = value
+	}
+
+	return &nodes.NodeOutput{
+		Data: map[string]interface{}{
+			nodes.PortWaitOutput: payload,
+		},
+		Logs:    []string{fmt.Sprintf("等待至 %s", wakeAt.Format(time.RFC3339))},
+		Success: true,
+	}, nil
+}
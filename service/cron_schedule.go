@@ -0,0 +1,250 @@
+/**
+ * @module cron_schedule
+ * @description 标准五段 cron 表达式解析，计算调度时区下的下次和上次触发时间
+ * @architecture 调度器计算下次触发时间和定时执行的数据区间时调用，按分钟粒度匹配
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow 无
+ * @rules 字段依次为 分 时 日 月 周，支持 *、列表、范围、步长、月份和星期英文缩写，周日可写作 0 或 7；
+ *        支持 @yearly、@monthly、@weekly、@daily、@hourly 等描述符；
+ *        日和周同时受限时满足其一即触发；夏令时跳过的本地时间不触发
+ * @dependencies time
+ * @refs service/simple_scheduler.go, service/workflow_service.go
+ */
+
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchDays 查找触发时间的最大天数，超过仍未匹配（如 2 月 30 日）视为不会触发
+const cronSearchDays = 366 * 5
+
+// cronDescriptors cron 描述符对应的五段表达式
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField cron 字段的取值范围和名称
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	cronMinuteField = cronField{name: "minute", min: 0, max: 59}
+	cronHourField   = cronField{name: "hour", min: 0, max: 23}
+	cronDayField    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	cronWeekdayField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// cronSchedule 解析后的 cron 表达式，各字段以位集表示允许的取值
+type cronSchedule struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+	anyDay   bool // 日字段为 *
+	anyWeek  bool // 周字段为 *
+}
+
+// parseCronExpression 解析五段 cron 表达式或描述符
+func parseCronExpression(expression string) (*cronSchedule, error) {
+	expression = strings.TrimSpace(expression)
+	if descriptor, exists := cronDescriptors[strings.ToLower(expression)]; exists {
+		expression = descriptor
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields (minute hour day month weekday), got %d", expression, len(fields))
+	}
+
+	schedule := &cronSchedule{
+		anyDay:  fields[2] == "*",
+		anyWeek: fields[4] == "*",
+	}
+	var err error
+	if schedule.minutes, err = parseCronField(fields[0], cronMinuteField); err != nil {
+		return nil, err
+	}
+	if schedule.hours, err = parseCronField(fields[1], cronHourField); err != nil {
+		return nil, err
+	}
+	if schedule.days, err = parseCronField(fields[2], cronDayField); err != nil {
+		return nil, err
+	}
+	if schedule.months, err = parseCronField(fields[3], cronMonthField); err != nil {
+		return nil, err
+	}
+	if schedule.weekdays, err = parseCronField(fields[4], cronWeekdayField); err != nil {
+		return nil, err
+	}
+	// 周日写作 7 时与 0 等价
+	if schedule.weekdays&(1<<7) != 0 {
+		schedule.weekdays |= 1
+	}
+
+	return schedule, nil
+}
+
+// parseCronField 解析单个字段，返回允许取值的位集
+func parseCronField(value string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q in cron %s field", stepPart, field.name)
+			}
+			step = n
+		}
+
+		var start, end int
+		switch {
+		case rangePart == "*":
+			start, end = field.min, field.max
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseCronValue(from, field); err != nil {
+				return 0, err
+			}
+			if end, err = parseCronValue(to, field); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q in cron %s field", rangePart, field.name)
+			}
+		default:
+			var err error
+			if start, err = parseCronValue(rangePart, field); err != nil {
+				return 0, err
+			}
+			// 单值带步长时表示从该值到最大值
+			end = start
+			if hasStep {
+				end = field.max
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// parseCronValue 解析字段中的单个数值或名称
+func parseCronValue(value string, field cronField) (int, error) {
+	if n, exists := field.names[strings.ToLower(value)]; exists {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < field.min || n > field.max {
+		return 0, fmt.Errorf("invalid value %q in cron %s field, expected %d-%d", value, field.name, field.min, field.max)
+	}
+	return n, nil
+}
+
+// matchesDay 判断日期是否满足日、月、周字段
+func (c *cronSchedule) matchesDay(day time.Time) bool {
+	if c.months&(1<<uint(day.Month())) == 0 {
+		return false
+	}
+
+	dayMatch := c.days&(1<<uint(day.Day())) != 0
+	weekMatch := c.weekdays&(1<<uint(day.Weekday())) != 0
+	switch {
+	case c.anyDay && c.anyWeek:
+		return true
+	case c.anyDay:
+		return weekMatch
+	case c.anyWeek:
+		return dayMatch
+	default:
+		return dayMatch || weekMatch
+	}
+}
+
+// Next 返回 after 之后（不含）的下一次触发时间，按 after 所在时区匹配，不会触发时返回零值
+func (c *cronSchedule) Next(after time.Time) time.Time {
+	location := after.Location()
+	from := after.Truncate(time.Minute).Add(time.Minute)
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, location)
+
+	for i := 0; i < cronSearchDays; i, day = i+1, day.AddDate(0, 0, 1) {
+		if !c.matchesDay(day) {
+			continue
+		}
+		for hour := 0; hour < 24; hour++ {
+			if c.hours&(1<<uint(hour)) == 0 {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if c.minutes&(1<<uint(minute)) == 0 {
+					continue
+				}
+				candidate, ok := cronTime(day, hour, minute)
+				if ok && !candidate.Before(from) {
+					return candidate
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// Prev 返回 before 之前（不含）的上一次触发时间，按 before 所在时区匹配，找不到时返回零值
+func (c *cronSchedule) Prev(before time.Time) time.Time {
+	location := before.Location()
+	day := time.Date(before.Year(), before.Month(), before.Day(), 0, 0, 0, 0, location)
+
+	for i := 0; i < cronSearchDays; i, day = i+1, day.AddDate(0, 0, -1) {
+		if !c.matchesDay(day) {
+			continue
+		}
+		for hour := 23; hour >= 0; hour-- {
+			if c.hours&(1<<uint(hour)) == 0 {
+				continue
+			}
+			for minute := 59; minute >= 0; minute-- {
+				if c.minutes&(1<<uint(minute)) == 0 {
+					continue
+				}
+				candidate, ok := cronTime(day, hour, minute)
+				if ok && candidate.Before(before) {
+					return candidate
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// cronTime 构造当天指定时分的时间，夏令时跳过的本地时间返回 false
+func cronTime(day time.Time, hour int, minute int) (time.Time, bool) {
+	t := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+	return t, t.Hour() == hour && t.Minute() == minute
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronExpressionErrors(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@every 5m",
+	} {
+		if _, err := parseCronExpression(expression); err == nil {
+			t.Errorf("parseCronExpression(%q) expected error", expression)
+		}
+	}
+}
+
+func TestCronScheduleNextAndPrev(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		at         time.Time
+		wantNext   time.Time
+		wantPrev   time.Time
+	}{
+		{
+			name:       "daily in schedule timezone",
+			expression: "0 2 * * *",
+			at:         time.Date(2024, 5, 1, 2, 0, 0, 0, shanghai),
+			wantNext:   time.Date(2024, 5, 2, 2, 0, 0, 0, shanghai),
+			wantPrev:   time.Date(2024, 4, 30, 2, 0, 0, 0, shanghai),
+		},
+		{
+			name:       "descriptor",
+			expression: "@hourly",
+			at:         time.Date(2024, 5, 1, 10, 30, 15, 0, time.UTC),
+			wantNext:   time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC),
+			wantPrev:   time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "steps and lists",
+			expression: "*/15 9-17 * * 1,3,5",
+			at:         time.Date(2024, 5, 3, 17, 45, 0, 0, time.UTC), // 周五
+			wantNext:   time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC),
+			wantPrev:   time.Date(2024, 5, 3, 17, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "names and sunday as 7",
+			expression: "30 8 * jan-mar 7",
+			at:         time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC), // 周日
+			wantNext:   time.Date(2025, 1, 5, 8, 30, 0, 0, time.UTC),
+			wantPrev:   time.Date(2024, 3, 31, 8, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "day of month or weekday",
+			expression: "0 0 1 * mon",
+			at:         time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), // 周三
+			wantNext:   time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC),
+			wantPrev:   time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "skips local time missing on dst start",
+			expression: "30 2 * * *",
+			at:         time.Date(2024, 3, 9, 3, 0, 0, 0, newYork),
+			wantNext:   time.Date(2024, 3, 11, 2, 30, 0, 0, newYork),
+			wantPrev:   time.Date(2024, 3, 9, 2, 30, 0, 0, newYork),
+		},
+		{
+			name:       "never fires",
+			expression: "0 0 30 2 *",
+			at:         time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cron, err := parseCronExpression(tt.expression)
+			if err != nil {
+				t.Fatalf("parseCronExpression() error = %v", err)
+			}
+			if got := cron.Next(tt.at); !got.Equal(tt.wantNext) {
+				t.Errorf("Next(%v) = %v, want %v", tt.at, got, tt.wantNext)
+			}
+			if got := cron.Prev(tt.at); !got.Equal(tt.wantPrev) {
+				t.Errorf("Prev(%v) = %v, want %v", tt.at, got, tt.wantPrev)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+/**
+ * @module execution_dates
+ * @description 执行日期宏，将执行的逻辑日期和数据区间按工作流调度时区转换为 ds、data_interval_start 等执行变量
+ * @architecture 执行引擎初始化执行变量时调用，变量与触发输入、工作流变量一起供条件表达式和节点配置模板引用
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow date_flow: execution(logical_date, data_interval) + schedule.timezone -> date variables
+ * @rules 日期宏为保留变量，覆盖同名的工作流变量和输入参数；时区未配置时使用服务器本地时区；
+ *        未记录逻辑日期的执行（如模拟运行）按计划时间、创建时间或当前时间计算
+ * @dependencies time, time/tzdata
+ * @refs service/workflow_engine.go, service/simple_scheduler.go, service/models/execution.go
+ */
+
+package service
+
+import (
+	"log"
+	"time"
+	_ "time/tzdata" // 内嵌时区数据，容器镜像缺少 zoneinfo 时调度时区仍可解析
+
+	"flow-service/service/models"
+)
+
+// 日期宏格式
+const (
+	dateLayoutNoDash      = "20060102"
+	timestampLayoutNoDash = "20060102T150405"
+)
+
+// scheduleLocation 返回工作流调度配置的时区，未配置或无法解析时使用服务器本地时区
+func scheduleLocation(workflow *models.Workflow) *time.Location {
+	if workflow == nil || workflow.Schedule == nil || workflow.Schedule.Timezone == "" {
+		return time.Local
+	}
+	location, err := time.LoadLocation(workflow.Schedule.Timezone)
+	if err != nil {
+		log.Printf("Invalid timezone %q of workflow %s, using local time: %v", workflow.Schedule.Timezone, workflow.ID, err)
+		return time.Local
+	}
+	return location
+}
+
+// executionDateVariables 构造执行的日期宏变量
+func executionDateVariables(execution *models.Execution, location *time.Location) map[string]interface{} {
+	logicalDate := time.Now()
+	switch {
+	case execution.LogicalDate != nil:
+		logicalDate = *execution.LogicalDate
+	case execution.DataIntervalStart != nil:
+		logicalDate = *execution.DataIntervalStart
+	case execution.ScheduledAt != nil:
+		logicalDate = *execution.ScheduledAt
+	case !execution.CreatedAt.IsZero():
+		logicalDate = execution.CreatedAt
+	}
+
+	intervalStart, intervalEnd := logicalDate, logicalDate
+	if execution.DataIntervalStart != nil {
+		intervalStart = *execution.DataIntervalStart
+	}
+	if execution.DataIntervalEnd != nil {
+		intervalEnd = *execution.DataIntervalEnd
+	}
+
+	logicalDate = logicalDate.In(location)
+	intervalStart = intervalStart.In(location)
+	intervalEnd = intervalEnd.In(location)
+
+	return map[string]interface{}{
+		"logical_date":           logicalDate.Format(time.RFC3339),
+		"ds":                     logicalDate.Format(time.DateOnly),
+		"ds_nodash":              logicalDate.Format(dateLayoutNoDash),
+		"ts":                     logicalDate.Format(time.RFC3339),
+		"ts_nodash":              logicalDate.Format(timestampLayoutNoDash),
+		"prev_ds":                logicalDate.AddDate(0, 0, -1).Format(time.DateOnly),
+		"next_ds":                logicalDate.AddDate(0, 0, 1).Format(time.DateOnly),
+		"data_interval_start":    intervalStart.Format(time.RFC3339),
+		"data_interval_end":      intervalEnd.Format(time.RFC3339),
+		"data_interval_start_ds": intervalStart.Format(time.DateOnly),
+		"data_interval_end_ds":   intervalEnd.Format(time.DateOnly),
+		"timezone":               location.String(),
+	}
+}
@@ -0,0 +1,252 @@
+/**
+ * @module execution_queue
+ * @description 执行准入队列，引擎并发数已满时排队等待的执行按优先级和公平策略依次准入
+ * @architecture 服务层内存索引，排队状态以 queued 状态、排队时间和排队顺序持久化在执行记录中，重启后从数据库重建
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow queue_flow: pending -> queued -> running
+ * @rules 执行优先级高者先准入，其次工作流优先级；同一优先级内运行中执行最少的工作流先准入，同一工作流内先进先出；
+ *        显式移到某执行之前只调整排队顺序并标记置顶，同一优先级内置顶项按排队顺序先于公平策略准入，不修改执行优先级
+ * @dependencies service/models/execution.go
+ * @refs service/execution_service.go, service/workflow_engine.go
+ */
+
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"flow-service/service/models"
+)
+
+// QueuedExecution 排队中的执行
+type QueuedExecution struct {
+	ExecutionID      string    `json:"execution_id"`
+	WorkflowID       string    `json:"workflow_id"`
+	Name             string    `json:"name,omitempty"`
+	Priority         int       `json:"priority"`          // 执行优先级，数值越大越优先
+	WorkflowPriority int       `json:"workflow_priority"` // 工作流优先级，执行优先级相同时比较
+	Order            float64   `json:"order"`             // 排队顺序，越小越靠前
+	Pinned           bool      `json:"pinned"`            // 排队顺序是否由调整排队接口显式指定
+	QueuedAt         time.Time `json:"queued_at"`
+	Position         int       `json:"position"` // 预计准入顺序，从0开始
+}
+
+// newQueuedExecution 由执行记录创建排队项
+func newQueuedExecution(execution *models.Execution, workflow *models.Workflow) *QueuedExecution {
+	entry := &QueuedExecution{
+		ExecutionID: execution.ID,
+		WorkflowID:  execution.WorkflowID,
+		Name:        execution.Name,
+		Priority:    execution.Priority,
+		Order:       execution.QueueOrder,
+		Pinned:      execution.QueuePinned,
+		QueuedAt:    time.Now(),
+	}
+	if execution.QueuedAt != nil {
+		entry.QueuedAt = *execution.QueuedAt
+	}
+	if workflow != nil {
+		entry.WorkflowPriority = workflow.Priority
+	}
+	return entry
+}
+
+// ExecutionQueue 执行准入队列
+type ExecutionQueue struct {
+	mu        sync.Mutex
+	entries   map[string]*QueuedExecution
+	lastOrder float64
+}
+
+// NewExecutionQueue 创建执行准入队列
+func NewExecutionQueue() *ExecutionQueue {
+	return &ExecutionQueue{
+		entries: make(map[string]*QueuedExecution),
+	}
+}
+
+// NextOrder 分配新排队执行的顺序号，排在所有已排队执行之后
+func (q *ExecutionQueue) NextOrder() float64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.lastOrder++
+	return q.lastOrder
+}
+
+// Push 加入排队项
+func (q *ExecutionQueue) Push(entry *QueuedExecution) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.entries[entry.ExecutionID] = entry
+	if entry.Order > q.lastOrder {
+		q.lastOrder = entry.Order
+	}
+}
+
+// Remove 移除排队项，返回是否存在
+func (q *ExecutionQueue) Remove(executionID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_, exists := q.entries[executionID]
+	delete(q.entries, executionID)
+	return exists
+}
+
+// Len 排队中的执行数
+func (q *ExecutionQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.entries)
+}
+
+// Peek 返回下一个应准入的执行，running 为各工作流占用槽位的执行数
+func (q *ExecutionQueue) Peek(running map[string]int) *QueuedExecution {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	next := q.selectNext(q.entries, running)
+	if next == nil {
+		return nil
+	}
+	copied := *next
+	return &copied
+}
+
+// List 按预计准入顺序列出排队中的执行
+func (q *ExecutionQueue) List(running map[string]int) []*QueuedExecution {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	remaining := make(map[string]*QueuedExecution, len(q.entries))
+	for id, entry := range q.entries {
+		remaining[id] = entry
+	}
+	counts := make(map[string]int, len(running))
+	for workflowID, count := range running {
+		counts[workflowID] = count
+	}
+
+	// 逐个模拟准入，得到与实际准入一致的顺序
+	list := make([]*QueuedExecution, 0, len(remaining))
+	for len(remaining) > 0 {
+		next := q.selectNext(remaining, counts)
+		delete(remaining, next.ExecutionID)
+		counts[next.WorkflowID]++
+
+		copied := *next
+		copied.Position = len(list)
+		list = append(list, &copied)
+	}
+	return list
+}
+
+// PlanMove 计算调整后的排队项，不修改队列，调用方持久化成功后通过 Update 生效：
+// 指定 priority 时修改执行优先级；指定 beforeID 时移到该执行之前并标记置顶，
+// 目标必须与调整后的执行处于同一优先级，跨优先级调整需要先修改执行优先级
+func (q *ExecutionQueue) PlanMove(executionID string, priority *int, beforeID string) (*QueuedExecution, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, exists := q.entries[executionID]
+	if !exists {
+		return nil, fmt.Errorf("execution is not queued: %s", executionID)
+	}
+
+	moved := *entry
+	if priority != nil && *priority != moved.Priority {
+		moved.Priority = *priority
+		moved.Pinned = false
+	}
+
+	if beforeID != "" && beforeID != executionID {
+		target, exists := q.entries[beforeID]
+		if !exists {
+			return nil, fmt.Errorf("execution is not queued: %s", beforeID)
+		}
+		if moved.Priority != target.Priority || moved.WorkflowPriority != target.WorkflowPriority {
+			return nil, fmt.Errorf("execution %s is queued at a different priority than %s, change its priority to reorder across priority levels", executionID, beforeID)
+		}
+		moved.Order = q.orderBefore(target, entry)
+		moved.Pinned = true
+	}
+
+	return &moved, nil
+}
+
+// Update 应用 PlanMove 计算的排队优先级和顺序，返回排队项是否仍在队列中
+func (q *ExecutionQueue) Update(moved *QueuedExecution) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, exists := q.entries[moved.ExecutionID]
+	if !exists {
+		return false
+	}
+	entry.Priority = moved.Priority
+	entry.Order = moved.Order
+	entry.Pinned = moved.Pinned
+	return true
+}
+
+// orderBefore 计算紧邻目标之前的顺序号（调用方需持有锁）
+func (q *ExecutionQueue) orderBefore(target *QueuedExecution, moving *QueuedExecution) float64 {
+	found := false
+	var previous float64
+	for _, entry := range q.entries {
+		if entry == target || entry == moving || entry.Order >= target.Order {
+			continue
+		}
+		if !found || entry.Order > previous {
+			previous = entry.Order
+			found = true
+		}
+	}
+	if !found {
+		return target.Order - 1
+	}
+	return (previous + target.Order) / 2
+}
+
+// selectNext 选择下一个准入的执行：先取最高优先级，再取运行中执行最少的工作流，最后按排队顺序；
+// 同一优先级内排队顺序更靠前的置顶项先于公平策略选出的执行准入（调用方需持有锁）
+func (q *ExecutionQueue) selectNext(entries map[string]*QueuedExecution, running map[string]int) *QueuedExecution {
+	var best *QueuedExecution
+	for _, entry := range entries {
+		if best == nil || queueAhead(entry, best, running) {
+			best = entry
+		}
+	}
+	if best == nil {
+		return nil
+	}
+
+	selected := best
+	for _, entry := range entries {
+		if !entry.Pinned || entry.Priority != best.Priority || entry.WorkflowPriority != best.WorkflowPriority {
+			continue
+		}
+		if entry.Order < selected.Order || (entry.Order == selected.Order && entry.QueuedAt.Before(selected.QueuedAt)) {
+			selected = entry
+		}
+	}
+	return selected
+}
+
+// queueAhead 判断 a 是否应先于 b 准入
+func queueAhead(a, b *QueuedExecution, running map[string]int) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if a.WorkflowPriority != b.WorkflowPriority {
+		return a.WorkflowPriority > b.WorkflowPriority
+	}
+	if a.WorkflowID != b.WorkflowID && running[a.WorkflowID] != running[b.WorkflowID] {
+		return running[a.WorkflowID] < running[b.WorkflowID]
+	}
+	if a.Order != b.Order {
+		return a.Order < b.Order
+	}
+	return a.QueuedAt.Before(b.QueuedAt)
+}
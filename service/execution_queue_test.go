@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"flow-service/service/config"
+	"flow-service/service/models"
+	"flow-service/service/nodes"
+)
+
+// newTestQueue 创建包含给定排队项的队列
+func newTestQueue(entries ...*QueuedExecution) *ExecutionQueue {
+	queue := NewExecutionQueue()
+	for _, entry := range entries {
+		queue.Push(entry)
+	}
+	return queue
+}
+
+// queuedIDs 返回排队项的执行ID
+func queuedIDs(entries []*QueuedExecution) []string {
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ExecutionID)
+	}
+	return ids
+}
+
+func TestExecutionQueueSelectNext(t *testing.T) {
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		entries []*QueuedExecution
+		running map[string]int
+		want    string
+	}{
+		{name: "empty queue", want: ""},
+		{
+			name: "execution priority first",
+			entries: []*QueuedExecution{
+				{ExecutionID: "low", WorkflowID: "w1", Priority: 1, WorkflowPriority: 9, Order: 1},
+				{ExecutionID: "high", WorkflowID: "w2", Priority: 2, Order: 2},
+			},
+			want: "high",
+		},
+		{
+			name: "workflow priority breaks ties",
+			entries: []*QueuedExecution{
+				{ExecutionID: "a", WorkflowID: "w1", WorkflowPriority: 1, Order: 1},
+				{ExecutionID: "b", WorkflowID: "w2", WorkflowPriority: 5, Order: 2},
+			},
+			want: "b",
+		},
+		{
+			name: "fewest running executions first",
+			entries: []*QueuedExecution{
+				{ExecutionID: "busy", WorkflowID: "w1", Order: 1},
+				{ExecutionID: "idle", WorkflowID: "w2", Order: 2},
+			},
+			running: map[string]int{"w1": 3, "w2": 1},
+			want:    "idle",
+		},
+		{
+			name: "fifo within workflow",
+			entries: []*QueuedExecution{
+				{ExecutionID: "second", WorkflowID: "w1", Order: 2},
+				{ExecutionID: "first", WorkflowID: "w1", Order: 1},
+			},
+			running: map[string]int{"w1": 3},
+			want:    "first",
+		},
+		{
+			name: "queued time breaks equal order",
+			entries: []*QueuedExecution{
+				{ExecutionID: "later", WorkflowID: "w1", Order: 1, QueuedAt: base.Add(time.Second)},
+				{ExecutionID: "earlier", WorkflowID: "w1", Order: 1, QueuedAt: base},
+			},
+			want: "earlier",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := newTestQueue(tt.entries...)
+			next := queue.Peek(tt.running)
+			got := ""
+			if next != nil {
+				got = next.ExecutionID
+			}
+			if got != tt.want {
+				t.Errorf("Peek() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutionQueueList(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []*QueuedExecution
+		running map[string]int
+		want    []string
+	}{
+		{name: "empty queue", want: []string{}},
+		{
+			name: "priority then order",
+			entries: []*QueuedExecution{
+				{ExecutionID: "c", WorkflowID: "w1", Order: 3},
+				{ExecutionID: "a", WorkflowID: "w1", Order: 1},
+				{ExecutionID: "urgent", WorkflowID: "w1", Priority: 5, Order: 4},
+				{ExecutionID: "b", WorkflowID: "w1", Order: 2},
+			},
+			want: []string{"urgent", "a", "b", "c"},
+		},
+		{
+			name: "simulated admissions alternate between workflows",
+			entries: []*QueuedExecution{
+				{ExecutionID: "w1-1", WorkflowID: "w1", Order: 1},
+				{ExecutionID: "w1-2", WorkflowID: "w1", Order: 2},
+				{ExecutionID: "w1-3", WorkflowID: "w1", Order: 3},
+				{ExecutionID: "w2-1", WorkflowID: "w2", Order: 4},
+				{ExecutionID: "w2-2", WorkflowID: "w2", Order: 5},
+			},
+			running: map[string]int{"w1": 1},
+			want:    []string{"w2-1", "w1-1", "w2-2", "w1-2", "w1-3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := newTestQueue(tt.entries...)
+			list := queue.List(tt.running)
+			if got := queuedIDs(list); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("List() = %v, want %v", got, tt.want)
+			}
+			for i, entry := range list {
+				if entry.Position != i {
+					t.Errorf("List()[%d].Position = %d, want %d", i, entry.Position, i)
+				}
+			}
+			if tt.running != nil && tt.running["w1"] != 1 {
+				t.Errorf("List() modified running counts: %v", tt.running)
+			}
+		})
+	}
+}
+
+func TestExecutionQueueMove(t *testing.T) {
+	priority := func(p int) *int { return &p }
+
+	tests := []struct {
+		name         string
+		entries      []*QueuedExecution
+		running      map[string]int
+		executionID  string
+		priority     *int
+		beforeID     string
+		want         []string
+		wantPriority int
+		wantErr      bool
+	}{
+		{
+			name: "change priority",
+			entries: []*QueuedExecution{
+				{ExecutionID: "a", WorkflowID: "w1", Order: 1},
+				{ExecutionID: "b", WorkflowID: "w1", Order: 2},
+			},
+			executionID:  "b",
+			priority:     priority(3),
+			want:         []string{"b", "a"},
+			wantPriority: 3,
+		},
+		{
+			name: "move before within workflow",
+			entries: []*QueuedExecution{
+				{ExecutionID: "a", WorkflowID: "w1", Order: 1},
+				{ExecutionID: "b", WorkflowID: "w1", Order: 2},
+				{ExecutionID: "c", WorkflowID: "w1", Order: 3},
+			},
+			executionID: "c",
+			beforeID:    "b",
+			want:        []string{"a", "c", "b"},
+		},
+		{
+			name: "pinned move wins over fairness without changing priority",
+			entries: []*QueuedExecution{
+				{ExecutionID: "other", WorkflowID: "w3", Order: 1},
+				{ExecutionID: "target", WorkflowID: "w2", Order: 2},
+				{ExecutionID: "moved", WorkflowID: "w1", Order: 3},
+			},
+			running:     map[string]int{"w1": 5, "w3": 1},
+			executionID: "moved",
+			beforeID:    "target",
+			want:        []string{"moved", "target", "other"},
+		},
+		{
+			name: "pinned move does not jump a higher priority entry",
+			entries: []*QueuedExecution{
+				{ExecutionID: "urgent", WorkflowID: "w3", Priority: 1, Order: 1},
+				{ExecutionID: "target", WorkflowID: "w2", Order: 2},
+				{ExecutionID: "moved", WorkflowID: "w1", Order: 3},
+			},
+			running:     map[string]int{"w1": 5},
+			executionID: "moved",
+			beforeID:    "target",
+			want:        []string{"urgent", "moved", "target"},
+		},
+		{
+			name: "priority and move together",
+			entries: []*QueuedExecution{
+				{ExecutionID: "target", WorkflowID: "w2", Priority: 2, Order: 1},
+				{ExecutionID: "moved", WorkflowID: "w1", Order: 2},
+			},
+			executionID:  "moved",
+			priority:     priority(2),
+			beforeID:     "target",
+			want:         []string{"moved", "target"},
+			wantPriority: 2,
+		},
+		{
+			name: "move across priority levels is rejected",
+			entries: []*QueuedExecution{
+				{ExecutionID: "target", WorkflowID: "w2", Priority: 2, Order: 1},
+				{ExecutionID: "moved", WorkflowID: "w1", Order: 2},
+			},
+			executionID: "moved",
+			beforeID:    "target",
+			wantErr:     true,
+		},
+		{
+			name: "move across workflow priority is rejected",
+			entries: []*QueuedExecution{
+				{ExecutionID: "target", WorkflowID: "w2", WorkflowPriority: 3, Order: 1},
+				{ExecutionID: "moved", WorkflowID: "w1", Order: 2},
+			},
+			executionID: "moved",
+			beforeID:    "target",
+			wantErr:     true,
+		},
+		{
+			name:        "unknown execution",
+			entries:     []*QueuedExecution{{ExecutionID: "a", WorkflowID: "w1", Order: 1}},
+			executionID: "missing",
+			priority:    priority(1),
+			wantErr:     true,
+		},
+		{
+			name:        "unknown target",
+			entries:     []*QueuedExecution{{ExecutionID: "a", WorkflowID: "w1", Order: 1}},
+			executionID: "a",
+			beforeID:    "missing",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := newTestQueue(tt.entries...)
+			before := queuedIDs(queue.List(tt.running))
+
+			moved, err := queue.PlanMove(tt.executionID, tt.priority, tt.beforeID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("PlanMove() expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PlanMove() error = %v", err)
+			}
+			if moved.Priority != tt.wantPriority {
+				t.Errorf("PlanMove() priority = %d, want %d", moved.Priority, tt.wantPriority)
+			}
+			if got := queuedIDs(queue.List(tt.running)); !reflect.DeepEqual(got, before) {
+				t.Errorf("PlanMove() changed the queue: %v, want %v", got, before)
+			}
+
+			if !queue.Update(moved) {
+				t.Fatal("Update() reported the execution as no longer queued")
+			}
+			if got := queuedIDs(queue.List(tt.running)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("List() after Update() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutionQueueUpdateRemoved(t *testing.T) {
+	queue := newTestQueue(&QueuedExecution{ExecutionID: "a", WorkflowID: "w1", Order: 1})
+	moved, err := queue.PlanMove("a", func(p int) *int { return &p }(2), "")
+	if err != nil {
+		t.Fatalf("PlanMove() error = %v", err)
+	}
+	queue.Remove("a")
+	if queue.Update(moved) {
+		t.Error("Update() applied a move to an execution that left the queue")
+	}
+}
+
+func TestExecutionQueueAdmitsByEngineCapacity(t *testing.T) {
+	releases := map[string]chan struct{}{
+		"w1-a": make(chan struct{}), "w1-b": make(chan struct{}), "w1-c": make(chan struct{}),
+	}
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			<-releases[input.Variables["run"].(string)]
+			return nil, nil
+		},
+	})
+
+	engine, callback := newTestEngine(t, 4, func(cfg *config.EngineConfig) {
+		cfg.Executor.MaxConcurrentDAGs = 2
+	})
+	w1 := testWorkflow(plugin, []string{"run"})
+	w1.ID = "w1"
+	execute := func(id string) error {
+		execution := &models.Execution{
+			ID:         id,
+			WorkflowID: w1.ID,
+			Context:    &models.ExecutionContext{Variables: map[string]interface{}{"run": id}},
+		}
+		return engine.ExecuteWorkflow(context.Background(), w1, execution)
+	}
+	for _, id := range []string{"w1-a", "w1-b"} {
+		if err := execute(id); err != nil {
+			t.Fatalf("ExecuteWorkflow(%s) error = %v", id, err)
+		}
+	}
+
+	// 引擎已满时新执行进入队列，按各工作流占用的槽位数公平选择
+	if err := execute("w1-c"); !errors.Is(err, ErrEngineSaturated) || engine.HasCapacity() {
+		t.Fatalf("ExecuteWorkflow() on a full engine error = %v, capacity = %v, want %v", err, engine.HasCapacity(), ErrEngineSaturated)
+	}
+	queue := newTestQueue(
+		&QueuedExecution{ExecutionID: "w1-c", WorkflowID: "w1", Order: 1},
+		&QueuedExecution{ExecutionID: "w2-a", WorkflowID: "w2", Order: 2},
+	)
+	if next := queue.Peek(engine.GetActiveExecutionCounts()); next.ExecutionID != "w2-a" {
+		t.Errorf("Peek() = %s, want w2-a while w1 holds both slots", next.ExecutionID)
+	}
+
+	// 手动移到前面后优先于公平选择准入
+	moved, err := queue.PlanMove("w1-c", nil, "w2-a")
+	if err != nil {
+		t.Fatalf("PlanMove() error = %v", err)
+	}
+	queue.Update(moved)
+	next := queue.Peek(engine.GetActiveExecutionCounts())
+	if next.ExecutionID != "w1-c" {
+		t.Fatalf("Peek() after move = %s, want w1-c", next.ExecutionID)
+	}
+
+	// 执行释放槽位后回调通知，准入队首执行
+	close(releases["w1-a"])
+	callback.wait(t)
+	if !engine.HasCapacity() {
+		t.Fatal("HasCapacity() = false after an execution was released")
+	}
+	queue.Remove(next.ExecutionID)
+	if err := execute(next.ExecutionID); err != nil {
+		t.Fatalf("ExecuteWorkflow(%s) after release error = %v", next.ExecutionID, err)
+	}
+	if counts := engine.GetActiveExecutionCounts(); counts["w1"] != 2 {
+		t.Errorf("GetActiveExecutionCounts() = %v, want w1 back at 2", counts)
+	}
+
+	close(releases["w1-b"])
+	close(releases["w1-c"])
+	callback.wait(t)
+	callback.wait(t)
+}
@@ -3,7 +3,7 @@
  * @description 轻量化执行服务，合并原 Instance 服务功能，提供简化的执行管理
  * @architecture 轻量化执行管理，专注于执行状态和记录管理
  * @documentReference ai_docs/refactor_plan.md
- * @stateFlow execution_states: pending -> running -> completed/failed -> archived
+ * @stateFlow execution_states: pending -> queued -> running -> completed/failed -> archived
  * @rules 执行记录必须关联工作流，状态变更必须可追溯，支持重试和取消
  * @dependencies service/models/execution.go, service/models/workflow.go
  * @refs service/workflow_service.go, pkg/engine/dag_engine.go
@@ -15,10 +15,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"sync"
 	"time"
 
 	"flow-service/service/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -27,19 +30,35 @@ type ExecutionService struct {
 	db              *gorm.DB
 	workflowService *WorkflowService
 	engine          *WorkflowEngine
+	recordMu        sync.Mutex // 串行化引擎回调对执行记录的读写
+	queue           *ExecutionQueue
+	admitMu         sync.Mutex // 串行化执行准入，保证排队顺序
 }
 
 // NewExecutionService 创建执行服务实例
 func NewExecutionService(db *gorm.DB, workflowService *WorkflowService, engine *WorkflowEngine) *ExecutionService {
-	return &ExecutionService{
+	s := &ExecutionService{
 		db:              db,
 		workflowService: workflowService,
 		engine:          engine,
+		queue:           NewExecutionQueue(),
 	}
+
+	// 注册引擎回调，回写节点记录和执行结果
+	if engine != nil {
+		engine.SetCallback(s)
+	}
+
+	return s
 }
 
 // CreateExecution 创建执行记录
 func (s *ExecutionService) CreateExecution(execution *models.Execution) error {
+	// 排空期间不再接收新执行
+	if s.engine != nil && s.engine.IsDraining() {
+		return ErrEngineDraining
+	}
+
 	// 检查工作流是否存在
 	workflow, err := s.workflowService.GetWorkflow(execution.WorkflowID)
 	if err != nil {
@@ -73,6 +92,18 @@ func (s *ExecutionService) CreateExecution(execution *models.Execution) error {
 		execution.Context = &models.ExecutionContext{}
 	}
 
+	// 补齐逻辑日期和数据区间
+	if err := execution.NormalizeDataInterval(time.Now()); err != nil {
+		return err
+	}
+
+	// 按参数定义校验输入并补齐默认值
+	input, err := workflow.ResolveInput(execution.Context.Input)
+	if err != nil {
+		return err
+	}
+	execution.Context.Input = input
+
 	// 初始化执行指标
 	if execution.Metrics == nil {
 		execution.Metrics = &models.ExecutionMetrics{}
@@ -127,6 +158,18 @@ func (s *ExecutionService) UpdateExecution(execution *models.Execution) error {
 	return nil
 }
 
+// updateExecutionColumns 只更新执行记录的指定列，引擎回调不覆盖状态等由其他接口写入的字段
+func (s *ExecutionService) updateExecutionColumns(execution *models.Execution, columns ...string) error {
+	execution.UpdatedAt = time.Now()
+	columns = append(columns, "updated_at")
+
+	if err := s.db.Model(execution).Select(columns).Updates(execution).Error; err != nil {
+		return fmt.Errorf("failed to update execution: %w", err)
+	}
+
+	return nil
+}
+
 // DeleteExecution 删除执行记录
 func (s *ExecutionService) DeleteExecution(id string) error {
 	// 先检查执行记录是否存在
@@ -136,7 +179,7 @@ func (s *ExecutionService) DeleteExecution(id string) error {
 	}
 
 	// 如果执行正在运行，先取消
-	if execution.IsRunning() {
+	if execution.IsRunning() || execution.IsPaused() || execution.IsQueued() {
 		if err := s.CancelExecution(id); err != nil {
 			return fmt.Errorf("failed to cancel execution before deletion: %w", err)
 		}
@@ -181,6 +224,7 @@ func (s *ExecutionService) ListExecutions(workflowID string, status models.Execu
 }
 
 // StartExecution 开始执行
+// 引擎并发数已满时执行进入准入队列，等待槽位释放后按优先级准入
 func (s *ExecutionService) StartExecution(id string) error {
 	execution, err := s.GetExecution(id)
 	if err != nil {
@@ -188,37 +232,220 @@ func (s *ExecutionService) StartExecution(id string) error {
 	}
 
 	// 检查状态
+	if execution.Status != models.ExecutionStatusPending {
+		return fmt.Errorf("failed to start execution: execution is not in pending status")
+	}
+
+	if s.engine == nil {
+		if err := execution.Start(); err != nil {
+			return fmt.Errorf("failed to start execution: %w", err)
+		}
+		return s.UpdateExecution(execution)
+	}
+
+	workflow, err := s.workflowService.GetWorkflow(execution.WorkflowID)
+	if err != nil {
+		return fmt.Errorf("failed to get workflow: %w", err)
+	}
+
+	s.admitMu.Lock()
+	defer s.admitMu.Unlock()
+
+	// 排空期间已有的执行进入准入队列，重启后准入；子执行由父节点在恢复后重新启动
+	if s.engine.IsDraining() {
+		if execution.ParentExecutionID != "" {
+			return ErrEngineDraining
+		}
+		return s.enqueueExecution(execution, workflow)
+	}
+
+	// 子执行由父执行等待，不排队；已有排队执行时新执行不插队
+	if execution.ParentExecutionID != "" || (s.queue.Len() == 0 && s.engine.HasCapacity()) {
+		// 引擎已满或刚开始排空时顶层执行转入排队
+		err := s.runExecution(execution, workflow)
+		requeue := errors.Is(err, ErrEngineSaturated) || (errors.Is(err, ErrEngineDraining) && execution.ParentExecutionID == "")
+		if !requeue {
+			return err
+		}
+	}
+
+	return s.enqueueExecution(execution, workflow)
+}
+
+// runExecution 将执行标记为运行中并交给引擎执行（调用方需持有准入锁）
+// 引擎已满或正在排空时恢复为待执行状态并返回对应错误，其它启动失败将执行标记为失败
+func (s *ExecutionService) runExecution(execution *models.Execution, workflow *models.Workflow) error {
 	if err := execution.Start(); err != nil {
 		return fmt.Errorf("failed to start execution: %w", err)
 	}
 
-	// 更新到数据库
 	if err := s.UpdateExecution(execution); err != nil {
 		return err
 	}
 
-	// 使用简化引擎执行工作流
-	if s.engine != nil {
+	err := s.engine.ExecuteWorkflow(context.Background(), workflow, execution)
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, ErrEngineSaturated) || errors.Is(err, ErrEngineDraining) {
+		execution.Status = models.ExecutionStatusPending
+		execution.StartedAt = nil
+		if errors.Is(err, ErrEngineDraining) && execution.ParentExecutionID != "" {
+			// 子执行不排队，保持待执行状态，由父节点恢复后重新启动
+			if updateErr := s.UpdateExecution(execution); updateErr != nil {
+				log.Printf("Failed to revert execution %s to pending: %v", execution.ID, updateErr)
+			}
+		}
+		return err
+	}
+
+	if failErr := s.failExecution(execution, fmt.Sprintf("failed to start workflow execution: %v", err), ErrorCodeWorkflowFailed); failErr != nil {
+		log.Printf("Failed to mark execution %s as failed: %v", execution.ID, failErr)
+	}
+	return fmt.Errorf("failed to start workflow execution: %w", err)
+}
+
+// enqueueExecution 将执行加入准入队列（调用方需持有准入锁）
+func (s *ExecutionService) enqueueExecution(execution *models.Execution, workflow *models.Workflow) error {
+	oldStatus := execution.Status
+	if err := execution.Enqueue(s.queue.NextOrder()); err != nil {
+		return fmt.Errorf("failed to queue execution: %w", err)
+	}
+
+	if err := GlobalStateManager.RecordExecutionTransition(execution.ID, oldStatus, models.ExecutionStatusQueued, "engine at max concurrency", "system"); err != nil {
+		fmt.Printf("Failed to record state transition: %v\n", err)
+	}
+
+	if err := s.UpdateExecution(execution); err != nil {
+		return err
+	}
+
+	s.queue.Push(newQueuedExecution(execution, workflow))
+	log.Printf("Execution %s queued, %d executions waiting", execution.ID, s.queue.Len())
+	return nil
+}
+
+// dispatchQueue 引擎有空闲槽位时按队列顺序准入排队中的执行
+func (s *ExecutionService) dispatchQueue() {
+	if s.engine == nil {
+		return
+	}
+
+	s.admitMu.Lock()
+	defer s.admitMu.Unlock()
+
+	// 排空期间不准入，排队中的执行在重启后重建队列
+	if s.engine.IsDraining() {
+		return
+	}
+
+	for s.engine.HasCapacity() {
+		entry := s.queue.Peek(s.engine.GetActiveExecutionCounts())
+		if entry == nil {
+			return
+		}
+		s.queue.Remove(entry.ExecutionID)
+
+		execution, err := s.GetExecution(entry.ExecutionID)
+		if err != nil || !execution.IsQueued() {
+			// 排队期间已被取消或删除
+			continue
+		}
+
 		workflow, err := s.workflowService.GetWorkflow(execution.WorkflowID)
 		if err != nil {
-			return fmt.Errorf("failed to get workflow: %w", err)
+			if failErr := s.failExecution(execution, fmt.Sprintf("failed to get workflow: %v", err), ErrorCodeWorkflowFailed); failErr != nil {
+				log.Printf("Failed to mark execution %s as failed: %v", execution.ID, failErr)
+			}
+			continue
 		}
 
-		if err := s.engine.ExecuteWorkflow(context.Background(), workflow, execution); err != nil {
-			return fmt.Errorf("failed to start workflow execution: %w", err)
+		if err := s.runExecution(execution, workflow); err != nil {
+			if errors.Is(err, ErrEngineSaturated) || errors.Is(err, ErrEngineDraining) {
+				if err := s.enqueueExecution(execution, workflow); err != nil {
+					log.Printf("Failed to requeue execution %s: %v", execution.ID, err)
+				}
+				return
+			}
+			log.Printf("Failed to start queued execution %s: %v", execution.ID, err)
+			continue
 		}
+
+		log.Printf("Admitted queued execution %s after waiting %v", execution.ID, execution.Metrics.QueueTime)
 	}
+}
 
-	return nil
+// ExecutionReleased 执行释放并发槽位（引擎回调），准入排队中的执行
+func (s *ExecutionService) ExecutionReleased(executionID string) {
+	s.dispatchQueue()
+}
+
+// ListQueuedExecutions 按预计准入顺序列出排队中的执行
+func (s *ExecutionService) ListQueuedExecutions() []*QueuedExecution {
+	var running map[string]int
+	if s.engine != nil {
+		running = s.engine.GetActiveExecutionCounts()
+	}
+	return s.queue.List(running)
+}
+
+// ReorderQueuedExecution 调整排队中执行的优先级，和/或移到同一优先级内另一个排队执行之前，返回带预计准入顺序的排队项
+func (s *ExecutionService) ReorderQueuedExecution(id string, priority *int, beforeID string) (*QueuedExecution, error) {
+	if priority == nil && beforeID == "" {
+		return nil, fmt.Errorf("priority or before_execution_id is required")
+	}
+
+	s.admitMu.Lock()
+	defer s.admitMu.Unlock()
+
+	execution, err := s.GetExecution(id)
+	if err != nil {
+		return nil, err
+	}
+	if !execution.IsQueued() {
+		return nil, fmt.Errorf("execution is not queued: %s", id)
+	}
+
+	entry, err := s.queue.PlanMove(id, priority, beforeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 持久化成功后再调整内存队列
+	execution.Priority = entry.Priority
+	execution.QueueOrder = entry.Order
+	execution.QueuePinned = entry.Pinned
+	if err := s.UpdateExecution(execution); err != nil {
+		return nil, err
+	}
+	s.queue.Update(entry)
+
+	for _, queued := range s.ListQueuedExecutions() {
+		if queued.ExecutionID == id {
+			return queued, nil
+		}
+	}
+	return entry, nil
 }
 
 // CompleteExecution 完成执行
 func (s *ExecutionService) CompleteExecution(id string) error {
+	s.recordMu.Lock()
+	defer s.recordMu.Unlock()
+
 	execution, err := s.GetExecution(id)
 	if err != nil {
 		return err
 	}
 
+	return s.completeExecution(execution)
+}
+
+// completeExecution 完成执行（私有方法）
+func (s *ExecutionService) completeExecution(execution *models.Execution) error {
+	id := execution.ID
+
 	// 使用状态管理器验证状态转换
 	if err := GlobalStateManager.ValidateExecutionTransition(execution.Status, models.ExecutionStatusCompleted); err != nil {
 		return fmt.Errorf("invalid state transition: %w", err)
@@ -249,11 +476,21 @@ func (s *ExecutionService) CompleteExecution(id string) error {
 
 // FailExecution 执行失败
 func (s *ExecutionService) FailExecution(id string, errorMsg string, errorCode string) error {
+	s.recordMu.Lock()
+	defer s.recordMu.Unlock()
+
 	execution, err := s.GetExecution(id)
 	if err != nil {
 		return err
 	}
 
+	return s.failExecution(execution, errorMsg, errorCode)
+}
+
+// failExecution 执行失败（私有方法）
+func (s *ExecutionService) failExecution(execution *models.Execution, errorMsg string, errorCode string) error {
+	id := execution.ID
+
 	// 使用状态管理器验证状态转换
 	if err := GlobalStateManager.ValidateExecutionTransition(execution.Status, models.ExecutionStatusFailed); err != nil {
 		return fmt.Errorf("invalid state transition: %w", err)
@@ -282,8 +519,43 @@ func (s *ExecutionService) FailExecution(id string, errorMsg string, errorCode s
 	return s.UpdateExecution(execution)
 }
 
-// CancelExecution 取消执行
+// timeoutExecution 执行超时（私有方法）
+func (s *ExecutionService) timeoutExecution(execution *models.Execution, errorMsg string, errorCode string) error {
+	id := execution.ID
+
+	// 使用状态管理器验证状态转换
+	if err := GlobalStateManager.ValidateExecutionTransition(execution.Status, models.ExecutionStatusTimeout); err != nil {
+		return fmt.Errorf("invalid state transition: %w", err)
+	}
+
+	oldStatus := execution.Status
+	// 检查状态并标记超时
+	if err := execution.MarkTimeout(errorMsg, errorCode); err != nil {
+		return fmt.Errorf("failed to mark execution timeout: %w", err)
+	}
+
+	// 记录状态转换
+	if err := GlobalStateManager.RecordExecutionTransition(id, oldStatus, models.ExecutionStatusTimeout, fmt.Sprintf("execution timeout: %s", errorMsg), "system"); err != nil {
+		fmt.Printf("Failed to record state transition: %v\n", err)
+	}
+
+	// 更新统计信息
+	if execution.StartedAt != nil {
+		execTime := execution.GetDuration()
+		if err := s.workflowService.UpdateWorkflowStatistics(execution.WorkflowID, execTime, false); err != nil {
+			// 记录错误但不影响主流程
+			fmt.Printf("Failed to update workflow statistics: %v\n", err)
+		}
+	}
+
+	return s.UpdateExecution(execution)
+}
+
+// CancelExecution 取消执行，与引擎回调串行写入，避免在途节点的记录覆盖取消状态
 func (s *ExecutionService) CancelExecution(id string) error {
+	s.recordMu.Lock()
+	defer s.recordMu.Unlock()
+
 	execution, err := s.GetExecution(id)
 	if err != nil {
 		return err
@@ -305,6 +577,12 @@ func (s *ExecutionService) CancelExecution(id string) error {
 		fmt.Printf("Failed to record state transition: %v\n", err)
 	}
 
+	// 排队中的执行只需移出队列
+	if oldStatus == models.ExecutionStatusQueued {
+		s.queue.Remove(id)
+		return s.UpdateExecution(execution)
+	}
+
 	// 通知简化引擎停止执行
 	if s.engine != nil {
 		if err := s.engine.CancelExecution(id); err != nil {
@@ -315,6 +593,76 @@ func (s *ExecutionService) CancelExecution(id string) error {
 	return s.UpdateExecution(execution)
 }
 
+// PauseExecution 暂停执行
+func (s *ExecutionService) PauseExecution(id string) error {
+	s.recordMu.Lock()
+	defer s.recordMu.Unlock()
+
+	execution, err := s.GetExecution(id)
+	if err != nil {
+		return err
+	}
+
+	// 使用状态管理器验证状态转换
+	if err := GlobalStateManager.ValidateExecutionTransition(execution.Status, models.ExecutionStatusPaused); err != nil {
+		return fmt.Errorf("invalid state transition: %w", err)
+	}
+
+	oldStatus := execution.Status
+	if err := execution.Pause(); err != nil {
+		return fmt.Errorf("failed to pause execution: %w", err)
+	}
+
+	// 通知引擎停止派发新节点
+	if s.engine != nil {
+		if err := s.engine.PauseExecution(id); err != nil {
+			return fmt.Errorf("failed to pause execution in engine: %w", err)
+		}
+	}
+
+	// 记录状态转换
+	if err := GlobalStateManager.RecordExecutionTransition(id, oldStatus, models.ExecutionStatusPaused, "execution paused", "system"); err != nil {
+		fmt.Printf("Failed to record state transition: %v\n", err)
+	}
+
+	return s.UpdateExecution(execution)
+}
+
+// ResumeExecution 恢复已暂停的执行
+func (s *ExecutionService) ResumeExecution(id string) error {
+	s.recordMu.Lock()
+	defer s.recordMu.Unlock()
+
+	execution, err := s.GetExecution(id)
+	if err != nil {
+		return err
+	}
+
+	// 使用状态管理器验证状态转换
+	if err := GlobalStateManager.ValidateExecutionTransition(execution.Status, models.ExecutionStatusRunning); err != nil {
+		return fmt.Errorf("invalid state transition: %w", err)
+	}
+
+	oldStatus := execution.Status
+	if err := execution.Resume(); err != nil {
+		return fmt.Errorf("failed to resume execution: %w", err)
+	}
+
+	// 通知引擎继续派发节点
+	if s.engine != nil {
+		if err := s.engine.ResumeExecution(id); err != nil {
+			return fmt.Errorf("failed to resume execution in engine: %w", err)
+		}
+	}
+
+	// 记录状态转换
+	if err := GlobalStateManager.RecordExecutionTransition(id, oldStatus, models.ExecutionStatusRunning, "execution resumed", "system"); err != nil {
+		fmt.Printf("Failed to record state transition: %v\n", err)
+	}
+
+	return s.UpdateExecution(execution)
+}
+
 // RetryExecution 重试执行
 func (s *ExecutionService) RetryExecution(id string) error {
 	execution, err := s.GetExecution(id)
@@ -327,6 +675,11 @@ func (s *ExecutionService) RetryExecution(id string) error {
 		return fmt.Errorf("failed to retry execution: %w", err)
 	}
 
+	// 完整重试不复用上次的节点输出、节点记录和执行输出
+	execution.CheckpointData = ""
+	execution.Nodes = []*models.ExecutionNodeRecord{}
+	execution.ClearOutput()
+
 	// 更新到数据库
 	if err := s.UpdateExecution(execution); err != nil {
 		return err
@@ -336,6 +689,129 @@ func (s *ExecutionService) RetryExecution(id string) error {
 	return s.StartExecution(id)
 }
 
+// RetryExecutionFromFailure 从失败节点重试，复用已完成节点的输出，只重新执行失败节点及其下游节点
+func (s *ExecutionService) RetryExecutionFromFailure(id string) error {
+	execution, err := s.GetExecution(id)
+	if err != nil {
+		return err
+	}
+
+	checkpoint, err := s.loadRerunCheckpoint(execution)
+	if err != nil {
+		return err
+	}
+
+	workflow, err := s.workflowService.GetWorkflow(execution.WorkflowID)
+	if err != nil {
+		return fmt.Errorf("failed to get workflow: %w", err)
+	}
+
+	// 失败节点及其下游全部重新执行
+	invalidated := make(map[string]bool)
+	for nodeID, state := range checkpoint.NodeStates {
+		if state != models.NodeStatusFailed {
+			continue
+		}
+		invalidated[nodeID] = true
+		for _, downstream := range workflow.GetDownstreamNodes(nodeID) {
+			invalidated[downstream] = true
+		}
+	}
+
+	if err := execution.Retry(); err != nil {
+		return fmt.Errorf("failed to retry execution: %w", err)
+	}
+
+	return s.restartFromCheckpoint(execution, workflow, checkpoint, invalidated)
+}
+
+// RerunExecutionFromNode 从指定节点重新运行，该节点及其所有下游节点重新执行
+func (s *ExecutionService) RerunExecutionFromNode(id string, nodeID string) error {
+	execution, err := s.GetExecution(id)
+	if err != nil {
+		return err
+	}
+
+	workflow, err := s.workflowService.GetWorkflow(execution.WorkflowID)
+	if err != nil {
+		return fmt.Errorf("failed to get workflow: %w", err)
+	}
+
+	if _, exists := workflow.Nodes[nodeID]; !exists {
+		return fmt.Errorf("node not found in workflow: %s", nodeID)
+	}
+
+	checkpoint, err := s.loadRerunCheckpoint(execution)
+	if err != nil {
+		return err
+	}
+
+	invalidated := map[string]bool{nodeID: true}
+	for _, downstream := range workflow.GetDownstreamNodes(nodeID) {
+		invalidated[downstream] = true
+	}
+
+	if err := execution.Rerun(); err != nil {
+		return fmt.Errorf("failed to rerun execution: %w", err)
+	}
+
+	return s.restartFromCheckpoint(execution, workflow, checkpoint, invalidated)
+}
+
+// loadRerunCheckpoint 加载上次运行保存的检查点
+func (s *ExecutionService) loadRerunCheckpoint(execution *models.Execution) (*models.ExecutionCheckpoint, error) {
+	checkpoint, err := execution.GetCheckpoint()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	if checkpoint == nil {
+		return nil, fmt.Errorf("execution %s has no checkpoint, use a full retry instead", execution.ID)
+	}
+	return checkpoint, nil
+}
+
+// restartFromCheckpoint 使指定节点的检查点失效后重新开始执行
+func (s *ExecutionService) restartFromCheckpoint(execution *models.Execution, workflow *models.Workflow, checkpoint *models.ExecutionCheckpoint, invalidated map[string]bool) error {
+	checkpoint.Invalidate(invalidated, workflow.Edges)
+	execution.RemoveNodeRecords(invalidated)
+	execution.ClearOutput()
+
+	compress := false
+	if s.engine != nil {
+		compress = s.engine.GetConfig().Storage.StateCompression
+	}
+	data, err := checkpoint.Encode(compress)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	execution.CheckpointData = data
+
+	if err := s.UpdateExecution(execution); err != nil {
+		return err
+	}
+
+	return s.StartExecution(execution.ID)
+}
+
+// SimulateWorkflow 模拟运行工作流，不创建执行记录，mocks 按节点ID提供模拟输出
+func (s *ExecutionService) SimulateWorkflow(ctx context.Context, workflow *models.Workflow, execution *models.Execution, mocks map[string]map[string]interface{}) (*SimulationResult, error) {
+	if s.engine == nil {
+		return nil, fmt.Errorf("workflow engine is not available")
+	}
+
+	// 与实际触发一致，按参数定义校验输入并补齐默认值
+	if execution.Context == nil {
+		execution.Context = &models.ExecutionContext{}
+	}
+	input, err := workflow.ResolveInput(execution.Context.Input)
+	if err != nil {
+		return nil, err
+	}
+	execution.Context.Input = input
+
+	return s.engine.SimulateWorkflow(ctx, workflow, execution, mocks)
+}
+
 // GetExecutionProgress 获取执行进度
 func (s *ExecutionService) GetExecutionProgress(id string) (float64, error) {
 	execution, err := s.GetExecution(id)
@@ -346,6 +822,25 @@ func (s *ExecutionService) GetExecutionProgress(id string) (float64, error) {
 	return execution.GetProgress(), nil
 }
 
+// ErrExecutionNotCompleted 执行尚未成功完成，没有输出结果
+var ErrExecutionNotCompleted = errors.New("execution has not completed")
+
+// GetExecutionOutput 获取已完成执行的输出结果
+func (s *ExecutionService) GetExecutionOutput(id string) (map[string]interface{}, error) {
+	execution, err := s.GetExecution(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if execution.Status != models.ExecutionStatusCompleted {
+		return nil, fmt.Errorf("%w: status is %s", ErrExecutionNotCompleted, execution.Status)
+	}
+	if execution.Context == nil || execution.Context.Output == nil {
+		return map[string]interface{}{}, nil
+	}
+	return execution.Context.Output, nil
+}
+
 // GetExecutionLogs 获取执行日志
 func (s *ExecutionService) GetExecutionLogs(id string, nodeID string) ([]string, error) {
 	execution, err := s.GetExecution(id)
@@ -388,6 +883,275 @@ func (s *ExecutionService) UpdateNodeExecution(executionID string, nodeID string
 	return s.UpdateExecution(execution)
 }
 
+// SaveNodeRecord 保存节点执行记录（引擎回调）
+func (s *ExecutionService) SaveNodeRecord(executionID string, record *models.ExecutionNodeRecord) error {
+	s.recordMu.Lock()
+	defer s.recordMu.Unlock()
+
+	execution, err := s.GetExecution(executionID)
+	if err != nil {
+		return err
+	}
+
+	execution.SetNodeRecord(record)
+
+	// 更新执行指标
+	s.updateExecutionMetrics(execution)
+
+	return s.updateExecutionColumns(execution, "nodes", "metrics")
+}
+
+// FinishExecution 保存执行最终状态（引擎回调）
+func (s *ExecutionService) FinishExecution(executionID string, result *ExecutionResult) error {
+	s.recordMu.Lock()
+	defer s.recordMu.Unlock()
+
+	execution, err := s.GetExecution(executionID)
+	if err != nil {
+		return err
+	}
+
+	// 合并引擎统计的节点指标
+	if execution.Metrics == nil {
+		execution.Metrics = &models.ExecutionMetrics{}
+	}
+	if result.Metrics != nil {
+		execution.Metrics.TotalNodes = result.Metrics.TotalNodes
+		execution.Metrics.CompletedNodes = result.Metrics.CompletedNodes
+		execution.Metrics.FailedNodes = result.Metrics.FailedNodes
+		execution.Metrics.SkippedNodes = result.Metrics.SkippedNodes
+		execution.Metrics.ExecutionTime = result.Metrics.ExecutionTime
+	}
+
+	// 保存执行输出，供子流程节点和调用方读取
+	if result.Output != nil {
+		if execution.Context == nil {
+			execution.Context = &models.ExecutionContext{}
+		}
+		execution.Context.Output = result.Output
+	}
+
+	switch result.Status {
+	case models.ExecutionStatusCompleted:
+		return s.completeExecution(execution)
+	case models.ExecutionStatusFailed:
+		return s.failExecution(execution, result.ErrorMsg, result.ErrorCode)
+	case models.ExecutionStatusTimeout:
+		return s.timeoutExecution(execution, result.ErrorMsg, result.ErrorCode)
+	default:
+		// 取消等状态已由对应接口完成状态转换，这里只保存指标和输出
+		return s.updateExecutionColumns(execution, "metrics", "context")
+	}
+}
+
+// SaveCheckpoint 保存执行检查点（引擎回调）
+func (s *ExecutionService) SaveCheckpoint(executionID string, checkpoint string) error {
+	s.recordMu.Lock()
+	defer s.recordMu.Unlock()
+
+	// 只更新检查点列，避免覆盖节点记录等其他字段
+	result := s.db.Model(&models.Execution{}).
+		Where("id = ?", executionID).
+		UpdateColumn("checkpoint", checkpoint)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", result.Error)
+	}
+
+	return nil
+}
+
+// StartSubExecution 创建并启动子流程节点的子执行（引擎回调），父执行恢复后复用该节点未结束的子执行
+func (s *ExecutionService) StartSubExecution(request *SubExecutionRequest) (string, error) {
+	var existing models.Execution
+	err := s.db.Where("parent_execution_id = ? AND parent_node_id = ? AND status IN ?",
+		request.ParentExecutionID, request.ParentNodeID,
+		[]models.ExecutionStatus{models.ExecutionStatusPending, models.ExecutionStatusRunning, models.ExecutionStatusPaused}).
+		First(&existing).Error
+	if err == nil {
+		if existing.Status == models.ExecutionStatusPending {
+			if err := s.StartExecution(existing.ID); err != nil {
+				return "", err
+			}
+		}
+		return existing.ID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("failed to find sub execution: %w", err)
+	}
+
+	workflow, err := s.workflowService.GetWorkflow(request.WorkflowID)
+	if err != nil {
+		return "", fmt.Errorf("sub workflow not found: %w", err)
+	}
+	if request.Version != "" && workflow.Version != request.Version {
+		return "", fmt.Errorf("sub workflow %s version mismatch: required %s, current %s", workflow.ID, request.Version, workflow.Version)
+	}
+
+	parent, err := s.GetExecution(request.ParentExecutionID)
+	if err != nil {
+		return "", err
+	}
+
+	execution := &models.Execution{
+		ID:                uuid.New().String(),
+		WorkflowID:        workflow.ID,
+		WorkflowVer:       workflow.Version,
+		Name:              workflow.Name,
+		Description:       fmt.Sprintf("由执行 %s 的节点 %s 触发", request.ParentExecutionID, request.ParentNodeID),
+		Status:            models.ExecutionStatusPending,
+		TriggerType:       models.TriggerTypeWorkflow,
+		TriggerBy:         request.ParentExecutionID,
+		ParentExecutionID: request.ParentExecutionID,
+		ParentNodeID:      request.ParentNodeID,
+		// 只传递子工作流声明的参数，创建时按参数定义校验并补齐默认值
+		Context: &models.ExecutionContext{
+			Input: workflow.DeclaredInput(request.Input),
+		},
+		Priority: parent.Priority,
+
+		// 子执行沿用父执行的逻辑日期和数据区间
+		LogicalDate:       parent.LogicalDate,
+		DataIntervalStart: parent.DataIntervalStart,
+		DataIntervalEnd:   parent.DataIntervalEnd,
+	}
+
+	if err := s.CreateExecution(execution); err != nil {
+		return "", err
+	}
+	if err := s.StartExecution(execution.ID); err != nil {
+		return "", err
+	}
+
+	return execution.ID, nil
+}
+
+// TriggerScheduledExecution 创建并启动定时执行（调度器触发回调），逻辑日期为数据区间开始，区间结束为本次计划触发时间
+func (s *ExecutionService) TriggerScheduledExecution(workflowID string, scheduledAt, intervalStart, intervalEnd time.Time) (*models.Execution, error) {
+	workflow, err := s.workflowService.GetWorkflow(workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("workflow not found: %w", err)
+	}
+
+	priority := 0
+	if workflow.Config != nil {
+		priority = workflow.Config.Priority
+	}
+
+	execution := &models.Execution{
+		ID:                uuid.New().String(),
+		WorkflowID:        workflow.ID,
+		WorkflowVer:       workflow.Version,
+		Name:              workflow.Name,
+		Status:            models.ExecutionStatusPending,
+		TriggerType:       models.TriggerTypeSchedule,
+		TriggerBy:         "scheduler",
+		Context:           &models.ExecutionContext{},
+		Priority:          priority,
+		ScheduledAt:       &scheduledAt,
+		LogicalDate:       &intervalStart,
+		DataIntervalStart: &intervalStart,
+		DataIntervalEnd:   &intervalEnd,
+	}
+
+	if err := s.CreateExecution(execution); err != nil {
+		return nil, err
+	}
+	if err := s.StartExecution(execution.ID); err != nil {
+		return execution, err
+	}
+
+	return execution, nil
+}
+
+// Drain 停止准入新执行并排空引擎，超时仍未结束的执行已保存检查点，
+// 按恢复策略保持运行状态等待重启后继续，或标记为中断失败
+func (s *ExecutionService) Drain(ctx context.Context) {
+	if s.engine == nil {
+		return
+	}
+
+	remaining := s.engine.Drain(ctx)
+	if len(remaining) == 0 {
+		return
+	}
+
+	if s.engine.GetConfig().Storage.RecoveryPolicy != RecoveryPolicyFail {
+		log.Printf("%d executions will resume from checkpoints after restart", len(remaining))
+		return
+	}
+
+	for _, id := range remaining {
+		execution, err := s.GetExecution(id)
+		if err != nil {
+			log.Printf("Failed to get interrupted execution %s: %v", id, err)
+			continue
+		}
+		if err := s.failExecution(execution, "execution interrupted by service shutdown", ErrorCodeInterrupted); err != nil {
+			log.Printf("Failed to mark interrupted execution %s as failed: %v", id, err)
+		}
+	}
+}
+
+// RecoverExecutions 处理服务重启前仍在运行或已暂停的执行，按恢复策略从检查点继续或标记为失败
+// 已暂停的执行恢复后保持暂停状态
+func (s *ExecutionService) RecoverExecutions() error {
+	if s.engine == nil {
+		return nil
+	}
+
+	var executions []*models.Execution
+	for _, status := range []models.ExecutionStatus{models.ExecutionStatusRunning, models.ExecutionStatusPaused} {
+		found, err := s.GetExecutionsByStatus(status, 0)
+		if err != nil {
+			return err
+		}
+		executions = append(executions, found...)
+	}
+
+	policy := s.engine.GetConfig().Storage.RecoveryPolicy
+	for _, execution := range executions {
+		if policy == RecoveryPolicyFail {
+			if err := s.failExecution(execution, "execution interrupted by service restart", ErrorCodeInterrupted); err != nil {
+				log.Printf("Failed to mark interrupted execution %s as failed: %v", execution.ID, err)
+			}
+			continue
+		}
+
+		workflow, err := s.workflowService.GetWorkflow(execution.WorkflowID)
+		if err == nil {
+			err = s.engine.ResumeWorkflow(context.Background(), workflow, execution)
+		}
+		if err != nil {
+			log.Printf("Failed to resume execution %s: %v", execution.ID, err)
+			if err := s.failExecution(execution, fmt.Sprintf("failed to resume execution: %v", err), ErrorCodeInterrupted); err != nil {
+				log.Printf("Failed to mark interrupted execution %s as failed: %v", execution.ID, err)
+			}
+			continue
+		}
+
+		log.Printf("Resumed execution %s", execution.ID)
+	}
+
+	// 重建准入队列，按剩余槽位准入
+	queued, err := s.GetExecutionsByStatus(models.ExecutionStatusQueued, 0)
+	if err != nil {
+		return err
+	}
+	for _, execution := range queued {
+		workflow, err := s.workflowService.GetWorkflow(execution.WorkflowID)
+		if err != nil {
+			log.Printf("Failed to get workflow for queued execution %s: %v", execution.ID, err)
+		}
+		s.queue.Push(newQueuedExecution(execution, workflow))
+	}
+	if len(queued) > 0 {
+		log.Printf("Restored %d queued executions", len(queued))
+	}
+	s.dispatchQueue()
+
+	return nil
+}
+
 // GetExecutionsByStatus 按状态获取执行记录
 func (s *ExecutionService) GetExecutionsByStatus(status models.ExecutionStatus, limit int) ([]*models.Execution, error) {
 	var executions []*models.Execution
@@ -409,7 +1173,7 @@ func (s *ExecutionService) GetExecutionsByStatus(status models.ExecutionStatus,
 func (s *ExecutionService) CleanupExecutions(beforeTime time.Time, keepCount int) (int64, error) {
 	// 删除指定时间之前的已完成执行记录
 	result := s.db.Where("status IN ? AND created_at < ?",
-		[]models.ExecutionStatus{models.ExecutionStatusCompleted, models.ExecutionStatusFailed, models.ExecutionStatusCancelled},
+		[]models.ExecutionStatus{models.ExecutionStatusCompleted, models.ExecutionStatusFailed, models.ExecutionStatusCancelled, models.ExecutionStatusTimeout},
 		beforeTime).
 		Limit(keepCount).
 		Delete(&models.Execution{})
@@ -434,12 +1198,15 @@ func (s *ExecutionService) updateExecutionMetrics(execution *models.Execution) {
 			completed++
 		case models.ExecutionStatusFailed:
 			failed++
-		case models.ExecutionStatusCancelled:
+		case models.ExecutionStatusSkipped:
 			skipped++
 		}
 	}
 
 	execution.Metrics.TotalNodes = len(execution.Nodes)
+	if execution.Workflow != nil && len(execution.Workflow.Nodes) > 0 {
+		execution.Metrics.TotalNodes = len(execution.Workflow.Nodes)
+	}
 	execution.Metrics.CompletedNodes = completed
 	execution.Metrics.FailedNodes = failed
 	execution.Metrics.SkippedNodes = skipped
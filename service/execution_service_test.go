@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"flow-service/service/models"
+	"flow-service/service/nodes"
+)
+
+func TestExecutionServiceSimulateResolvesInputParameters(t *testing.T) {
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			return map[string]interface{}{
+				"region": input.Config["region"],
+				"limit":  fmt.Sprint(input.Config["limit"]),
+			}, nil
+		},
+	})
+
+	workflow := testWorkflow(plugin, []string{"export"})
+	workflow.Parameters = []*models.WorkflowParameter{
+		{Name: "region", Type: "string", Required: true, Enum: []interface{}{"eu", "us"}},
+		{Name: "limit", Type: "integer", Default: 100},
+	}
+	workflow.Nodes["export"].Config.PluginConfig["region"] = "{{ input.region }}"
+	workflow.Nodes["export"].Config.PluginConfig["limit"] = "{{ input.limit }}"
+
+	engine, _ := newTestEngine(t, 1)
+	executionService := NewExecutionService(nil, nil, engine)
+
+	tests := []struct {
+		name       string
+		input      map[string]interface{}
+		wantOutput map[string]interface{}
+		wantErrors map[string]string // 字段 -> 错误码
+	}{
+		{
+			name:       "default fills missing parameter",
+			input:      map[string]interface{}{"region": "eu"},
+			wantOutput: map[string]interface{}{"region": "eu", "limit": "100"},
+		},
+		{
+			name:       "json number converted to integer",
+			input:      map[string]interface{}{"region": "us", "limit": 25.0}, // JSON 数字解码为 float64
+			wantOutput: map[string]interface{}{"region": "us", "limit": "25"},
+		},
+		{
+			name:  "field level errors",
+			input: map[string]interface{}{"region": "apac", "dry_run": true},
+			wantErrors: map[string]string{
+				"region":  models.ParameterErrorNotInEnum,
+				"dry_run": models.ParameterErrorUnknown,
+			},
+		},
+		{
+			name:       "required parameter missing",
+			wantErrors: map[string]string{"region": models.ParameterErrorRequired},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execution := &models.Execution{
+				ID:         "simulation-" + tt.name,
+				WorkflowID: workflow.ID,
+				Context:    &models.ExecutionContext{Input: tt.input},
+			}
+			result, err := executionService.SimulateWorkflow(context.Background(), workflow, execution, nil)
+
+			if tt.wantErrors != nil {
+				var inputErr *models.InputValidationError
+				if !errors.As(err, &inputErr) {
+					t.Fatalf("SimulateWorkflow() error = %v, want *models.InputValidationError", err)
+				}
+				got := make(map[string]string, len(inputErr.Errors))
+				for _, fieldErr := range inputErr.Errors {
+					got[fieldErr.Field] = fieldErr.Code
+				}
+				if !reflect.DeepEqual(got, tt.wantErrors) {
+					t.Errorf("field errors = %v, want %v", got, tt.wantErrors)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("SimulateWorkflow() error = %v", err)
+			}
+			if result.Status != models.ExecutionStatusCompleted {
+				t.Fatalf("status = %s (%s), want completed", result.Status, result.ErrorMsg)
+			}
+			if !reflect.DeepEqual(result.Output, tt.wantOutput) {
+				t.Errorf("output = %v, want %v", result.Output, tt.wantOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/**
+ * @module expression_dates
+ * @description 表达式日期函数：当前时间、日期格式化和日期加减
+ * @architecture 表达式语言没有日期类型，日期以字符串传递，函数解析常见日期格式并按 Go 时间布局格式化
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow 无状态
+ * @rules 加减结果保持输入日期的格式；无法解析的日期字符串报错；参数为 null 时返回 null
+ * @dependencies time
+ * @refs service/expression/functions.go, service/expression/template.go
+ */
+
+package expression
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout 日期格式，today() 和纯日期参数使用
+const DateLayout = "2006-01-02"
+
+// dateLayouts 可解析的日期格式，按顺序尝试
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	DateLayout,
+}
+
+// init 登记日期函数
+func init() {
+	register("now", 0, 0, fnNow)
+	register("today", 0, 0, fnToday)
+	register("formatDate", 2, 2, fnFormatDate)
+	register("addDays", 2, 2, dateAdder(func(t time.Time, n float64) time.Time { return t.AddDate(0, 0, int(n)) }))
+	register("addHours", 2, 2, dateAdder(func(t time.Time, n float64) time.Time {
+		return t.Add(time.Duration(n * float64(time.Hour)))
+	}))
+}
+
+// fnNow 当前时间，RFC3339 格式
+func fnNow(args []interface{}) (interface{}, error) {
+	return time.Now().Format(time.RFC3339), nil
+}
+
+// fnToday 当天日期，YYYY-MM-DD 格式
+func fnToday(args []interface{}) (interface{}, error) {
+	return time.Now().Format(DateLayout), nil
+}
+
+// fnFormatDate 按 Go 时间布局格式化日期，如 formatDate(ds, '20060102')
+func fnFormatDate(args []interface{}) (interface{}, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	t, _, err := parseDate(args[0])
+	if err != nil {
+		return nil, err
+	}
+	layout, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("expects string layout, got %s", typeName(args[1]))
+	}
+	return t.Format(layout), nil
+}
+
+// dateAdder 构造日期加减函数，结果保持输入日期的格式
+func dateAdder(add func(t time.Time, n float64) time.Time) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		t, layout, err := parseDate(args[0])
+		if err != nil {
+			return nil, err
+		}
+		n, ok := toNumber(args[1])
+		if !ok {
+			return nil, fmt.Errorf("expects number, got %s", typeName(args[1]))
+		}
+		return add(t, n).Format(layout), nil
+	}
+}
+
+// parseDate 解析日期字符串，返回时间和匹配的格式
+func parseDate(value interface{}) (time.Time, string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, "", fmt.Errorf("expects date string, got %s", typeName(value))
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			if layout == time.RFC3339Nano {
+				layout = time.RFC3339
+			}
+			return t, layout, nil
+		}
+	}
+	return time.Time{}, "", fmt.Errorf("cannot parse date %q", s)
+}
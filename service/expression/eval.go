@@ -0,0 +1,460 @@
+/**
+ * @module expression_eval
+ * @description 条件表达式求值，按类型执行比较、逻辑、算术、in 和路径访问
+ * @architecture 语法树解释器，变量环境只读，映射和切片通过反射读取，不访问结构体字段和方法
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow eval_flow: node -> value
+ * @rules 数值统一按 float64 比较；不同类型 == 为 false，大小比较报错，与 null 大小比较为 false；
+ *        && || ! 只接受布尔值和 null（视为 false）；访问不存在的变量或字段报错，避免拼写错误被当作 null；
+ *        访问 null 的字段或越界下标得到 null
+ * @dependencies reflect
+ * @refs service/expression/parser.go, service/expression/functions.go
+ */
+
+package expression
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// evaluator 单次求值状态
+type evaluator struct {
+	ctx     context.Context
+	env     map[string]interface{}
+	steps   int
+	lenient int // 大于 0 时处于空值判断函数的参数中，不存在的变量或字段视为 null
+}
+
+// missingError 变量或字段不存在
+type missingError struct {
+	message string
+}
+
+// Error 实现 error 接口
+func (e *missingError) Error() string {
+	return e.message
+}
+
+// tolerate 空值判断函数的参数中忽略变量或字段不存在的错误
+func (ev *evaluator) tolerate(value interface{}, err error) (interface{}, error) {
+	if _, missing := err.(*missingError); missing && ev.lenient > 0 {
+		return nil, nil
+	}
+	return value, err
+}
+
+// eval 求值语法树节点
+func (ev *evaluator) eval(n node) (interface{}, error) {
+	ev.steps++
+	if ev.steps > maxEvaluationSteps {
+		return nil, fmt.Errorf("expression evaluation exceeds %d steps", maxEvaluationSteps)
+	}
+	if err := ev.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("expression evaluation interrupted: %w", err)
+	}
+
+	switch n := n.(type) {
+	case *literalNode:
+		return n.value, nil
+
+	case *identNode:
+		value, exists := ev.env[n.name]
+		if !exists {
+			return ev.tolerate(nil, &missingError{message: fmt.Sprintf("undefined variable %s", n.name)})
+		}
+		return normalize(value), nil
+
+	case *memberNode:
+		target, err := ev.eval(n.target)
+		if err != nil {
+			return nil, err
+		}
+		return ev.tolerate(member(target, n.name))
+
+	case *indexNode:
+		target, err := ev.eval(n.target)
+		if err != nil {
+			return nil, err
+		}
+		index, err := ev.eval(n.index)
+		if err != nil {
+			return nil, err
+		}
+		return ev.tolerate(indexValue(target, index))
+
+	case *listNode:
+		items := make([]interface{}, 0, len(n.items))
+		for _, item := range n.items {
+			value, err := ev.eval(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+
+	case *unaryNode:
+		operand, err := ev.eval(n.operand)
+		if err != nil {
+			return nil, err
+		}
+		if n.op == "!" {
+			b, err := truthy(operand, "!")
+			if err != nil {
+				return nil, err
+			}
+			return !b, nil
+		}
+		number, ok := toNumber(operand)
+		if !ok {
+			return nil, fmt.Errorf("operator - expects number, got %s", typeName(operand))
+		}
+		return -number, nil
+
+	case *binaryNode:
+		return ev.evalBinary(n)
+
+	case *callNode:
+		args, err := ev.evalArgs(n)
+		if err != nil {
+			return nil, err
+		}
+		value, err := n.fn.call(args)
+		if err != nil {
+			return nil, fmt.Errorf("%s(): %w", n.fn.name, err)
+		}
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("unsupported expression node %T", n)
+}
+
+// evalArgs 求值函数参数，空值判断函数的参数中不存在的变量或字段视为 null
+func (ev *evaluator) evalArgs(n *callNode) ([]interface{}, error) {
+	if n.fn.lenient {
+		ev.lenient++
+		defer func() { ev.lenient-- }()
+	}
+
+	args := make([]interface{}, 0, len(n.args))
+	for _, arg := range n.args {
+		value, err := ev.eval(arg)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+	return args, nil
+}
+
+// evalBinary 求值二元运算，&& 和 || 短路求值
+func (ev *evaluator) evalBinary(n *binaryNode) (interface{}, error) {
+	left, err := ev.eval(n.left)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "&&" || n.op == "||" {
+		l, err := truthy(left, n.op)
+		if err != nil {
+			return nil, err
+		}
+		if (n.op == "&&" && !l) || (n.op == "||" && l) {
+			return l, nil
+		}
+		right, err := ev.eval(n.right)
+		if err != nil {
+			return nil, err
+		}
+		return truthy(right, n.op)
+	}
+
+	right, err := ev.eval(n.right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		return compare(n.op, left, right)
+	case "in":
+		return contains(right, left)
+	default:
+		return arithmetic(n.op, left, right)
+	}
+}
+
+// normalize 将具体类型的数值统一为 float64
+func normalize(value interface{}) interface{} {
+	if number, ok := toNumber(value); ok {
+		return number
+	}
+	return value
+}
+
+// toNumber 转换数值类型，非数值返回false
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case interface{ Float64() (float64, error) }:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// typeName 返回值的表达式类型名，用于错误信息
+func typeName(value interface{}) string {
+	if value == nil {
+		return "null"
+	}
+	if _, ok := toNumber(value); ok {
+		return "number"
+	}
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Map:
+		return "map"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// truthy 逻辑运算的操作数必须为布尔值，null 视为 false
+func truthy(value interface{}, op string) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case nil:
+		return false, nil
+	}
+	return false, fmt.Errorf("operator %s expects boolean, got %s", op, typeName(value))
+}
+
+// equal 按类型判断相等，数值按数值比较，类型不同时不相等
+func equal(left, right interface{}) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	if l, ok := toNumber(left); ok {
+		r, ok := toNumber(right)
+		return ok && l == r
+	}
+	switch l := left.(type) {
+	case string:
+		r, ok := right.(string)
+		return ok && l == r
+	case bool:
+		r, ok := right.(bool)
+		return ok && l == r
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// compare 大小比较，只比较数值与数值、字符串与字符串，任一侧为 null 时为 false
+func compare(op string, left, right interface{}) (bool, error) {
+	if left == nil || right == nil {
+		return false, nil
+	}
+
+	var c int
+	if l, ok := toNumber(left); ok {
+		r, ok := toNumber(right)
+		if !ok {
+			return false, fmt.Errorf("cannot compare number with %s", typeName(right))
+		}
+		switch {
+		case l < r:
+			c = -1
+		case l > r:
+			c = 1
+		}
+	} else if l, ok := left.(string); ok {
+		r, ok := right.(string)
+		if !ok {
+			return false, fmt.Errorf("cannot compare string with %s", typeName(right))
+		}
+		c = strings.Compare(l, r)
+	} else {
+		return false, fmt.Errorf("operator %s does not support %s", op, typeName(left))
+	}
+
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+// contains 判断 item 是否在列表中、是否为映射的键或是否为字符串的子串
+func contains(collection, item interface{}) (bool, error) {
+	if collection == nil {
+		return false, nil
+	}
+	if s, ok := collection.(string); ok {
+		sub, ok := item.(string)
+		if !ok {
+			return false, fmt.Errorf("operator in on string expects string, got %s", typeName(item))
+		}
+		return strings.Contains(s, sub), nil
+	}
+
+	value := reflect.ValueOf(collection)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if equal(item, value.Index(i).Interface()) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case reflect.Map:
+		key, ok := item.(string)
+		if !ok || value.Type().Key().Kind() != reflect.String {
+			return false, nil
+		}
+		return value.MapIndex(reflect.ValueOf(key).Convert(value.Type().Key())).IsValid(), nil
+	}
+	return false, fmt.Errorf("operator in expects list, map or string, got %s", typeName(collection))
+}
+
+// arithmetic 算术运算，+ 同时支持字符串拼接
+func arithmetic(op string, left, right interface{}) (interface{}, error) {
+	if op == "+" {
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				return l + r, nil
+			}
+		}
+	}
+
+	l, lok := toNumber(left)
+	r, rok := toNumber(right)
+	if !lok || !rok {
+		return nil, fmt.Errorf("operator %s expects numbers, got %s and %s", op, typeName(left), typeName(right))
+	}
+
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	case "%":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(l, r), nil
+	}
+	return nil, fmt.Errorf("unsupported operator %s", op)
+}
+
+// member 读取映射字段，null 的字段为 null，不存在的字段报错
+func member(target interface{}, name string) (interface{}, error) {
+	if target == nil {
+		return nil, nil
+	}
+	if m, ok := target.(map[string]interface{}); ok {
+		value, exists := m[name]
+		if !exists {
+			return nil, &missingError{message: fmt.Sprintf("field %s not found", name)}
+		}
+		return normalize(value), nil
+	}
+
+	value := reflect.ValueOf(target)
+	if value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String {
+		field := value.MapIndex(reflect.ValueOf(name).Convert(value.Type().Key()))
+		if !field.IsValid() {
+			return nil, &missingError{message: fmt.Sprintf("field %s not found", name)}
+		}
+		return normalize(field.Interface()), nil
+	}
+	return nil, fmt.Errorf("cannot access field %s of %s", name, typeName(target))
+}
+
+// indexValue 按下标读取列表元素或按键读取映射字段，越界时为 null
+func indexValue(target interface{}, index interface{}) (interface{}, error) {
+	if target == nil {
+		return nil, nil
+	}
+	if key, ok := index.(string); ok {
+		if s, ok := target.(string); ok {
+			return nil, fmt.Errorf("cannot index string %q with string key", s)
+		}
+		return member(target, key)
+	}
+
+	number, ok := toNumber(index)
+	if !ok {
+		return nil, fmt.Errorf("index must be number or string, got %s", typeName(index))
+	}
+	if number != math.Trunc(number) {
+		return nil, fmt.Errorf("list index must be an integer, got %v", number)
+	}
+	i := int(number)
+
+	value := reflect.ValueOf(target)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		if i < 0 || i >= value.Len() {
+			return nil, nil
+		}
+		return normalize(value.Index(i).Interface()), nil
+	case reflect.String:
+		runes := []rune(value.String())
+		if i < 0 || i >= len(runes) {
+			return nil, nil
+		}
+		return string(runes[i]), nil
+	}
+	return nil, fmt.Errorf("cannot index %s", typeName(target))
+}
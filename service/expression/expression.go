@@ -0,0 +1,206 @@
+/**
+ * @module expression
+ * @description 沙箱条件表达式语言，用于边条件、条件节点分支和 while 循环条件
+ * @architecture 表达式先编译为语法树并缓存，求值只读访问变量环境，不支持赋值、方法调用和反射访问结构体
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow expression_flow: source -> compile(cache) -> evaluate(env, ctx) -> value
+ * @rules 比较按类型进行，不做隐式转换；访问不存在的变量或字段报错（isNull/isEmpty/coalesce 的参数中为 null）；
+ *        求值步数受限并响应 ctx 取消和超时
+ * @dependencies service/expression/parser.go, service/expression/eval.go, service/expression/functions.go
+ * @refs service/models/edge.go, service/control_executor.go, service/workflow_validator.go
+ */
+
+package expression
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// 表达式限制
+const (
+	MaxExpressionLength = 4096  // 表达式最大长度（字节）
+	maxEvaluationSteps  = 10000 // 单次求值最多访问的语法树节点数
+	maxCachedPrograms   = 1024  // 编译缓存最大条目数
+)
+
+// SyntaxError 表达式语法错误
+type SyntaxError struct {
+	Expression string `json:"expression"`
+	Position   int    `json:"position"` // 出错位置（字节偏移）
+	Message    string `json:"message"`
+}
+
+// Error 实现 error 接口
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Position, e.Message)
+}
+
+// newSyntaxError 创建语法错误
+func newSyntaxError(source string, position int, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{
+		Expression: source,
+		Position:   position,
+		Message:    fmt.Sprintf(format, args...),
+	}
+}
+
+// Program 编译后的表达式，可并发求值
+type Program struct {
+	source string
+	root   node
+}
+
+// Source 返回表达式原文
+func (p *Program) Source() string {
+	return p.source
+}
+
+var (
+	cacheMu sync.RWMutex
+	cache   = make(map[string]*Program)
+)
+
+// Compile 编译表达式，相同表达式复用缓存的编译结果
+func Compile(source string) (*Program, error) {
+	cacheMu.RLock()
+	program, exists := cache[source]
+	cacheMu.RUnlock()
+	if exists {
+		return program, nil
+	}
+
+	if len(source) > MaxExpressionLength {
+		return nil, newSyntaxError(source, MaxExpressionLength, "expression exceeds %d bytes", MaxExpressionLength)
+	}
+	root, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+	program = &Program{source: source, root: root}
+
+	cacheMu.Lock()
+	if len(cache) >= maxCachedPrograms {
+		cache = make(map[string]*Program)
+	}
+	cache[source] = program
+	cacheMu.Unlock()
+
+	return program, nil
+}
+
+// Check 只检查表达式语法，供保存工作流时校验
+func Check(source string) error {
+	_, err := Compile(source)
+	return err
+}
+
+// References 返回表达式引用的变量路径：根变量名及其后的静态字段名（a.b、a['b']），遇到动态下标截止；
+// 访问链的每一级前缀都会返回，供保存时检查引用的节点是否存在
+func (p *Program) References() [][]string {
+	var refs [][]string
+	seen := make(map[string]bool)
+	var walk func(n node)
+	walk = func(n node) {
+		if path, complete := referencePath(n); complete {
+			key := strings.Join(path, "\x00")
+			if !seen[key] {
+				seen[key] = true
+				refs = append(refs, path)
+			}
+		}
+
+		switch n := n.(type) {
+		case *memberNode:
+			walk(n.target)
+		case *indexNode:
+			walk(n.target)
+			walk(n.index)
+		case *listNode:
+			for _, item := range n.items {
+				walk(item)
+			}
+		case *unaryNode:
+			walk(n.operand)
+		case *binaryNode:
+			walk(n.left)
+			walk(n.right)
+		case *callNode:
+			for _, arg := range n.args {
+				walk(arg)
+			}
+		}
+	}
+	walk(p.root)
+	return refs
+}
+
+// referencePath 返回访问链的静态路径，链中含动态下标时 complete 为 false
+func referencePath(n node) (path []string, complete bool) {
+	switch n := n.(type) {
+	case *identNode:
+		return []string{n.name}, true
+	case *memberNode:
+		if path, complete := referencePath(n.target); complete {
+			return append(path[:len(path):len(path)], n.name), true
+		}
+	case *indexNode:
+		literal, ok := n.index.(*literalNode)
+		if !ok {
+			return nil, false
+		}
+		key, ok := literal.value.(string)
+		if !ok {
+			return nil, false
+		}
+		if path, complete := referencePath(n.target); complete {
+			return append(path[:len(path):len(path)], key), true
+		}
+	}
+	return nil, false
+}
+
+// Evaluate 在变量环境中求值
+func (p *Program) Evaluate(ctx context.Context, env map[string]interface{}) (interface{}, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ev := &evaluator{ctx: ctx, env: env}
+	return ev.eval(p.root)
+}
+
+// EvaluateBool 求值并要求结果为布尔值，null 视为 false
+func (p *Program) EvaluateBool(ctx context.Context, env map[string]interface{}) (bool, error) {
+	value, err := p.Evaluate(ctx, env)
+	if err != nil {
+		return false, err
+	}
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case nil:
+		return false, nil
+	default:
+		return false, fmt.Errorf("expression must evaluate to a boolean, got %s", typeName(value))
+	}
+}
+
+// Evaluate 编译并求值表达式
+func Evaluate(ctx context.Context, source string, env map[string]interface{}) (interface{}, error) {
+	program, err := Compile(source)
+	if err != nil {
+		return nil, err
+	}
+	return program.Evaluate(ctx, env)
+}
+
+// EvaluateBool 编译并求值布尔表达式
+func EvaluateBool(ctx context.Context, source string, env map[string]interface{}) (bool, error) {
+	program, err := Compile(source)
+	if err != nil {
+		return false, err
+	}
+	return program.EvaluateBool(ctx, env)
+}
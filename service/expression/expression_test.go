@@ -0,0 +1,355 @@
+package expression
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLexer(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		kinds   []tokenKind
+		values  []interface{} // 与 kinds 对应，运算符和标识符比较 text，字面量比较 value
+		wantErr string
+	}{
+		{
+			name:   "operators",
+			source: "a >= 1 && b != 'x'",
+			kinds:  []tokenKind{tokenIdent, tokenOperator, tokenNumber, tokenOperator, tokenIdent, tokenOperator, tokenString},
+			values: []interface{}{"a", ">=", 1.0, "&&", "b", "!=", "x"},
+		},
+		{
+			name:   "single equals is equality",
+			source: "a = 1",
+			kinds:  []tokenKind{tokenIdent, tokenOperator, tokenNumber},
+			values: []interface{}{"a", "==", 1.0},
+		},
+		{
+			name:   "numbers",
+			source: "1.5 .5 2e3",
+			kinds:  []tokenKind{tokenNumber, tokenNumber, tokenNumber},
+			values: []interface{}{1.5, 0.5, 2000.0},
+		},
+		{
+			name:   "string escapes",
+			source: `"a\"b\n" 'c\'d'`,
+			kinds:  []tokenKind{tokenString, tokenString},
+			values: []interface{}{"a\"b\n", "c'd"},
+		},
+		{
+			name:   "member and index",
+			source: "node1.output.data[0]",
+			kinds:  []tokenKind{tokenIdent, tokenDot, tokenIdent, tokenDot, tokenIdent, tokenLBracket, tokenNumber, tokenRBracket},
+			values: []interface{}{"node1", ".", "output", ".", "data", "[", 0.0, "]"},
+		},
+		{name: "unterminated string", source: "'abc", wantErr: "unterminated string"},
+		{name: "invalid escape", source: `'\q'`, wantErr: "invalid escape sequence"},
+		{name: "unexpected character", source: "a # b", wantErr: "unexpected character"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &lexer{source: tt.source}
+			var kinds []tokenKind
+			var values []interface{}
+			for {
+				tok, err := l.next()
+				if err != nil {
+					if tt.wantErr == "" || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Fatalf("next() error = %v, want %q", err, tt.wantErr)
+					}
+					return
+				}
+				if tok.kind == tokenEOF {
+					break
+				}
+				kinds = append(kinds, tok.kind)
+				if tok.kind == tokenNumber || tok.kind == tokenString {
+					values = append(values, tok.value)
+				} else {
+					values = append(values, tok.text)
+				}
+			}
+			if tt.wantErr != "" {
+				t.Fatalf("expected error %q, got tokens %v", tt.wantErr, values)
+			}
+			if !reflect.DeepEqual(kinds, tt.kinds) {
+				t.Errorf("kinds = %v, want %v", kinds, tt.kinds)
+			}
+			if !reflect.DeepEqual(values, tt.values) {
+				t.Errorf("values = %v, want %v", values, tt.values)
+			}
+		})
+	}
+}
+
+func TestCompileSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		wantErr  string
+		position int
+	}{
+		{name: "empty", source: "", wantErr: "unexpected end of expression", position: 0},
+		{name: "dangling operator", source: "a &&", wantErr: "unexpected end of expression", position: 4},
+		{name: "unclosed paren", source: "(a", wantErr: ")", position: 2},
+		{name: "chained comparison", source: "1 < a < 3", wantErr: "cannot be chained", position: 6},
+		{name: "unknown function", source: "foo(1)", wantErr: "unknown function foo", position: 0},
+		{name: "wrong arity", source: "len(1, 2)", wantErr: "len", position: 0},
+		{name: "trailing token", source: "a b", wantErr: "unexpected", position: 2},
+		{name: "too long", source: strings.Repeat("a", MaxExpressionLength+1), wantErr: "exceeds", position: MaxExpressionLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.source)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Check(%q) error = %v, want *SyntaxError", tt.source, err)
+			}
+			if !strings.Contains(syntaxErr.Message, tt.wantErr) {
+				t.Errorf("message = %q, want to contain %q", syntaxErr.Message, tt.wantErr)
+			}
+			if syntaxErr.Position != tt.position {
+				t.Errorf("position = %d, want %d", syntaxErr.Position, tt.position)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	env := map[string]interface{}{
+		"count":  3,
+		"name":   "Alice",
+		"empty":  "",
+		"absent": nil,
+		"tags":   []interface{}{"a", "b"},
+		"node1": map[string]interface{}{
+			"output": map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{"amount": 12.5},
+				},
+			},
+		},
+		"typed": map[string]int{"n": 2},
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		want    interface{}
+		wantErr string
+	}{
+		{name: "arithmetic precedence", source: "1 + 2 * 3 - 4 / 2", want: 5.0},
+		{name: "modulo", source: "count % 2", want: 1.0},
+		{name: "unary minus", source: "-count + 1", want: -2.0},
+		{name: "string concat", source: "name + '!'", want: "Alice!"},
+		{name: "comparison", source: "count >= 3 && count < 4", want: true},
+		{name: "equality across numeric types", source: "count == 3.0", want: true},
+		{name: "not", source: "!(count > 5)", want: true},
+		{name: "short circuit or", source: "true || missing.field", want: true},
+		{name: "short circuit and", source: "false && missing", want: false},
+		{name: "in list", source: "'b' in tags", want: true},
+		{name: "in string", source: "'lic' in name", want: true},
+		{name: "list literal", source: "[1, 'x', null]", want: []interface{}{1.0, "x", nil}},
+		{name: "nested member and index", source: "node1.output.data[0].amount", want: 12.5},
+		{name: "string key index", source: "node1['output']['data'][0]['amount'] * 2", want: 25.0},
+		{name: "typed map member", source: "typed.n + 1", want: 3.0},
+		{name: "out of range index is null", source: "tags[5]", want: nil},
+		{name: "member of null is null", source: "absent.field", want: nil},
+		{name: "functions", source: "upper(name) + lower('X') + string(len(tags))", want: "ALICEx2"},
+		{name: "matches", source: "matches(name, '^A')", want: true},
+		{name: "number conversion", source: "number('4') + 1", want: 5.0},
+		{name: "undefined variable", source: "missing == 1", wantErr: "undefined variable missing"},
+		{name: "missing field", source: "node1.result", wantErr: "field result not found"},
+		{name: "missing nested field", source: "node1.output.data[0].total", wantErr: "field total not found"},
+		{name: "isNull tolerates missing", source: "isNull(missing.field)", want: true},
+		{name: "isEmpty tolerates missing", source: "isEmpty(node1.result) && isEmpty(empty)", want: true},
+		{name: "coalesce tolerates missing", source: "coalesce(node1.result, name)", want: "Alice"},
+		{name: "lenient only applies inside the call", source: "isNull(absent) && missing", wantErr: "undefined variable missing"},
+		{name: "division by zero", source: "count / 0", wantErr: "division by zero"},
+		{name: "type mismatch", source: "name - 1", wantErr: "expects numbers"},
+		{name: "field of scalar", source: "count.value", wantErr: "cannot access field value"},
+		{name: "fractional index", source: "tags[0.5]", wantErr: "must be an integer"},
+		{name: "boolean operand required", source: "count && true", wantErr: "&&"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Evaluate(context.Background(), tt.source, env)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Evaluate(%q) error = %v, want %q", tt.source, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Evaluate(%q) error = %v", tt.source, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate(%q) = %#v, want %#v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateBool(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    bool
+		wantErr bool
+	}{
+		{name: "true", source: "1 < 2", want: true},
+		{name: "null is false", source: "null", want: false},
+		{name: "non boolean", source: "1 + 1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EvaluateBool(context.Background(), tt.source, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EvaluateBool(%q) error = %v, wantErr %v", tt.source, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("EvaluateBool(%q) = %v, want %v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Evaluate(ctx, "1 + 1", nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Evaluate() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestReferences(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   [][]string
+	}{
+		{name: "literal", source: "1 + 2", want: nil},
+		{name: "identifier", source: "count > 1", want: [][]string{{"count"}}},
+		{
+			name:   "member chain prefixes",
+			source: "node1.output.data",
+			want:   [][]string{{"node1", "output", "data"}, {"node1", "output"}, {"node1"}},
+		},
+		{
+			name:   "string key index",
+			source: "nodes['a'].out",
+			want:   [][]string{{"nodes", "a", "out"}, {"nodes", "a"}, {"nodes"}},
+		},
+		{
+			name:   "dynamic index stops the path",
+			source: "items[i].name",
+			want:   [][]string{{"items"}, {"i"}},
+		},
+		{
+			name:   "function arguments are walked",
+			source: "len(a.b) > c",
+			want:   [][]string{{"a", "b"}, {"a"}, {"c"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.source)
+			if err != nil {
+				t.Fatalf("Compile(%q) error = %v", tt.source, err)
+			}
+			if got := program.References(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("References() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	env := map[string]interface{}{
+		"name":  "Bob",
+		"count": 2,
+		"items": []interface{}{1, "x"},
+	}
+
+	tests := []struct {
+		name     string
+		source   string
+		want     interface{}
+		wantErr  string
+		position int
+	}{
+		{name: "plain text", source: "hello", want: "hello"},
+		{name: "single expression keeps type", source: "{{count + 1}}", want: 3.0},
+		{name: "interpolation", source: "hi {{name}}, {{count}} items", want: "hi Bob, 2 items"},
+		{name: "list is rendered as json", source: "items: {{items}}", want: `items: [1,"x"]`},
+		{name: "null is empty", source: "[{{null}}]", want: "[]"},
+		{name: "unclosed", source: "a {{name", wantErr: "unclosed template expression", position: 2},
+		{name: "empty expression", source: "x {{ }}", wantErr: "empty template expression", position: 2},
+		{name: "syntax error offset", source: "ab {{1 +}}", wantErr: "unexpected end of expression", position: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate(tt.source)
+			if tt.wantErr != "" {
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &syntaxErr) {
+					t.Fatalf("ParseTemplate(%q) error = %v, want *SyntaxError", tt.source, err)
+				}
+				if !strings.Contains(syntaxErr.Message, tt.wantErr) || syntaxErr.Position != tt.position {
+					t.Errorf("error = %q at %d, want %q at %d", syntaxErr.Message, syntaxErr.Position, tt.wantErr, tt.position)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTemplate(%q) error = %v", tt.source, err)
+			}
+			got, err := tmpl.Render(context.Background(), env)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Render() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateBind(t *testing.T) {
+	tmpl, err := ParseTemplate("SELECT * FROM t WHERE a = {{a}} AND b = {{b.c}}")
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+
+	var args []interface{}
+	got, err := tmpl.Bind(context.Background(), map[string]interface{}{
+		"a": "x'; DROP TABLE t; --",
+		"b": map[string]interface{}{"c": 1},
+	}, func(value interface{}) (string, error) {
+		args = append(args, value)
+		return "?", nil
+	})
+	if err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if want := "SELECT * FROM t WHERE a = ? AND b = ?"; got != want {
+		t.Errorf("Bind() = %q, want %q", got, want)
+	}
+	if want := []interface{}{"x'; DROP TABLE t; --", 1.0}; !reflect.DeepEqual(args, want) {
+		t.Errorf("bound args = %#v, want %#v", args, want)
+	}
+
+	refs := tmpl.References()
+	if want := [][]string{{"a"}, {"b", "c"}, {"b"}}; !reflect.DeepEqual(refs, want) {
+		t.Errorf("References() = %v, want %v", refs, want)
+	}
+}
@@ -0,0 +1,232 @@
+/**
+ * @module expression_functions
+ * @description 条件表达式内置函数：字符串处理、空值判断、长度和类型转换
+ * @architecture 白名单函数表，编译阶段按名称解析并检查参数个数，未登记的函数名视为语法错误
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow 无状态
+ * @rules 函数无副作用；字符串函数的参数为 null 时返回 null 或 false；正则表达式编译结果缓存；
+ *        isNull、isEmpty、coalesce 的参数中不存在的变量或字段视为 null，用于判断可选值
+ * @dependencies regexp, strconv, strings
+ * @refs service/expression/parser.go, service/expression/eval.go, service/expression/dates.go
+ */
+
+package expression
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// function 内置函数定义
+type function struct {
+	name    string
+	minArgs int
+	maxArgs int // -1 表示不限
+	call    func(args []interface{}) (interface{}, error)
+	lenient bool // 参数中不存在的变量或字段视为 null
+}
+
+// arity 描述参数个数，用于错误信息
+func (f *function) arity() string {
+	switch {
+	case f.maxArgs < 0:
+		return fmt.Sprintf("at least %d arguments", f.minArgs)
+	case f.minArgs == 1 && f.maxArgs == 1:
+		return "1 argument"
+	case f.minArgs == f.maxArgs:
+		return fmt.Sprintf("%d arguments", f.minArgs)
+	default:
+		return fmt.Sprintf("%d to %d arguments", f.minArgs, f.maxArgs)
+	}
+}
+
+// functions 内置函数表
+var functions = map[string]*function{}
+
+// register 登记内置函数
+func register(name string, minArgs, maxArgs int, call func(args []interface{}) (interface{}, error)) {
+	functions[name] = &function{name: name, minArgs: minArgs, maxArgs: maxArgs, call: call}
+}
+
+// init 登记内置函数
+func init() {
+	register("len", 1, 1, fnLen)
+	register("lower", 1, 1, stringMapper(strings.ToLower))
+	register("upper", 1, 1, stringMapper(strings.ToUpper))
+	register("trim", 1, 1, stringMapper(strings.TrimSpace))
+	register("contains", 2, 2, fnContains)
+	register("startsWith", 2, 2, stringPredicate(strings.HasPrefix))
+	register("endsWith", 2, 2, stringPredicate(strings.HasSuffix))
+	register("matches", 2, 2, fnMatches)
+	register("isNull", 1, 1, fnIsNull)
+	register("isEmpty", 1, 1, fnIsEmpty)
+	register("coalesce", 1, -1, fnCoalesce)
+	register("number", 1, 1, fnNumber)
+	register("string", 1, 1, fnString)
+
+	for _, name := range []string{"isNull", "isEmpty", "coalesce"} {
+		functions[name].lenient = true
+	}
+}
+
+// stringMapper 构造字符串转换函数，null 返回 null
+func stringMapper(mapper func(string) string) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("expects string, got %s", typeName(args[0]))
+		}
+		return mapper(s), nil
+	}
+}
+
+// stringPredicate 构造字符串判断函数，任一参数为 null 时为 false
+func stringPredicate(predicate func(string, string) bool) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if args[0] == nil || args[1] == nil {
+			return false, nil
+		}
+		s, ok1 := args[0].(string)
+		sub, ok2 := args[1].(string)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("expects strings, got %s and %s", typeName(args[0]), typeName(args[1]))
+		}
+		return predicate(s, sub), nil
+	}
+}
+
+// fnLen 字符串、列表或映射的长度，null 为 0
+func fnLen(args []interface{}) (interface{}, error) {
+	value := args[0]
+	if value == nil {
+		return float64(0), nil
+	}
+	if s, ok := value.(string); ok {
+		return float64(utf8.RuneCountInString(s)), nil
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(reflect.ValueOf(value).Len()), nil
+	}
+	return nil, fmt.Errorf("expects string, list or map, got %s", typeName(value))
+}
+
+// fnContains 字符串包含子串或列表包含元素
+func fnContains(args []interface{}) (interface{}, error) {
+	return contains(args[0], args[1])
+}
+
+var (
+	regexMu    sync.RWMutex
+	regexCache = make(map[string]*regexp.Regexp)
+)
+
+// fnMatches 判断字符串是否匹配正则表达式
+func fnMatches(args []interface{}) (interface{}, error) {
+	if args[0] == nil {
+		return false, nil
+	}
+	s, ok1 := args[0].(string)
+	pattern, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("expects strings, got %s and %s", typeName(args[0]), typeName(args[1]))
+	}
+
+	regexMu.RLock()
+	re, exists := regexCache[pattern]
+	regexMu.RUnlock()
+	if !exists {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		re = compiled
+		regexMu.Lock()
+		if len(regexCache) >= maxCachedPrograms {
+			regexCache = make(map[string]*regexp.Regexp)
+		}
+		regexCache[pattern] = re
+		regexMu.Unlock()
+	}
+	return re.MatchString(s), nil
+}
+
+// fnIsNull 判断是否为 null（含不存在的变量和字段）
+func fnIsNull(args []interface{}) (interface{}, error) {
+	return args[0] == nil, nil
+}
+
+// fnIsEmpty 判断是否为 null、空字符串、空列表或空映射
+func fnIsEmpty(args []interface{}) (interface{}, error) {
+	value := args[0]
+	if value == nil {
+		return true, nil
+	}
+	if s, ok := value.(string); ok {
+		return s == "", nil
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return reflect.ValueOf(value).Len() == 0, nil
+	}
+	return false, nil
+}
+
+// fnCoalesce 返回第一个非 null 参数
+func fnCoalesce(args []interface{}) (interface{}, error) {
+	for _, arg := range args {
+		if arg != nil {
+			return arg, nil
+		}
+	}
+	return nil, nil
+}
+
+// fnNumber 转换为数值，支持数值字符串和布尔值
+func fnNumber(args []interface{}) (interface{}, error) {
+	value := args[0]
+	if number, ok := toNumber(value); ok {
+		return number, nil
+	}
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %q to number", v)
+		}
+		return number, nil
+	case bool:
+		if v {
+			return float64(1), nil
+		}
+		return float64(0), nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to number", typeName(value))
+}
+
+// fnString 转换为字符串，数值使用最短表示
+func fnString(args []interface{}) (interface{}, error) {
+	value := args[0]
+	if number, ok := toNumber(value); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	}
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return fmt.Sprint(value), nil
+}
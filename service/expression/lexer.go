@@ -0,0 +1,211 @@
+/**
+ * @module expression_lexer
+ * @description 条件表达式词法分析，将表达式文本切分为数字、字符串、标识符、运算符和分隔符
+ * @architecture 表达式引擎前端，语法分析器按需逐个读取词法单元
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow lex_flow: source -> tokens -> EOF
+ * @rules 字符串支持单引号和双引号及常用转义；单个 = 视为 == 以兼容旧表达式
+ * @dependencies strconv, unicode
+ * @refs service/expression/parser.go
+ */
+
+package expression
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+	tokenDot
+)
+
+// token 词法单元
+type token struct {
+	kind   tokenKind
+	text   string      // 运算符和标识符的文本
+	value  interface{} // 数字和字符串字面量的值
+	offset int         // 在表达式中的起始位置
+}
+
+// lexer 词法分析器
+type lexer struct {
+	source string
+	offset int
+}
+
+// twoCharOperators 双字符运算符
+var twoCharOperators = []string{"==", "!=", "<=", ">=", "&&", "||"}
+
+// next 读取下一个词法单元
+func (l *lexer) next() (token, error) {
+	for l.offset < len(l.source) {
+		r, size := utf8.DecodeRuneInString(l.source[l.offset:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.offset += size
+	}
+
+	start := l.offset
+	if start >= len(l.source) {
+		return token{kind: tokenEOF, offset: start}, nil
+	}
+
+	rest := l.source[start:]
+	for _, op := range twoCharOperators {
+		if strings.HasPrefix(rest, op) {
+			l.offset += len(op)
+			return token{kind: tokenOperator, text: op, offset: start}, nil
+		}
+	}
+
+	c := rest[0]
+	switch c {
+	case '(':
+		l.offset++
+		return token{kind: tokenLParen, text: "(", offset: start}, nil
+	case ')':
+		l.offset++
+		return token{kind: tokenRParen, text: ")", offset: start}, nil
+	case '[':
+		l.offset++
+		return token{kind: tokenLBracket, text: "[", offset: start}, nil
+	case ']':
+		l.offset++
+		return token{kind: tokenRBracket, text: "]", offset: start}, nil
+	case ',':
+		l.offset++
+		return token{kind: tokenComma, text: ",", offset: start}, nil
+	case '=':
+		l.offset++
+		return token{kind: tokenOperator, text: "==", offset: start}, nil
+	case '<', '>', '!', '+', '-', '*', '/', '%':
+		l.offset++
+		return token{kind: tokenOperator, text: string(c), offset: start}, nil
+	case '\'', '"':
+		return l.lexString(c)
+	case '.':
+		if len(rest) > 1 && isDigit(rest[1]) {
+			return l.lexNumber()
+		}
+		l.offset++
+		return token{kind: tokenDot, text: ".", offset: start}, nil
+	}
+
+	if isDigit(c) {
+		return l.lexNumber()
+	}
+
+	r, _ := utf8.DecodeRuneInString(rest)
+	if isIdentStart(r) {
+		for l.offset < len(l.source) {
+			r, size := utf8.DecodeRuneInString(l.source[l.offset:])
+			if !isIdentPart(r) {
+				break
+			}
+			l.offset += size
+		}
+		return token{kind: tokenIdent, text: l.source[start:l.offset], offset: start}, nil
+	}
+
+	return token{}, newSyntaxError(l.source, start, "unexpected character %q", r)
+}
+
+// lexNumber 读取数字字面量，支持小数和指数
+func (l *lexer) lexNumber() (token, error) {
+	start := l.offset
+	for l.offset < len(l.source) && isDigit(l.source[l.offset]) {
+		l.offset++
+	}
+	if l.offset < len(l.source) && l.source[l.offset] == '.' {
+		l.offset++
+		for l.offset < len(l.source) && isDigit(l.source[l.offset]) {
+			l.offset++
+		}
+	}
+	if l.offset < len(l.source) && (l.source[l.offset] == 'e' || l.source[l.offset] == 'E') {
+		l.offset++
+		if l.offset < len(l.source) && (l.source[l.offset] == '+' || l.source[l.offset] == '-') {
+			l.offset++
+		}
+		for l.offset < len(l.source) && isDigit(l.source[l.offset]) {
+			l.offset++
+		}
+	}
+
+	text := l.source[start:l.offset]
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return token{}, newSyntaxError(l.source, start, "invalid number %s", text)
+	}
+	return token{kind: tokenNumber, text: text, value: value, offset: start}, nil
+}
+
+// lexString 读取字符串字面量
+func (l *lexer) lexString(quote byte) (token, error) {
+	start := l.offset
+	l.offset++
+
+	var builder strings.Builder
+	for l.offset < len(l.source) {
+		c := l.source[l.offset]
+		switch {
+		case c == quote:
+			l.offset++
+			return token{kind: tokenString, text: l.source[start:l.offset], value: builder.String(), offset: start}, nil
+		case c == '\\':
+			if l.offset+1 >= len(l.source) {
+				return token{}, newSyntaxError(l.source, start, "unterminated string")
+			}
+			escaped := l.source[l.offset+1]
+			switch escaped {
+			case 'n':
+				builder.WriteByte('\n')
+			case 't':
+				builder.WriteByte('\t')
+			case 'r':
+				builder.WriteByte('\r')
+			case '\\', '\'', '"':
+				builder.WriteByte(escaped)
+			default:
+				return token{}, newSyntaxError(l.source, l.offset, "invalid escape sequence \\%c", escaped)
+			}
+			l.offset += 2
+		default:
+			builder.WriteByte(c)
+			l.offset++
+		}
+	}
+	return token{}, newSyntaxError(l.source, start, "unterminated string")
+}
+
+// isDigit 判断是否为十进制数字
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isIdentStart 判断是否可以作为标识符首字符
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// isIdentPart 判断是否可以作为标识符后续字符
+func isIdentPart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
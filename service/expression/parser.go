@@ -0,0 +1,445 @@
+/**
+ * @module expression_parser
+ * @description 条件表达式语法分析，按运算符优先级将词法单元构造成语法树
+ * @architecture 递归下降语法分析器，编译阶段检查函数名、参数个数和正则表达式，语法错误带位置信息
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow parse_flow: tokens -> ast -> program
+ * @rules 优先级从低到高：|| < && < 比较/in < 加减 < 乘除取模 < 一元 ! - < 路径访问；比较运算不可连写；嵌套深度受限
+ * @dependencies service/expression/lexer.go
+ * @refs service/expression/eval.go, service/expression/functions.go
+ */
+
+package expression
+
+import (
+	"regexp"
+)
+
+// maxNestingDepth 语法树最大嵌套深度
+const maxNestingDepth = 64
+
+// node 语法树节点
+type node interface{}
+
+// literalNode 字面量
+type literalNode struct {
+	value interface{}
+}
+
+// identNode 根变量引用
+type identNode struct {
+	name string
+}
+
+// memberNode 字段访问 a.b
+type memberNode struct {
+	target node
+	name   string
+}
+
+// indexNode 下标访问 a[expr]
+type indexNode struct {
+	target node
+	index  node
+}
+
+// listNode 列表字面量 [a, b]
+type listNode struct {
+	items []node
+}
+
+// unaryNode 一元运算
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+// binaryNode 二元运算
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+// callNode 内置函数调用
+type callNode struct {
+	fn   *function
+	args []node
+}
+
+// parser 语法分析器
+type parser struct {
+	lexer   *lexer
+	current token
+	depth   int
+}
+
+// parse 解析完整表达式
+func parse(source string) (node, error) {
+	p := &parser{lexer: &lexer{source: source}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.current.kind != tokenEOF {
+		return nil, p.errorf("unexpected %s", p.describe())
+	}
+	return root, nil
+}
+
+// advance 读取下一个词法单元
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.current = tok
+	return nil
+}
+
+// isOperator 判断当前词法单元是否为指定运算符
+func (p *parser) isOperator(ops ...string) bool {
+	if p.current.kind != tokenOperator && !(p.current.kind == tokenIdent && p.current.text == "in") {
+		return false
+	}
+	for _, op := range ops {
+		if p.current.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+// enter 进入一层嵌套，超过最大深度时报错
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > maxNestingDepth {
+		return p.errorf("expression is nested too deeply")
+	}
+	return nil
+}
+
+// leave 退出一层嵌套
+func (p *parser) leave() {
+	p.depth--
+}
+
+// parseOr 解析 ||
+func (p *parser) parseOr() (node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("||") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd 解析 &&
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("&&") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseComparison 解析比较运算和 in，不允许连写
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOperator("==", "!=", "<", "<=", ">", ">=", "in") {
+		return left, nil
+	}
+
+	op := p.current.text
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOperator("==", "!=", "<", "<=", ">", ">=", "in") {
+		return nil, p.errorf("comparison operators cannot be chained, use && instead")
+	}
+	return &binaryNode{op: op, left: left, right: right}, nil
+}
+
+// parseAdditive 解析加减
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("+", "-") {
+		op := p.current.text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseMultiplicative 解析乘除和取模
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("*", "/", "%") {
+		op := p.current.text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseUnary 解析一元 ! 和 -
+func (p *parser) parseUnary() (node, error) {
+	if !p.isOperator("!", "-") {
+		return p.parsePostfix()
+	}
+
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	op := p.current.text
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &unaryNode{op: op, operand: operand}, nil
+}
+
+// parsePostfix 解析字段访问和下标访问
+func (p *parser) parsePostfix() (node, error) {
+	target, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch p.current.kind {
+		case tokenDot:
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if p.current.kind != tokenIdent {
+				return nil, p.errorf("expected field name after '.', got %s", p.describe())
+			}
+			target = &memberNode{target: target, name: p.current.text}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		case tokenLBracket:
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			index, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(tokenRBracket, "]"); err != nil {
+				return nil, err
+			}
+			target = &indexNode{target: target, index: index}
+		default:
+			return target, nil
+		}
+	}
+}
+
+// parsePrimary 解析字面量、变量、函数调用、括号表达式和列表
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.current
+	switch tok.kind {
+	case tokenNumber, tokenString:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return &literalNode{value: tok.value}, nil
+
+	case tokenIdent:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null", "nil":
+			return &literalNode{value: nil}, nil
+		case "in":
+			return nil, newSyntaxError(p.lexer.source, tok.offset, "unexpected operator in")
+		}
+		if p.current.kind == tokenLParen {
+			return p.parseCall(tok)
+		}
+		return &identNode{name: tok.text}, nil
+
+	case tokenLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+
+	case tokenLBracket:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		items, err := p.parseList(tokenRBracket, "]")
+		if err != nil {
+			return nil, err
+		}
+		return &listNode{items: items}, nil
+
+	case tokenEOF:
+		return nil, p.errorf("unexpected end of expression")
+	}
+
+	return nil, p.errorf("unexpected %s", p.describe())
+}
+
+// parseCall 解析内置函数调用，编译阶段检查函数名、参数个数和正则表达式字面量
+func (p *parser) parseCall(name token) (node, error) {
+	fn, exists := functions[name.text]
+	if !exists {
+		return nil, newSyntaxError(p.lexer.source, name.offset, "unknown function %s", name.text)
+	}
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	args, err := p.parseList(tokenRParen, ")")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, newSyntaxError(p.lexer.source, name.offset, "function %s expects %s, got %d", name.text, fn.arity(), len(args))
+	}
+	if fn.name == "matches" {
+		if literal, ok := args[1].(*literalNode); ok {
+			pattern, ok := literal.value.(string)
+			if !ok {
+				return nil, newSyntaxError(p.lexer.source, name.offset, "function matches expects a string pattern")
+			}
+			if _, err := regexp.Compile(pattern); err != nil {
+				return nil, newSyntaxError(p.lexer.source, name.offset, "invalid regular expression: %v", err)
+			}
+		}
+	}
+
+	return &callNode{fn: fn, args: args}, nil
+}
+
+// parseList 解析逗号分隔的表达式列表，直到结束符
+func (p *parser) parseList(end tokenKind, endText string) ([]node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	var items []node
+	if p.current.kind == end {
+		return items, p.advance()
+	}
+	for {
+		item, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		if p.current.kind == tokenComma {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := p.expect(end, endText); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+}
+
+// expect 要求当前词法单元为指定类型并前进
+func (p *parser) expect(kind tokenKind, text string) error {
+	if p.current.kind != kind {
+		return p.errorf("expected '%s', got %s", text, p.describe())
+	}
+	return p.advance()
+}
+
+// describe 描述当前词法单元，用于错误信息
+func (p *parser) describe() string {
+	switch p.current.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenNumber, tokenString:
+		return "literal " + p.current.text
+	case tokenIdent:
+		return "identifier " + p.current.text
+	default:
+		return "'" + p.current.text + "'"
+	}
+}
+
+// errorf 在当前位置生成语法错误
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return newSyntaxError(p.lexer.source, p.current.offset, format, args...)
+}
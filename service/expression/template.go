@@ -0,0 +1,151 @@
+/**
+ * @module expression_template
+ * @description 配置模板，将字符串中的 {{ 表达式 }} 替换为求值结果或绑定为参数占位符
+ * @architecture 模板拆分为文本片段和表达式片段，表达式片段复用条件表达式的编译缓存和求值器
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow template_flow: source -> segments -> render(env)/bind(env, placeholder) -> value
+ * @rules 整个字符串只有一个表达式时保留求值结果的类型；与文本混排时转换为字符串，null 为空串；
+ *        绑定模式下表达式的值只交给占位符回调，不拼接进结果文本
+ * @dependencies service/expression/expression.go
+ * @refs service/node_config_template.go
+ */
+
+package expression
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 模板定界符
+const (
+	templateOpen  = "{{"
+	templateClose = "}}"
+)
+
+// Template 解析后的模板
+type Template struct {
+	source   string
+	texts    []string   // 文本片段，比表达式片段多一个
+	programs []*Program // 表达式片段
+}
+
+// IsTemplate 判断字符串是否包含模板表达式
+func IsTemplate(source string) bool {
+	return strings.Contains(source, templateOpen)
+}
+
+// ParseTemplate 解析模板，表达式语法错误的位置相对于整个模板
+func ParseTemplate(source string) (*Template, error) {
+	t := &Template{source: source}
+
+	rest := source
+	offset := 0
+	for {
+		start := strings.Index(rest, templateOpen)
+		if start < 0 {
+			t.texts = append(t.texts, rest)
+			return t, nil
+		}
+		end := strings.Index(rest[start+len(templateOpen):], templateClose)
+		if end < 0 {
+			return nil, newSyntaxError(source, offset+start, "unclosed template expression, missing '%s'", templateClose)
+		}
+
+		exprStart := start + len(templateOpen)
+		exprSource := rest[exprStart : exprStart+end]
+		if strings.TrimSpace(exprSource) == "" {
+			return nil, newSyntaxError(source, offset+start, "empty template expression")
+		}
+		program, err := Compile(exprSource)
+		if err != nil {
+			if syntaxErr, ok := err.(*SyntaxError); ok {
+				return nil, newSyntaxError(source, offset+exprStart+syntaxErr.Position, "%s", syntaxErr.Message)
+			}
+			return nil, err
+		}
+
+		t.texts = append(t.texts, rest[:start])
+		t.programs = append(t.programs, program)
+
+		consumed := exprStart + end + len(templateClose)
+		offset += consumed
+		rest = rest[consumed:]
+	}
+}
+
+// CheckTemplate 只检查模板语法，供保存工作流时校验
+func CheckTemplate(source string) error {
+	_, err := ParseTemplate(source)
+	return err
+}
+
+// Source 返回模板原文
+func (t *Template) Source() string {
+	return t.source
+}
+
+// References 返回模板中所有表达式引用的变量路径
+func (t *Template) References() [][]string {
+	var refs [][]string
+	for _, program := range t.programs {
+		refs = append(refs, program.References()...)
+	}
+	return refs
+}
+
+// Render 渲染模板：整个模板为单个表达式时返回求值结果本身，否则返回拼接后的字符串
+func (t *Template) Render(ctx context.Context, env map[string]interface{}) (interface{}, error) {
+	if len(t.programs) == 0 {
+		return t.source, nil
+	}
+	if len(t.programs) == 1 && t.texts[0] == "" && t.texts[1] == "" {
+		return t.programs[0].Evaluate(ctx, env)
+	}
+
+	return t.Bind(ctx, env, Stringify)
+}
+
+// Bind 渲染模板，表达式的值交给占位符回调，结果文本中只出现回调返回的占位符
+func (t *Template) Bind(ctx context.Context, env map[string]interface{}, placeholder func(value interface{}) (string, error)) (string, error) {
+	var builder strings.Builder
+	for i, program := range t.programs {
+		builder.WriteString(t.texts[i])
+
+		value, err := program.Evaluate(ctx, env)
+		if err != nil {
+			return "", fmt.Errorf("failed to evaluate {{%s}}: %w", program.Source(), err)
+		}
+		text, err := placeholder(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to render {{%s}}: %w", program.Source(), err)
+		}
+		builder.WriteString(text)
+	}
+	builder.WriteString(t.texts[len(t.texts)-1])
+	return builder.String(), nil
+}
+
+// Stringify 将求值结果转换为模板文本：null 为空串，数值使用最短表示，列表和映射转为 JSON
+func Stringify(value interface{}) (string, error) {
+	if number, ok := toNumber(value); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	}
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("cannot render %s as text: %w", typeName(value), err)
+	}
+	return string(data), nil
+}
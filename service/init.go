@@ -5,7 +5,7 @@
  * @documentReference: /docs/flow-service-service-layer.md
  * @stateFlow: 无
  * @rules:
- *   - 服务启动时进行必要的初始化操作
+ *   - 服务启动时由程序入口调用 InitServices 进行初始化，导入包本身不连接数据库
  *   - 按依赖顺序初始化各个组件
  *   - 提供优雅的错误处理和回滚机制
  * @dependencies:
@@ -22,6 +22,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sync/atomic"
+	"time"
 
 	"flow-service/service/database"
 )
@@ -29,15 +31,18 @@ import (
 var GlobalWorkflowService *WorkflowService
 var GlobalExecutionService *ExecutionService
 
-func init() {
-	err := initDatabase()
-	if err != nil {
-		log.Fatalf("数据库初始化失败: %v", err)
+// shuttingDown 服务开始优雅关闭后置位，就绪检查据此失败
+var shuttingDown atomic.Bool
+
+// InitServices 初始化数据库和全局服务实例，由程序入口在注册路由前显式调用
+func InitServices() error {
+	if err := initDatabase(); err != nil {
+		return fmt.Errorf("数据库初始化失败: %w", err)
 	}
-	err = initServices()
-	if err != nil {
-		log.Fatalf("服务初始化失败: %v", err)
+	if err := initServices(); err != nil {
+		return fmt.Errorf("服务初始化失败: %w", err)
 	}
+	return nil
 }
 
 func initDatabase() error {
@@ -93,6 +98,51 @@ func initServices() error {
 	GlobalWorkflowService = NewWorkflowService(db, GlobalSimpleScheduler)
 	GlobalExecutionService = NewExecutionService(db, GlobalWorkflowService, GlobalEngine)
 
+	// 恢复服务重启前中断的执行
+	if err := GlobalExecutionService.RecoverExecutions(); err != nil {
+		log.Printf("恢复中断的执行失败: %v", err)
+	}
+
+	// 调度器按计划时间和数据区间触发定时执行，恢复活跃工作流的定时调度
+	GlobalSimpleScheduler.SetTrigger(func(workflowID string, scheduledAt, intervalStart, intervalEnd time.Time) error {
+		_, err := GlobalExecutionService.TriggerScheduledExecution(workflowID, scheduledAt, intervalStart, intervalEnd)
+		return err
+	})
+	if err := GlobalWorkflowService.RestoreSchedules(); err != nil {
+		log.Printf("恢复工作流调度失败: %v", err)
+	}
+
 	log.Println("服务初始化完成")
 	return nil
 }
+
+// IsShuttingDown 判断服务是否已开始优雅关闭
+func IsShuttingDown() bool {
+	return shuttingDown.Load()
+}
+
+// Shutdown 优雅关闭服务：停止调度器和新执行准入，在 ctx 结束前等待在途节点完成，
+// 未能结束的执行保存检查点或按恢复策略标记为中断，最后关闭数据库连接
+func Shutdown(ctx context.Context) error {
+	if !shuttingDown.CompareAndSwap(false, true) {
+		return fmt.Errorf("服务已在关闭中")
+	}
+	log.Println("开始优雅关闭服务")
+
+	if GlobalSimpleScheduler != nil {
+		if err := GlobalSimpleScheduler.Stop(); err != nil {
+			log.Printf("停止调度器失败: %v", err)
+		}
+	}
+
+	if GlobalExecutionService != nil {
+		GlobalExecutionService.Drain(ctx)
+	}
+
+	if err := database.CloseDatabase(); err != nil {
+		return fmt.Errorf("关闭数据库连接失败: %w", err)
+	}
+
+	log.Println("服务已关闭")
+	return nil
+}
@@ -8,10 +8,12 @@
  *   - 边必须连接两个不同的节点
  *   - 不能形成环路
  *   - 条件边必须指定条件表达式
+ *   - 条件表达式由 service/expression 编译求值，保存时检查语法
  * @dependencies:
  *   - time
  *   - encoding/json
  *   - fmt
+ *   - service/expression
  * @refs:
  *   - service/models/workflow.go
  *   - service/models/node.go
@@ -20,12 +22,21 @@
 package models
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 	"time"
+
+	"flow-service/service/expression"
 )
 
+// ErrEdgeDataFiltered 边数据被过滤规则拦截
+var ErrEdgeDataFiltered = errors.New("data filtered out by filter rules")
+
 // EdgeTypeEnum 边类型枚举
 type EdgeTypeEnum string
 
@@ -92,22 +103,28 @@ type EdgeCondition struct {
 	// 条件表达式
 	Expression string `json:"expression" validate:"required"`
 
-	// 条件类型
-	Type string `json:"type" validate:"oneof=javascript python go simple"`
+	// 条件类型，为空时按 expression 处理
+	Type string `json:"type" validate:"omitempty,oneof=expression simple"`
 
-	// 条件参数
+	// 条件参数，表达式中通过 params.<name> 或直接以参数名引用
 	Parameters map[string]interface{} `json:"parameters,omitempty"`
 
-	// 默认值
+	// 默认值，条件未启用或求值失败时使用
 	DefaultValue bool `json:"default_value"`
 
-	// 超时时间 (单位: 纳秒)
+	// 求值超时时间，0 表示不限 (单位: 纳秒)
 	Timeout time.Duration `json:"timeout" swaggertype:"integer" validate:"min=0"`
 
 	// 是否启用
 	Enabled bool `json:"enabled"`
 }
 
+// 边条件类型，simple 为旧版简单表达式，与 expression 使用同一表达式语言求值
+const (
+	EdgeConditionTypeExpression = "expression"
+	EdgeConditionTypeSimple     = "simple"
+)
+
 // EdgeDataMapping 边数据映射配置
 type EdgeDataMapping struct {
 	// 源字段映射
@@ -242,6 +259,10 @@ type Edge struct {
 	FromNodeID string `json:"from_node_id" validate:"required"`
 	ToNodeID   string `json:"to_node_id" validate:"required"`
 
+	// 端口字段，为空时使用节点插件声明的第一个输出/输入端口
+	FromPort string `json:"from_port,omitempty"`
+	ToPort   string `json:"to_port,omitempty"`
+
 	// 类型和状态
 	Type   EdgeTypeEnum   `json:"type" validate:"required"`
 	Status EdgeStatusEnum `json:"status"`
@@ -312,6 +333,11 @@ func (e *Edge) IsConditional() bool {
 	return e.Type == EdgeTypeConditional
 }
 
+// IsFailureHandler 检查是否为错误/超时处理边
+func (e *Edge) IsFailureHandler() bool {
+	return e.Type == EdgeTypeError || e.Type == EdgeTypeTimeout
+}
+
 // IsEnabled 检查边是否启用
 func (e *Edge) IsEnabled() bool {
 	if e.Status == EdgeStatusDisabled {
@@ -349,8 +375,8 @@ func (e *Edge) GetDelay() time.Duration {
 	return 0 // 默认无延迟
 }
 
-// EvaluateCondition 评估条件（简化版本，实际应该调用表达式引擎）
-func (e *Edge) EvaluateCondition(context map[string]interface{}) (bool, error) {
+// EvaluateCondition 使用条件表达式语言评估条件，求值失败时返回默认值和错误
+func (e *Edge) EvaluateCondition(variables map[string]interface{}) (bool, error) {
 	if !e.IsConditional() {
 		return true, nil // 非条件边总是返回true
 	}
@@ -363,73 +389,51 @@ func (e *Edge) EvaluateCondition(context map[string]interface{}) (bool, error) {
 	if !condition.Enabled {
 		return condition.DefaultValue, nil
 	}
-
-	// 简单的条件评估实现
-	expr := condition.Expression
-	switch condition.Type {
-	case "simple":
-		return e.evaluateSimpleCondition(expr, context)
-	default:
-		return condition.DefaultValue, nil
+	if err := condition.Check(); err != nil {
+		return condition.DefaultValue, err
 	}
-}
 
-// evaluateSimpleCondition 评估简单条件
-func (e *Edge) evaluateSimpleCondition(expr string, context map[string]interface{}) (bool, error) {
-	// 简单的条件解析：支持 "value > 10" 格式
-	if expr == "true" {
-		return true, nil
-	}
-	if expr == "false" {
-		return false, nil
+	ctx := context.Background()
+	if condition.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, condition.Timeout)
+		defer cancel()
 	}
 
-	// 解析 "value > 10" 格式
-	parts := strings.Fields(expr)
-	if len(parts) != 3 {
-		return false, fmt.Errorf("invalid expression format: %s", expr)
+	matched, err := expression.EvaluateBool(ctx, condition.Expression, condition.environment(variables))
+	if err != nil {
+		return condition.DefaultValue, fmt.Errorf("failed to evaluate condition %q: %w", condition.Expression, err)
 	}
+	return matched, nil
+}
 
-	field := parts[0]
-	operator := parts[1]
-	valueStr := parts[2]
-
-	contextValue, exists := context[field]
-	if !exists {
-		return false, nil
+// Check 检查条件类型和表达式语法
+func (c *EdgeCondition) Check() error {
+	switch c.Type {
+	case "", EdgeConditionTypeExpression, EdgeConditionTypeSimple:
+	default:
+		return fmt.Errorf("unsupported condition type: %s", c.Type)
 	}
+	return expression.Check(c.Expression)
+}
 
-	// 尝试转换为数字进行比较
-	contextNum, ok1 := contextValue.(int)
-	if !ok1 {
-		if f, ok2 := contextValue.(float64); ok2 {
-			contextNum = int(f)
-		} else {
-			return false, fmt.Errorf("cannot compare non-numeric value")
-		}
+// environment 构造求值环境：条件参数可通过 params 访问，未与变量重名的参数也可直接引用
+func (c *EdgeCondition) environment(variables map[string]interface{}) map[string]interface{} {
+	if len(c.Parameters) == 0 {
+		return variables
 	}
 
-	targetNum := 0
-	if _, err := fmt.Sscanf(valueStr, "%d", &targetNum); err != nil {
-		return false, fmt.Errorf("invalid target value: %s", valueStr)
-	}
-
-	switch operator {
-	case ">":
-		return contextNum > targetNum, nil
-	case ">=":
-		return contextNum >= targetNum, nil
-	case "<":
-		return contextNum < targetNum, nil
-	case "<=":
-		return contextNum <= targetNum, nil
-	case "==", "=":
-		return contextNum == targetNum, nil
-	case "!=":
-		return contextNum != targetNum, nil
-	default:
-		return false, fmt.Errorf("unsupported operator: %s", operator)
+	env := make(map[string]interface{}, len(variables)+len(c.Parameters)+1)
+	for k, v := range c.Parameters {
+		env[k] = v
+	}
+	for k, v := range variables {
+		env[k] = v
 	}
+	if _, exists := env["params"]; !exists {
+		env["params"] = c.Parameters
+	}
+	return env
 }
 
 // TransformData 转换数据
@@ -486,39 +490,210 @@ func (e *Edge) TransformData(inputData map[string]interface{}) (map[string]inter
 	// 应用过滤规则
 	if len(mapping.FilterRules) > 0 {
 		if !e.applyFilterRules(result, mapping.FilterRules) {
-			return nil, fmt.Errorf("data filtered out by filter rules")
+			return nil, ErrEdgeDataFiltered
 		}
 	}
 
 	return result, nil
 }
 
+// HasFieldMapping 是否配置了源/目标字段映射
+func (e *Edge) HasFieldMapping() bool {
+	if e.Config == nil || e.Config.DataMapping == nil {
+		return false
+	}
+	mapping := e.Config.DataMapping
+	return !mapping.PassThrough && (len(mapping.SourceMapping) > 0 || len(mapping.TargetMapping) > 0)
+}
+
 // applyTransformRule 应用转换规则
 func (e *Edge) applyTransformRule(data map[string]interface{}, rule TransformRule) error {
-	// 这里应该实现实际的转换逻辑
-	// 目前只是简单的示例
+	value, exists := data[rule.Field]
+	if !exists {
+		return nil
+	}
+
 	switch rule.Type {
 	case "format":
-		// 格式化转换
-		if value, exists := data[rule.Field]; exists {
-			data[rule.Field] = fmt.Sprintf(rule.Expression, value)
-		}
+		// 格式化转换，Expression 为 fmt 格式串
+		data[rule.Field] = fmt.Sprintf(rule.Expression, value)
 	case "convert":
-		// 类型转换
-		// 实现类型转换逻辑
+		// 类型转换，Expression 为目标类型
+		converted, err := convertValue(value, rule.Expression)
+		if err != nil {
+			return err
+		}
+		data[rule.Field] = converted
 	case "calculate":
-		// 计算转换
-		// 实现计算逻辑
+		// 计算转换，Expression 格式为 "<运算符> <数值>"，如 "* 100"
+		parts := strings.Fields(rule.Expression)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid calculate expression: %s", rule.Expression)
+		}
+		left, ok := toFloat64(value)
+		if !ok {
+			return fmt.Errorf("cannot calculate non-numeric value: %v", value)
+		}
+		right, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return fmt.Errorf("invalid calculate operand: %s", parts[1])
+		}
+		switch parts[0] {
+		case "+":
+			data[rule.Field] = left + right
+		case "-":
+			data[rule.Field] = left - right
+		case "*":
+			data[rule.Field] = left * right
+		case "/":
+			if right == 0 {
+				return fmt.Errorf("division by zero")
+			}
+			data[rule.Field] = left / right
+		default:
+			return fmt.Errorf("unsupported calculate operator: %s", parts[0])
+		}
+	default:
+		return fmt.Errorf("unsupported transform type: %s", rule.Type)
 	}
 
 	return nil
 }
 
-// applyFilterRules 应用过滤规则
+// applyFilterRules 应用过滤规则，按顺序以各规则的逻辑操作符与前一结果组合
 func (e *Edge) applyFilterRules(data map[string]interface{}, rules []FilterRule) bool {
-	// 这里应该实现实际的过滤逻辑
-	// 目前只是简单返回true
-	return true
+	result := true
+	for i, rule := range rules {
+		matched := matchFilterRule(data[rule.Field], rule)
+		if i == 0 {
+			result = matched
+			continue
+		}
+		if rule.Logic == "or" {
+			result = result || matched
+		} else {
+			result = result && matched
+		}
+	}
+	return result
+}
+
+// matchFilterRule 判断字段值是否满足过滤规则
+func matchFilterRule(value interface{}, rule FilterRule) bool {
+	switch rule.Operator {
+	case "eq":
+		return compareValues(value, rule.Value) == 0
+	case "ne":
+		return compareValues(value, rule.Value) != 0
+	case "gt":
+		return compareOrdered(value, rule.Value, func(c int) bool { return c > 0 })
+	case "lt":
+		return compareOrdered(value, rule.Value, func(c int) bool { return c < 0 })
+	case "ge":
+		return compareOrdered(value, rule.Value, func(c int) bool { return c >= 0 })
+	case "le":
+		return compareOrdered(value, rule.Value, func(c int) bool { return c <= 0 })
+	case "in", "nin":
+		found := false
+		if list, ok := rule.Value.([]interface{}); ok {
+			for _, item := range list {
+				if compareValues(value, item) == 0 {
+					found = true
+					break
+				}
+			}
+		}
+		return found == (rule.Operator == "in")
+	case "contains":
+		if list, ok := value.([]interface{}); ok {
+			for _, item := range list {
+				if compareValues(item, rule.Value) == 0 {
+					return true
+				}
+			}
+			return false
+		}
+		return strings.Contains(fmt.Sprint(value), fmt.Sprint(rule.Value))
+	default:
+		return false
+	}
+}
+
+// compareOrdered 比较有序值，无法比较时返回false
+func compareOrdered(left, right interface{}, check func(int) bool) bool {
+	if left == nil || right == nil {
+		return false
+	}
+	return check(compareValues(left, right))
+}
+
+// compareValues 比较两个值，数值按浮点数比较，其余按字符串比较
+func compareValues(left, right interface{}) int {
+	if l, ok := toFloat64(left); ok {
+		if r, ok := toFloat64(right); ok {
+			switch {
+			case l < r:
+				return -1
+			case l > r:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+	if left == nil || right == nil {
+		if left == nil && right == nil {
+			return 0
+		}
+		return 1
+	}
+	return strings.Compare(fmt.Sprint(left), fmt.Sprint(right))
+}
+
+// toFloat64 将数值类型转换为float64
+func toFloat64(value interface{}) (float64, bool) {
+	if value == nil {
+		return 0, false
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// convertValue 将值转换为目标类型
+func convertValue(value interface{}, targetType string) (interface{}, error) {
+	switch targetType {
+	case "string":
+		return fmt.Sprint(value), nil
+	case "number", "float":
+		if f, ok := toFloat64(value); ok {
+			return f, nil
+		}
+		return strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(value)), 64)
+	case "integer", "int":
+		if f, ok := toFloat64(value); ok {
+			return int64(f), nil
+		}
+		return strconv.ParseInt(strings.TrimSpace(fmt.Sprint(value)), 10, 64)
+	case "boolean", "bool":
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		return strconv.ParseBool(strings.TrimSpace(fmt.Sprint(value)))
+	default:
+		return nil, fmt.Errorf("unsupported convert type: %s", targetType)
+	}
 }
 
 // RecordExecution 记录执行信息
@@ -557,6 +732,8 @@ func (e *Edge) Clone() *Edge {
 		Description: e.Description,
 		FromNodeID:  e.FromNodeID,
 		ToNodeID:    e.ToNodeID,
+		FromPort:    e.FromPort,
+		ToPort:      e.ToPort,
 		Type:        e.Type,
 		Status:      e.Status,
 		UIConfig:    e.UIConfig,
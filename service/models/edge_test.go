@@ -0,0 +1,189 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEdgeTransformData(t *testing.T) {
+	input := map[string]interface{}{
+		"id":     7,
+		"amount": 12.5,
+		"name":   "widget",
+		"count":  "42",
+		"active": "true",
+	}
+
+	tests := []struct {
+		name    string
+		mapping *EdgeDataMapping
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{name: "no mapping passes input", mapping: nil, want: input},
+		{name: "pass through", mapping: &EdgeDataMapping{PassThrough: true, SourceMapping: map[string]string{"x": "id"}}, want: input},
+		{
+			name:    "source mapping",
+			mapping: &EdgeDataMapping{SourceMapping: map[string]string{"order_id": "id", "missing": "nope"}},
+			want:    map[string]interface{}{"order_id": 7},
+		},
+		{
+			name: "target mapping renames mapped and input fields",
+			mapping: &EdgeDataMapping{
+				SourceMapping: map[string]string{"order_id": "id"},
+				TargetMapping: map[string]string{"order_id": "orderId", "name": "title"},
+			},
+			want: map[string]interface{}{"orderId": 7, "title": "widget"},
+		},
+		{
+			name: "format rule",
+			mapping: &EdgeDataMapping{TransformRules: []TransformRule{
+				{Field: "name", Type: "format", Expression: "item-%v"},
+			}},
+			want: map[string]interface{}{"id": 7, "amount": 12.5, "name": "item-widget", "count": "42", "active": "true"},
+		},
+		{
+			name: "convert rules",
+			mapping: &EdgeDataMapping{TransformRules: []TransformRule{
+				{Field: "count", Type: "convert", Expression: "integer"},
+				{Field: "active", Type: "convert", Expression: "boolean"},
+				{Field: "id", Type: "convert", Expression: "string"},
+			}},
+			want: map[string]interface{}{"id": "7", "amount": 12.5, "name": "widget", "count": int64(42), "active": true},
+		},
+		{
+			name: "calculate rule",
+			mapping: &EdgeDataMapping{TransformRules: []TransformRule{
+				{Field: "amount", Type: "calculate", Expression: "* 2"},
+			}},
+			want: map[string]interface{}{"id": 7, "amount": 25.0, "name": "widget", "count": "42", "active": "true"},
+		},
+		{
+			name: "rule for missing field is skipped",
+			mapping: &EdgeDataMapping{
+				SourceMapping:  map[string]string{"id": "id"},
+				TransformRules: []TransformRule{{Field: "absent", Type: "calculate", Expression: "+ 1"}},
+			},
+			want: map[string]interface{}{"id": 7},
+		},
+		{
+			name:    "calculate non numeric",
+			mapping: &EdgeDataMapping{TransformRules: []TransformRule{{Field: "name", Type: "calculate", Expression: "+ 1"}}},
+			wantErr: "cannot calculate non-numeric value",
+		},
+		{
+			name:    "calculate division by zero",
+			mapping: &EdgeDataMapping{TransformRules: []TransformRule{{Field: "amount", Type: "calculate", Expression: "/ 0"}}},
+			wantErr: "division by zero",
+		},
+		{
+			name:    "invalid calculate expression",
+			mapping: &EdgeDataMapping{TransformRules: []TransformRule{{Field: "amount", Type: "calculate", Expression: "*2"}}},
+			wantErr: "invalid calculate expression",
+		},
+		{
+			name:    "invalid conversion",
+			mapping: &EdgeDataMapping{TransformRules: []TransformRule{{Field: "name", Type: "convert", Expression: "number"}}},
+			wantErr: "field name",
+		},
+		{
+			name:    "unsupported transform type",
+			mapping: &EdgeDataMapping{TransformRules: []TransformRule{{Field: "name", Type: "script", Expression: "x"}}},
+			wantErr: "unsupported transform type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edge := &Edge{ID: "e1", Config: &EdgeConfig{DataMapping: tt.mapping}}
+			got, err := edge.TransformData(input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("TransformData() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TransformData() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TransformData() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEdgeFilterRules(t *testing.T) {
+	data := map[string]interface{}{
+		"status": "paid",
+		"amount": 150,
+		"tags":   []interface{}{"vip", "eu"},
+		"note":   "rush order",
+	}
+
+	tests := []struct {
+		name  string
+		rules []FilterRule
+		want  bool
+	}{
+		{name: "eq string", rules: []FilterRule{{Field: "status", Operator: "eq", Value: "paid"}}, want: true},
+		{name: "eq across numeric types", rules: []FilterRule{{Field: "amount", Operator: "eq", Value: 150.0}}, want: true},
+		{name: "ne", rules: []FilterRule{{Field: "status", Operator: "ne", Value: "paid"}}, want: false},
+		{name: "gt", rules: []FilterRule{{Field: "amount", Operator: "gt", Value: 100}}, want: true},
+		{name: "lt", rules: []FilterRule{{Field: "amount", Operator: "lt", Value: 100}}, want: false},
+		{name: "ge boundary", rules: []FilterRule{{Field: "amount", Operator: "ge", Value: 150}}, want: true},
+		{name: "le boundary", rules: []FilterRule{{Field: "amount", Operator: "le", Value: 150}}, want: true},
+		{name: "ordered comparison with missing field", rules: []FilterRule{{Field: "absent", Operator: "lt", Value: 1}}, want: false},
+		{name: "in", rules: []FilterRule{{Field: "status", Operator: "in", Value: []interface{}{"paid", "shipped"}}}, want: true},
+		{name: "nin", rules: []FilterRule{{Field: "status", Operator: "nin", Value: []interface{}{"paid"}}}, want: false},
+		{name: "contains list item", rules: []FilterRule{{Field: "tags", Operator: "contains", Value: "vip"}}, want: true},
+		{name: "contains substring", rules: []FilterRule{{Field: "note", Operator: "contains", Value: "rush"}}, want: true},
+		{name: "unknown operator", rules: []FilterRule{{Field: "status", Operator: "like", Value: "paid"}}, want: false},
+		{
+			name: "and logic",
+			rules: []FilterRule{
+				{Field: "status", Operator: "eq", Value: "paid"},
+				{Field: "amount", Operator: "gt", Value: 200, Logic: "and"},
+			},
+			want: false,
+		},
+		{
+			name: "or logic",
+			rules: []FilterRule{
+				{Field: "status", Operator: "eq", Value: "refunded"},
+				{Field: "amount", Operator: "gt", Value: 100, Logic: "or"},
+			},
+			want: true,
+		},
+		{
+			name: "rules combine left to right",
+			rules: []FilterRule{
+				{Field: "status", Operator: "eq", Value: "refunded"},
+				{Field: "amount", Operator: "gt", Value: 100, Logic: "or"},
+				{Field: "tags", Operator: "contains", Value: "us", Logic: "and"},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edge := &Edge{ID: "e1", Config: &EdgeConfig{DataMapping: &EdgeDataMapping{FilterRules: tt.rules}}}
+			got, err := edge.TransformData(data)
+			if tt.want {
+				if err != nil {
+					t.Fatalf("TransformData() error = %v, want data to pass", err)
+				}
+				if !reflect.DeepEqual(got, data) {
+					t.Errorf("TransformData() = %#v, want %#v", got, data)
+				}
+				return
+			}
+			if !errors.Is(err, ErrEdgeDataFiltered) {
+				t.Fatalf("TransformData() error = %v, want ErrEdgeDataFiltered", err)
+			}
+		})
+	}
+}
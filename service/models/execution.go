@@ -12,8 +12,14 @@
 package models
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -24,18 +30,21 @@ type ExecutionStatus string
 
 const (
 	ExecutionStatusPending   ExecutionStatus = "pending"   // 等待执行
+	ExecutionStatusQueued    ExecutionStatus = "queued"    // 引擎已满，在准入队列中排队
 	ExecutionStatusRunning   ExecutionStatus = "running"   // 正在执行
+	ExecutionStatusPaused    ExecutionStatus = "paused"    // 已暂停
 	ExecutionStatusCompleted ExecutionStatus = "completed" // 执行完成
 	ExecutionStatusFailed    ExecutionStatus = "failed"    // 执行失败
 	ExecutionStatusCancelled ExecutionStatus = "cancelled" // 已取消
 	ExecutionStatusTimeout   ExecutionStatus = "timeout"   // 执行超时
 	ExecutionStatusArchived  ExecutionStatus = "archived"  // 已归档
+	ExecutionStatusSkipped   ExecutionStatus = "skipped"   // 入边全部被剪枝而跳过，仅用于节点执行记录
 )
 
 // IsValid 验证执行状态是否有效
 func (s ExecutionStatus) IsValid() bool {
 	switch s {
-	case ExecutionStatusPending, ExecutionStatusRunning, ExecutionStatusCompleted,
+	case ExecutionStatusPending, ExecutionStatusQueued, ExecutionStatusRunning, ExecutionStatusPaused, ExecutionStatusCompleted,
 		ExecutionStatusFailed, ExecutionStatusCancelled, ExecutionStatusTimeout, ExecutionStatusArchived:
 		return true
 	default:
@@ -57,6 +66,7 @@ const (
 	TriggerTypeManual   TriggerType = "manual"   // 手动触发
 	TriggerTypeAPI      TriggerType = "api"      // API触发
 	TriggerTypeEvent    TriggerType = "event"    // 事件触发
+	TriggerTypeWorkflow TriggerType = "workflow" // 父工作流的子流程节点触发
 )
 
 // ExecutionContext 执行上下文
@@ -81,12 +91,15 @@ type ExecutionNodeRecord struct {
 	Status     ExecutionStatus        `json:"status"`
 	StartTime  *time.Time             `json:"start_time,omitempty"`
 	EndTime    *time.Time             `json:"end_time,omitempty"`
+	PausedAt   *time.Time             `json:"paused_at,omitempty"`  // 节点就绪后因执行暂停而等待的开始时间
+	ResumedAt  *time.Time             `json:"resumed_at,omitempty"` // 执行恢复、节点得以派发的时间
 	Duration   time.Duration          `json:"duration" swaggertype:"integer"`
 	RetryCount int                    `json:"retry_count"`
 	Input      map[string]interface{} `json:"input,omitempty"`
 	Output     map[string]interface{} `json:"output,omitempty"`
 	ErrorMsg   string                 `json:"error_msg,omitempty"`
 	Logs       []string               `json:"logs,omitempty"`
+	Metrics    map[string]interface{} `json:"metrics,omitempty"`
 }
 
 // ExecutionMetrics 执行指标
@@ -100,6 +113,88 @@ type ExecutionMetrics struct {
 	WaitTime       time.Duration `json:"wait_time" swaggertype:"integer"`
 }
 
+// ExecutionCheckpoint 执行检查点，记录已结束节点的状态和输出，用于服务重启后恢复执行
+type ExecutionCheckpoint struct {
+	NodeStates   map[string]NodeStatusEnum         `json:"node_states"`
+	EdgeStates   map[string]string                 `json:"edge_states"`
+	NodeOutputs  map[string]map[string]interface{} `json:"node_outputs,omitempty"`
+	NodeFailures map[string]map[string]interface{} `json:"node_failures,omitempty"`
+	Variables    map[string]interface{}            `json:"variables,omitempty"`
+	WakeTimes    map[string]time.Time              `json:"wake_times,omitempty"` // 等待中节点的唤醒时间，重启后继续等待剩余时间
+	SavedAt      time.Time                         `json:"saved_at"`
+}
+
+// Invalidate 使指定节点的检查点失效，恢复执行时这些节点将重新执行
+func (c *ExecutionCheckpoint) Invalidate(nodeIDs map[string]bool, edges []*Edge) {
+	for nodeID := range nodeIDs {
+		delete(c.NodeStates, nodeID)
+		delete(c.NodeOutputs, nodeID)
+		delete(c.NodeFailures, nodeID)
+		delete(c.WakeTimes, nodeID)
+	}
+
+	// 失效节点的出边需要重新决议
+	for _, edge := range edges {
+		if nodeIDs[edge.FromNodeID] {
+			delete(c.EdgeStates, edge.ID)
+		}
+	}
+}
+
+// checkpointGzipPrefix 压缩检查点的数据前缀
+const checkpointGzipPrefix = "gzip:"
+
+// Encode 编码检查点，compress 为 true 时使用 gzip 压缩并以 base64 存储
+func (c *ExecutionCheckpoint) Encode(compress bool) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	if !compress {
+		return string(data), nil
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	return checkpointGzipPrefix + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodeExecutionCheckpoint 解码检查点，自动识别是否压缩
+func DecodeExecutionCheckpoint(data string) (*ExecutionCheckpoint, error) {
+	raw := []byte(data)
+	if strings.HasPrefix(data, checkpointGzipPrefix) {
+		compressed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(data, checkpointGzipPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkpoint encoding: %w", err)
+		}
+
+		reader, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkpoint compression: %w", err)
+		}
+		defer reader.Close()
+
+		if raw, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("failed to decompress checkpoint: %w", err)
+		}
+	}
+
+	var checkpoint ExecutionCheckpoint
+	if err := json.Unmarshal(raw, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal checkpoint: %w", err)
+	}
+
+	return &checkpoint, nil
+}
+
 // Execution 轻量化执行记录模型
 type Execution struct {
 	// 基础字段
@@ -118,6 +213,10 @@ type Execution struct {
 	TriggerData string                 `json:"-" gorm:"type:text;column:trigger_data"`
 	Trigger     map[string]interface{} `json:"trigger,omitempty" gorm:"-"`
 
+	// 父执行信息，由子流程节点启动的执行关联到父执行和父节点
+	ParentExecutionID string `json:"parent_execution_id,omitempty" gorm:"size:64;index"`
+	ParentNodeID      string `json:"parent_node_id,omitempty" gorm:"size:64"`
+
 	// 执行上下文
 	ContextData string            `json:"-" gorm:"type:text;column:context"`
 	Context     *ExecutionContext `json:"context,omitempty" gorm:"-"`
@@ -130,9 +229,28 @@ type Execution struct {
 	MetricsData string            `json:"-" gorm:"type:text;column:metrics"`
 	Metrics     *ExecutionMetrics `json:"metrics,omitempty" gorm:"-"`
 
+	// 执行超时，为0时使用工作流配置的超时时间
+	Timeout time.Duration `json:"timeout" swaggertype:"integer" gorm:"default:0"`
+
+	// 执行检查点，编码后存储；执行结束后保留，供从失败处重试和从节点重新运行，完整重试时清空
+	CheckpointData string `json:"-" gorm:"type:text;column:checkpoint"`
+
+	// 排队信息，QueueOrder 为同一优先级内的排队顺序，越小越靠前；
+	// QueuePinned 表示排队顺序由调整排队接口显式指定，准入时优先于按运行中执行数的公平策略
+	QueuedAt    *time.Time `json:"queued_at,omitempty"`
+	QueueOrder  float64    `json:"queue_order,omitempty" gorm:"default:0"`
+	QueuePinned bool       `json:"queue_pinned,omitempty" gorm:"default:false"`
+
+	// 逻辑日期和数据区间 [DataIntervalStart, DataIntervalEnd]，定时执行由调度器按调度周期设置，
+	// 手动执行由调用方指定，未指定时为创建时间；节点配置模板通过 ds、data_interval_start 等变量引用
+	LogicalDate       *time.Time `json:"logical_date,omitempty"`
+	DataIntervalStart *time.Time `json:"data_interval_start,omitempty"`
+	DataIntervalEnd   *time.Time `json:"data_interval_end,omitempty"`
+
 	// 时间信息
 	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
 	StartedAt   *time.Time `json:"started_at,omitempty"`
+	PausedAt    *time.Time `json:"paused_at,omitempty"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 
 	// 重试信息
@@ -312,14 +430,71 @@ func (e *Execution) Validate() error {
 		return errors.New("max_retries cannot be negative")
 	}
 
+	if e.Timeout < 0 {
+		return errors.New("timeout cannot be negative")
+	}
+
+	if e.DataIntervalStart != nil && e.DataIntervalEnd != nil && e.DataIntervalEnd.Before(*e.DataIntervalStart) {
+		return ErrInvalidDataInterval
+	}
+
 	return nil
 }
 
+// ErrInvalidDataInterval 数据区间结束时间早于开始时间
+var ErrInvalidDataInterval = errors.New("data_interval_end must not be before data_interval_start")
+
+// NormalizeDataInterval 补齐逻辑日期和数据区间：只指定区间一端时另一端与之相同，
+// 未指定逻辑日期时取区间开始，均未指定时取 now
+func (e *Execution) NormalizeDataInterval(now time.Time) error {
+	if e.DataIntervalStart == nil && e.DataIntervalEnd == nil {
+		if e.LogicalDate == nil {
+			e.LogicalDate = &now
+		}
+		start, end := *e.LogicalDate, *e.LogicalDate
+		e.DataIntervalStart, e.DataIntervalEnd = &start, &end
+	}
+	if e.DataIntervalStart == nil {
+		start := *e.DataIntervalEnd
+		e.DataIntervalStart = &start
+	}
+	if e.DataIntervalEnd == nil {
+		end := *e.DataIntervalStart
+		e.DataIntervalEnd = &end
+	}
+	if e.DataIntervalEnd.Before(*e.DataIntervalStart) {
+		return ErrInvalidDataInterval
+	}
+	if e.LogicalDate == nil {
+		logicalDate := *e.DataIntervalStart
+		e.LogicalDate = &logicalDate
+	}
+	return nil
+}
+
+// GetCheckpoint 获取执行检查点，未保存时返回nil
+func (e *Execution) GetCheckpoint() (*ExecutionCheckpoint, error) {
+	if e.CheckpointData == "" {
+		return nil, nil
+	}
+	return DecodeExecutionCheckpoint(e.CheckpointData)
+}
+
 // IsRunning 检查执行是否正在运行
 func (e *Execution) IsRunning() bool {
 	return e.Status == ExecutionStatusRunning
 }
 
+// IsPaused 检查执行是否已暂停
+func (e *Execution) IsPaused() bool {
+	return e.Status == ExecutionStatusPaused
+}
+
+// IsQueued 检查执行是否在准入队列中
+func (e *Execution) IsQueued() bool {
+	return e.Status == ExecutionStatusQueued
+}
+
 // IsFinished 检查执行是否已结束
 func (e *Execution) IsFinished() bool {
 	return e.Status.IsFinished()
@@ -332,13 +507,13 @@ func (e *Execution) IsSuccessful() bool {
 
 // CanRetry 检查是否可以重试
 func (e *Execution) CanRetry() bool {
-	return e.Status == ExecutionStatusFailed && e.RetryCount < e.MaxRetries
+	return (e.Status == ExecutionStatusFailed || e.Status == ExecutionStatusTimeout) && e.RetryCount < e.MaxRetries
 }
 
 // Start 开始执行
 func (e *Execution) Start() error {
-	if e.Status != ExecutionStatusPending {
-		return errors.New("execution is not in pending status")
+	if e.Status != ExecutionStatusPending && e.Status != ExecutionStatusQueued {
+		return errors.New("execution is not in pending or queued status")
 	}
 
 	e.Status = ExecutionStatusRunning
@@ -350,14 +525,74 @@ func (e *Execution) Start() error {
 		e.Metrics = &ExecutionMetrics{}
 	}
 
+	// 记录在准入队列中的等待时间
+	e.Metrics.QueueTime = 0
+	if e.QueuedAt != nil {
+		e.Metrics.QueueTime = now.Sub(*e.QueuedAt)
+		e.QueuedAt = nil
+	}
+
+	return nil
+}
+
+// Enqueue 进入准入队列等待执行
+func (e *Execution) Enqueue(order float64) error {
+	if e.Status != ExecutionStatusPending {
+		return errors.New("execution is not in pending status")
+	}
+
+	e.Status = ExecutionStatusQueued
+	now := time.Now()
+	e.QueuedAt = &now
+	e.QueueOrder = order
+	e.QueuePinned = false
+
+	return nil
+}
+
+// Pause 暂停执行
+func (e *Execution) Pause() error {
+	if e.Status != ExecutionStatusRunning {
+		return errors.New("execution is not running")
+	}
+
+	e.Status = ExecutionStatusPaused
+	now := time.Now()
+	e.PausedAt = &now
+
+	return nil
+}
+
+// Resume 恢复已暂停的执行，暂停时长计入等待时间
+func (e *Execution) Resume() error {
+	if e.Status != ExecutionStatusPaused {
+		return errors.New("execution is not paused")
+	}
+
+	e.Status = ExecutionStatusRunning
+	e.endPause()
+
 	return nil
 }
 
+// endPause 结束暂停并累计等待时间
+func (e *Execution) endPause() {
+	if e.PausedAt == nil {
+		return
+	}
+	if e.Metrics == nil {
+		e.Metrics = &ExecutionMetrics{}
+	}
+	e.Metrics.WaitTime += time.Since(*e.PausedAt)
+	e.PausedAt = nil
+}
+
 // Complete 完成执行
 func (e *Execution) Complete() error {
-	if e.Status != ExecutionStatusRunning {
+	if e.Status != ExecutionStatusRunning && e.Status != ExecutionStatusPaused {
 		return errors.New("execution is not running")
 	}
+	e.endPause()
 
 	e.Status = ExecutionStatusCompleted
 	now := time.Now()
@@ -373,9 +608,10 @@ func (e *Execution) Complete() error {
 
 // Fail 执行失败
 func (e *Execution) Fail(errorMsg string, errorCode string) error {
-	if e.Status != ExecutionStatusRunning {
+	if e.Status != ExecutionStatusRunning && e.Status != ExecutionStatusPaused {
 		return errors.New("execution is not running")
 	}
+	e.endPause()
 
 	e.Status = ExecutionStatusFailed
 	e.ErrorMsg = errorMsg
@@ -391,6 +627,27 @@ func (e *Execution) Fail(errorMsg string, errorCode string) error {
 	return nil
 }
 
+// MarkTimeout 执行超时
+func (e *Execution) MarkTimeout(errorMsg string, errorCode string) error {
+	if e.Status != ExecutionStatusRunning && e.Status != ExecutionStatusPaused {
+		return errors.New("execution is not running")
+	}
+	e.endPause()
+
+	e.Status = ExecutionStatusTimeout
+	e.ErrorMsg = errorMsg
+	e.ErrorCode = errorCode
+	now := time.Now()
+	e.CompletedAt = &now
+
+	// 更新执行时间
+	if e.StartedAt != nil {
+		e.Metrics.ExecutionTime = now.Sub(*e.StartedAt)
+	}
+
+	return nil
+}
+
 // Cancel 取消执行
 func (e *Execution) Cancel() error {
 	if e.IsFinished() {
@@ -421,6 +678,46 @@ func (e *Execution) Retry() error {
 	return nil
 }
 
+// Rerun 准备从检查点重新运行，只允许已完成、失败或超时的执行
+func (e *Execution) Rerun() error {
+	switch e.Status {
+	case ExecutionStatusCompleted, ExecutionStatusFailed, ExecutionStatusTimeout:
+	default:
+		return errors.New("only completed, failed or timed out executions can be rerun")
+	}
+
+	e.Status = ExecutionStatusPending
+	e.StartedAt = nil
+	e.CompletedAt = nil
+	e.ErrorMsg = ""
+	e.ErrorCode = ""
+	e.StackTrace = ""
+
+	return nil
+}
+
+// RemoveNodeRecords 删除指定节点的执行记录，包括循环体内带 [i] 后缀的迭代记录
+func (e *Execution) RemoveNodeRecords(nodeIDs map[string]bool) {
+	records := e.Nodes[:0]
+	for _, record := range e.Nodes {
+		nodeID := record.NodeID
+		if i := strings.IndexByte(nodeID, '['); i > 0 {
+			nodeID = nodeID[:i]
+		}
+		if !nodeIDs[nodeID] {
+			records = append(records, record)
+		}
+	}
+	e.Nodes = records
+}
+
+// ClearOutput 清空上次运行的执行输出
+func (e *Execution) ClearOutput() {
+	if e.Context != nil {
+		e.Context.Output = nil
+	}
+}
+
 // UpdateNodeExecution 更新节点执行状态
 func (e *Execution) UpdateNodeExecution(nodeID string, status ExecutionStatus, errorMsg string) {
 	if e.Nodes == nil {
@@ -462,13 +759,26 @@ func (e *Execution) UpdateNodeExecution(nodeID string, status ExecutionStatus, e
 	e.Nodes = append(e.Nodes, nodeExec)
 }
 
+// SetNodeRecord 写入节点执行记录，已存在同一节点的记录时覆盖
+func (e *Execution) SetNodeRecord(record *ExecutionNodeRecord) {
+	for i, node := range e.Nodes {
+		if node.NodeID == record.NodeID {
+			e.Nodes[i] = record
+			return
+		}
+	}
+
+	e.Nodes = append(e.Nodes, record)
+}
+
 // GetProgress 获取执行进度
 func (e *Execution) GetProgress() float64 {
 	if e.Metrics == nil || e.Metrics.TotalNodes == 0 {
 		return 0.0
 	}
 
-	return float64(e.Metrics.CompletedNodes) / float64(e.Metrics.TotalNodes) * 100.0
+	// 被跳过的节点视为已处理
+	return float64(e.Metrics.CompletedNodes+e.Metrics.SkippedNodes) / float64(e.Metrics.TotalNodes) * 100.0
 }
 
 // GetDuration 获取执行时长
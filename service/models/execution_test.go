@@ -0,0 +1,142 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecutionCheckpointEncodeDecode(t *testing.T) {
+	checkpoint := &ExecutionCheckpoint{
+		NodeStates: map[string]NodeStatusEnum{
+			"extract": NodeStatusCompleted,
+			"load":    NodeStatusFailed,
+		},
+		EdgeStates: map[string]string{"e1": "satisfied"},
+		NodeOutputs: map[string]map[string]interface{}{
+			"extract": {"data": []interface{}{map[string]interface{}{"id": 1.0}}},
+		},
+		NodeFailures: map[string]map[string]interface{}{
+			"load": {"error": "connection refused"},
+		},
+		Variables: map[string]interface{}{"region": "eu", "limit": 10.0},
+		WakeTimes: map[string]time.Time{"wait": time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		SavedAt:   time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name       string
+		compress   bool
+		wantPrefix bool
+	}{
+		{name: "plain json", compress: false, wantPrefix: false},
+		{name: "gzip", compress: true, wantPrefix: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := checkpoint.Encode(tt.compress)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if got := strings.HasPrefix(data, checkpointGzipPrefix); got != tt.wantPrefix {
+				t.Errorf("encoded data has gzip prefix = %v, want %v", got, tt.wantPrefix)
+			}
+
+			decoded, err := DecodeExecutionCheckpoint(data)
+			if err != nil {
+				t.Fatalf("DecodeExecutionCheckpoint() error = %v", err)
+			}
+			if !reflect.DeepEqual(decoded, checkpoint) {
+				t.Errorf("decoded checkpoint = %+v, want %+v", decoded, checkpoint)
+			}
+		})
+	}
+}
+
+func TestDecodeExecutionCheckpointErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "invalid json", data: "{", wantErr: "failed to unmarshal checkpoint"},
+		{name: "invalid base64", data: checkpointGzipPrefix + "!!!", wantErr: "invalid checkpoint encoding"},
+		{name: "not gzip", data: checkpointGzipPrefix + "aGVsbG8=", wantErr: "invalid checkpoint compression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeExecutionCheckpoint(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("DecodeExecutionCheckpoint() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecutionGetCheckpoint(t *testing.T) {
+	execution := &Execution{}
+	checkpoint, err := execution.GetCheckpoint()
+	if err != nil || checkpoint != nil {
+		t.Fatalf("GetCheckpoint() without data = %v, %v, want nil, nil", checkpoint, err)
+	}
+
+	execution.CheckpointData = `{"node_states":{"a":"completed"},"edge_states":{}}`
+	checkpoint, err = execution.GetCheckpoint()
+	if err != nil {
+		t.Fatalf("GetCheckpoint() error = %v", err)
+	}
+	if checkpoint.NodeStates["a"] != NodeStatusCompleted {
+		t.Errorf("NodeStates[a] = %q, want %q", checkpoint.NodeStates["a"], NodeStatusCompleted)
+	}
+}
+
+func TestExecutionCheckpointInvalidate(t *testing.T) {
+	checkpoint := &ExecutionCheckpoint{
+		NodeStates:   map[string]NodeStatusEnum{"a": NodeStatusCompleted, "b": NodeStatusCompleted, "c": NodeStatusFailed},
+		EdgeStates:   map[string]string{"a-b": "satisfied", "b-c": "satisfied"},
+		NodeOutputs:  map[string]map[string]interface{}{"a": {"out": 1.0}, "b": {"out": 2.0}},
+		NodeFailures: map[string]map[string]interface{}{"c": {"error": "boom"}},
+		WakeTimes:    map[string]time.Time{"b": time.Now()},
+	}
+	edges := []*Edge{
+		{ID: "a-b", FromNodeID: "a", ToNodeID: "b"},
+		{ID: "b-c", FromNodeID: "b", ToNodeID: "c"},
+	}
+
+	checkpoint.Invalidate(map[string]bool{"b": true, "c": true}, edges)
+
+	if want := map[string]NodeStatusEnum{"a": NodeStatusCompleted}; !reflect.DeepEqual(checkpoint.NodeStates, want) {
+		t.Errorf("NodeStates = %v, want %v", checkpoint.NodeStates, want)
+	}
+	if want := map[string]string{"a-b": "satisfied"}; !reflect.DeepEqual(checkpoint.EdgeStates, want) {
+		t.Errorf("EdgeStates = %v, want %v", checkpoint.EdgeStates, want)
+	}
+	if _, exists := checkpoint.NodeOutputs["b"]; exists {
+		t.Error("NodeOutputs still contains invalidated node b")
+	}
+	if len(checkpoint.NodeFailures) != 0 || len(checkpoint.WakeTimes) != 0 {
+		t.Errorf("failures = %v, wake times = %v, want both empty", checkpoint.NodeFailures, checkpoint.WakeTimes)
+	}
+}
+
+func TestExecutionRerunAllowedStatuses(t *testing.T) {
+	for _, status := range []ExecutionStatus{
+		ExecutionStatusCompleted, ExecutionStatusFailed, ExecutionStatusTimeout,
+		ExecutionStatusPending, ExecutionStatusRunning, ExecutionStatusCancelled,
+	} {
+		execution := &Execution{Status: status, ErrorMsg: "previous error"}
+		err := execution.Rerun()
+
+		allowed := status.IsFinished() && status != ExecutionStatusCancelled
+		if allowed != (err == nil) {
+			t.Errorf("Rerun() from %s error = %v, want allowed = %v", status, err, allowed)
+			continue
+		}
+		if allowed && (execution.Status != ExecutionStatusPending || execution.ErrorMsg != "") {
+			t.Errorf("Rerun() from %s left status %s, error %q", status, execution.Status, execution.ErrorMsg)
+		}
+	}
+}
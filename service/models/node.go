@@ -23,6 +23,8 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 )
 
@@ -123,6 +125,8 @@ const (
 	NodeTypeAPI NodeTypeEnum = "api"
 	// NodeTypeTimer 定时器节点
 	NodeTypeTimer NodeTypeEnum = "timer"
+	// NodeTypeDelay 延迟节点
+	NodeTypeDelay NodeTypeEnum = "delay"
 )
 
 // String 返回类型字符串
@@ -135,7 +139,7 @@ func (t NodeTypeEnum) IsValid() bool {
 	switch t {
 	case NodeTypeDataSource, NodeTypeTransform, NodeTypeOutput, NodeTypeControl,
 		NodeTypeCondition, NodeTypeLoop, NodeTypeSubDAG, NodeTypeScript,
-		NodeTypeAPI, NodeTypeTimer:
+		NodeTypeAPI, NodeTypeTimer, NodeTypeDelay:
 		return true
 	default:
 		return false
@@ -229,6 +233,68 @@ type NodeRetryConfig struct {
 	StopConditions []string `json:"stop_conditions,omitempty"`
 }
 
+// GetRetryDelay 根据退避策略计算第 attempt 次重试前的等待时间（attempt 从1开始）
+func (c *NodeRetryConfig) GetRetryDelay(attempt int) time.Duration {
+	if c == nil || c.RetryInterval <= 0 || attempt < 1 {
+		return 0
+	}
+
+	// 在浮点数范围内计算并限制上限，避免超出 int64 的值转换为 Duration 时结果未定义
+	interval := float64(c.RetryInterval)
+	var delay float64
+	switch c.BackoffStrategy {
+	case "linear":
+		delay = interval * float64(attempt)
+	case "exponential":
+		multiplier := c.BackoffMultiplier
+		if multiplier < 1 {
+			multiplier = 2 // 默认倍数
+		}
+		delay = interval * math.Pow(multiplier, float64(attempt-1))
+	default: // fixed
+		delay = interval
+	}
+
+	limit := time.Duration(math.MaxInt64)
+	if c.MaxRetryInterval > 0 {
+		limit = c.MaxRetryInterval
+	}
+	if delay >= float64(limit) {
+		return limit
+	}
+
+	return time.Duration(delay)
+}
+
+// ShouldRetry 根据重试条件和停止条件判断错误是否需要重试，条件按子串匹配错误信息（忽略大小写）
+func (c *NodeRetryConfig) ShouldRetry(errMsg string) bool {
+	if c == nil {
+		return false
+	}
+
+	msg := strings.ToLower(errMsg)
+
+	// 命中停止条件时不再重试
+	for _, condition := range c.StopConditions {
+		if condition != "" && strings.Contains(msg, strings.ToLower(condition)) {
+			return false
+		}
+	}
+
+	// 未配置重试条件时，所有错误均重试
+	if len(c.RetryConditions) == 0 {
+		return true
+	}
+
+	for _, condition := range c.RetryConditions {
+		if condition != "" && strings.Contains(msg, strings.ToLower(condition)) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // TimeoutConfig 超时配置
 type TimeoutConfig struct {
 	// 执行超时 (单位: 纳秒)
@@ -258,8 +324,12 @@ type ResourceConfig struct {
 	// 网络限制
 	NetworkLimit string `json:"network_limit,omitempty"`
 
-	// 并发限制
-	ConcurrencyLimit int `json:"concurrency_limit" validate:"min=1"`
+	// 并发池名称，节点执行前需获取该池的槽位，池在引擎配置中定义
+	ConcurrencyPool string `json:"concurrency_pool,omitempty"`
+
+	// 并发限制：声明了未在引擎配置中定义的并发池时作为该池的上限，
+	// 未声明并发池时限制该节点跨所有执行同时运行的数量，0表示不限制
+	ConcurrencyLimit int `json:"concurrency_limit" validate:"min=0"`
 }
 
 // ConditionConfig 条件配置
@@ -278,6 +348,18 @@ type ConditionConfig struct {
 
 	// 默认分支
 	DefaultBranch string `json:"default_branch,omitempty"`
+
+	// 多路分支，按顺序匹配第一个条件成立的分支
+	Cases []ConditionCase `json:"cases,omitempty"`
+}
+
+// ConditionCase 多路分支条件
+type ConditionCase struct {
+	// 分支名，对应出边的源端口或目标节点ID
+	Branch string `json:"branch" validate:"required"`
+
+	// 条件表达式
+	Expression string `json:"expression" validate:"required"`
 }
 
 // LoopConfig 循环配置
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNodeRetryConfigGetRetryDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *NodeRetryConfig
+		attempt int
+		want    time.Duration
+	}{
+		{name: "nil config", config: nil, attempt: 1, want: 0},
+		{name: "no interval", config: &NodeRetryConfig{BackoffStrategy: "fixed"}, attempt: 1, want: 0},
+		{name: "attempt before first", config: &NodeRetryConfig{RetryInterval: time.Second}, attempt: 0, want: 0},
+		{name: "fixed", config: &NodeRetryConfig{RetryInterval: time.Second, BackoffStrategy: "fixed"}, attempt: 3, want: time.Second},
+		{name: "unknown strategy is fixed", config: &NodeRetryConfig{RetryInterval: time.Second, BackoffStrategy: "random"}, attempt: 3, want: time.Second},
+		{name: "linear", config: &NodeRetryConfig{RetryInterval: time.Second, BackoffStrategy: "linear"}, attempt: 3, want: 3 * time.Second},
+		{
+			name:    "exponential first attempt",
+			config:  &NodeRetryConfig{RetryInterval: time.Second, BackoffStrategy: "exponential", BackoffMultiplier: 3},
+			attempt: 1,
+			want:    time.Second,
+		},
+		{
+			name:    "exponential",
+			config:  &NodeRetryConfig{RetryInterval: time.Second, BackoffStrategy: "exponential", BackoffMultiplier: 3},
+			attempt: 3,
+			want:    9 * time.Second,
+		},
+		{
+			name:    "exponential default multiplier",
+			config:  &NodeRetryConfig{RetryInterval: time.Second, BackoffStrategy: "exponential"},
+			attempt: 4,
+			want:    8 * time.Second,
+		},
+		{
+			name:    "capped by max interval",
+			config:  &NodeRetryConfig{RetryInterval: time.Second, BackoffStrategy: "linear", MaxRetryInterval: 5 * time.Second},
+			attempt: 10,
+			want:    5 * time.Second,
+		},
+		{
+			name:    "exponential overflow capped",
+			config:  &NodeRetryConfig{RetryInterval: time.Hour, BackoffStrategy: "exponential", BackoffMultiplier: 10, MaxRetryInterval: time.Minute},
+			attempt: 40,
+			want:    time.Minute,
+		},
+		{
+			name:    "exponential overflow without max interval",
+			config:  &NodeRetryConfig{RetryInterval: time.Hour, BackoffStrategy: "exponential", BackoffMultiplier: 10},
+			attempt: 40,
+			want:    time.Duration(math.MaxInt64),
+		},
+		{
+			name:    "linear overflow without max interval",
+			config:  &NodeRetryConfig{RetryInterval: time.Duration(math.MaxInt64 / 2), BackoffStrategy: "linear"},
+			attempt: 3,
+			want:    time.Duration(math.MaxInt64),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.GetRetryDelay(tt.attempt); got != tt.want {
+				t.Errorf("GetRetryDelay(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNodeRetryConfigShouldRetry(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *NodeRetryConfig
+		errMsg string
+		want   bool
+	}{
+		{name: "nil config", config: nil, errMsg: "timeout", want: false},
+		{name: "no conditions retries everything", config: &NodeRetryConfig{}, errMsg: "anything", want: true},
+		{name: "retry condition matches", config: &NodeRetryConfig{RetryConditions: []string{"timeout"}}, errMsg: "request timeout after 5s", want: true},
+		{name: "retry condition ignores case", config: &NodeRetryConfig{RetryConditions: []string{"Timeout"}}, errMsg: "TIMEOUT", want: true},
+		{name: "retry condition misses", config: &NodeRetryConfig{RetryConditions: []string{"timeout"}}, errMsg: "permission denied", want: false},
+		{name: "empty retry condition ignored", config: &NodeRetryConfig{RetryConditions: []string{""}}, errMsg: "permission denied", want: false},
+		{name: "stop condition wins", config: &NodeRetryConfig{RetryConditions: []string{"error"}, StopConditions: []string{"invalid"}}, errMsg: "invalid input error", want: false},
+		{name: "stop condition without retry conditions", config: &NodeRetryConfig{StopConditions: []string{"denied"}}, errMsg: "access DENIED", want: false},
+		{name: "stop condition misses", config: &NodeRetryConfig{StopConditions: []string{"denied"}}, errMsg: "connection reset", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.ShouldRetry(tt.errMsg); got != tt.want {
+				t.Errorf("ShouldRetry(%q) = %v, want %v", tt.errMsg, got, tt.want)
+			}
+		})
+	}
+}
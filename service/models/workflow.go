@@ -126,6 +126,14 @@ type Workflow struct {
 	Nodes     map[string]*Node `json:"nodes,omitempty" gorm:"-"`
 	Edges     []*Edge          `json:"edges,omitempty" gorm:"-"`
 
+	// 输入参数定义
+	ParametersData string               `json:"-" gorm:"type:text;column:parameters"`
+	Parameters     []*WorkflowParameter `json:"parameters,omitempty" gorm:"-"`
+
+	// 输出定义
+	OutputsData string            `json:"-" gorm:"type:text;column:outputs"`
+	Outputs     []*WorkflowOutput `json:"outputs,omitempty" gorm:"-"`
+
 	// 调度配置 (原 Task 功能)
 	ScheduleData string            `json:"-" gorm:"type:text;column:schedule"`
 	Schedule     *WorkflowSchedule `json:"schedule" gorm:"-"`
@@ -212,6 +220,24 @@ func (w *Workflow) serializeFields() error {
 		w.EdgesData = string(data)
 	}
 
+	// 序列化输入参数定义
+	if w.Parameters != nil {
+		data, err := json.Marshal(w.Parameters)
+		if err != nil {
+			return err
+		}
+		w.ParametersData = string(data)
+	}
+
+	// 序列化输出定义
+	if w.Outputs != nil {
+		data, err := json.Marshal(w.Outputs)
+		if err != nil {
+			return err
+		}
+		w.OutputsData = string(data)
+	}
+
 	// 序列化调度配置
 	if w.Schedule != nil {
 		data, err := json.Marshal(w.Schedule)
@@ -267,6 +293,20 @@ func (w *Workflow) deserializeFields() error {
 		}
 	}
 
+	// 反序列化输入参数定义
+	if w.ParametersData != "" {
+		if err := json.Unmarshal([]byte(w.ParametersData), &w.Parameters); err != nil {
+			return err
+		}
+	}
+
+	// 反序列化输出定义
+	if w.OutputsData != "" {
+		if err := json.Unmarshal([]byte(w.OutputsData), &w.Outputs); err != nil {
+			return err
+		}
+	}
+
 	// 反序列化调度配置
 	if w.ScheduleData != "" {
 		if err := json.Unmarshal([]byte(w.ScheduleData), &w.Schedule); err != nil {
@@ -308,7 +348,10 @@ func (w *Workflow) Validate() error {
 		return errors.New("invalid workflow status")
 	}
 
-	return nil
+	if err := w.validateParameters(); err != nil {
+		return err
+	}
+	return w.validateOutputs()
 }
 
 // ValidateForUpdate 验证工作流更新（不要求节点）
@@ -326,7 +369,10 @@ func (w *Workflow) ValidateForUpdate() error {
 	}
 
 	// 更新时不强制要求节点，允许部分更新
-	return nil
+	if err := w.validateParameters(); err != nil {
+		return err
+	}
+	return w.validateOutputs()
 }
 
 // IsActive 检查工作流是否处于活跃状态
@@ -339,6 +385,29 @@ func (w *Workflow) CanExecute() bool {
 	return w.Status == WorkflowStatusActive && w.Schedule != nil && w.Schedule.Enabled
 }
 
+// GetDownstreamNodes 获取指定节点经启用的边可达的所有下游节点（不含自身）
+func (w *Workflow) GetDownstreamNodes(nodeID string) []string {
+	visited := map[string]bool{nodeID: true}
+	queue := []string{nodeID}
+	var downstream []string
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, edge := range w.Edges {
+			if edge.FromNodeID != current || !edge.IsEnabled() || visited[edge.ToNodeID] {
+				continue
+			}
+			visited[edge.ToNodeID] = true
+			downstream = append(downstream, edge.ToNodeID)
+			queue = append(queue, edge.ToNodeID)
+		}
+	}
+
+	return downstream
+}
+
 // UpdateStatistics 更新统计信息
 func (w *Workflow) UpdateStatistics(execTime time.Duration, success bool) {
 	if w.Statistics == nil {
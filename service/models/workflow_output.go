@@ -0,0 +1,78 @@
+/**
+ * @module workflow_output
+ * @description 工作流输出定义，声明执行完成后返回给调用方的命名结果及其来源节点端口或表达式
+ * @architecture 数据模型层，输出定义随工作流以JSON存储，执行引擎在执行完成时求值并写入执行上下文的 Output
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow output_flow: node outputs + variables -> declared outputs -> execution.context.output
+ * @rules 每个输出只能来自节点端口或表达式之一；节点未执行或端口不存在时输出为 null；
+ *        未声明输出的工作流沿用出口节点输出作为执行结果
+ * @dependencies service/expression
+ * @refs service/models/workflow.go, service/workflow_engine.go, service/workflow_validator.go
+ */
+
+package models
+
+import (
+	"fmt"
+
+	"flow-service/service/expression"
+)
+
+// WorkflowOutput 工作流输出定义
+type WorkflowOutput struct {
+	// 输出名，即执行结果中的字段名
+	Name string `json:"name" validate:"required"`
+
+	// 来源节点ID
+	NodeID string `json:"node_id,omitempty"`
+
+	// 来源节点的输出端口，为空时取节点所有端口的输出
+	Port string `json:"port,omitempty"`
+
+	// 表达式，可引用输入参数、工作流变量和 nodes.<节点ID>.<端口>
+	Expression string `json:"expression,omitempty"`
+
+	// 描述
+	Description string `json:"description,omitempty"`
+}
+
+// Validate 校验输出定义，节点是否存在由工作流图校验检查
+func (o *WorkflowOutput) Validate() error {
+	if o.Name == "" {
+		return fmt.Errorf("output name is required")
+	}
+
+	switch {
+	case o.NodeID != "" && o.Expression != "":
+		return fmt.Errorf("output %s must specify either node_id or expression, not both", o.Name)
+	case o.NodeID == "" && o.Expression == "":
+		return fmt.Errorf("output %s must specify node_id or expression", o.Name)
+	case o.Port != "" && o.NodeID == "":
+		return fmt.Errorf("output %s specifies port without node_id", o.Name)
+	}
+
+	if o.Expression != "" {
+		if err := expression.Check(o.Expression); err != nil {
+			return fmt.Errorf("output %s has invalid expression: %w", o.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateOutputs 校验输出定义列表，输出名不能重复
+func (w *Workflow) validateOutputs() error {
+	names := make(map[string]bool, len(w.Outputs))
+	for i, output := range w.Outputs {
+		if output == nil {
+			return fmt.Errorf("output at index %d is empty", i)
+		}
+		if err := output.Validate(); err != nil {
+			return err
+		}
+		if names[output.Name] {
+			return fmt.Errorf("duplicate output name: %s", output.Name)
+		}
+		names[output.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+/**
+ * @module workflow_parameter
+ * @description 工作流输入参数定义，声明触发执行时输入的名称、类型、必填、默认值和可选值
+ * @architecture 数据模型层，参数定义随工作流以JSON存储，触发执行、定时执行和子流程调用共用同一套输入校验
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow input_flow: raw input -> defaults -> type check -> enum check -> resolved input
+ * @rules 未声明参数的工作流不校验输入；声明参数后拒绝未声明的输入字段；类型严格匹配，不做字符串到数值的隐式转换；
+ *        校验错误按字段返回，便于前端逐项定位
+ * @dependencies encoding/json, time
+ * @refs service/models/workflow.go, service/execution_service.go, api/controllers/workflow_controller.go
+ */
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 参数类型
+const (
+	ParameterTypeString   = "string"
+	ParameterTypeNumber   = "number"
+	ParameterTypeInteger  = "integer"
+	ParameterTypeBoolean  = "boolean"
+	ParameterTypeArray    = "array"
+	ParameterTypeObject   = "object"
+	ParameterTypeDate     = "date"     // YYYY-MM-DD 格式的字符串
+	ParameterTypeDateTime = "datetime" // RFC3339 格式的字符串
+)
+
+// 参数校验错误代码
+const (
+	ParameterErrorRequired    = "required"
+	ParameterErrorInvalidType = "invalid_type"
+	ParameterErrorNotInEnum   = "not_in_enum"
+	ParameterErrorUnknown     = "unknown_parameter"
+)
+
+// WorkflowParameter 工作流输入参数定义
+type WorkflowParameter struct {
+	// 参数名，即输入数据中的字段名
+	Name string `json:"name" validate:"required"`
+
+	// 参数类型
+	Type string `json:"type" validate:"oneof=string number integer boolean array object date datetime"`
+
+	// 是否必填，有默认值时未提供也可通过
+	Required bool `json:"required"`
+
+	// 默认值，未提供参数时使用，定时执行使用默认值
+	Default interface{} `json:"default,omitempty"`
+
+	// 可选值
+	Enum []interface{} `json:"enum,omitempty"`
+
+	// 描述，用于触发表单
+	Description string `json:"description,omitempty"`
+}
+
+// Validate 校验参数定义，默认值和可选值必须符合参数类型
+func (p *WorkflowParameter) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("parameter name is required")
+	}
+	if !isParameterType(p.Type) {
+		return fmt.Errorf("parameter %s has invalid type: %s", p.Name, p.Type)
+	}
+
+	for i, option := range p.Enum {
+		if _, err := p.convert(option); err != nil {
+			return fmt.Errorf("parameter %s enum[%d]: %w", p.Name, i, err)
+		}
+	}
+	if p.Default != nil {
+		value, err := p.convert(p.Default)
+		if err != nil {
+			return fmt.Errorf("parameter %s default: %w", p.Name, err)
+		}
+		if !p.allowed(value) {
+			return fmt.Errorf("parameter %s default is not one of enum values", p.Name)
+		}
+	}
+	return nil
+}
+
+// isParameterType 判断参数类型是否有效
+func isParameterType(parameterType string) bool {
+	switch parameterType {
+	case ParameterTypeString, ParameterTypeNumber, ParameterTypeInteger, ParameterTypeBoolean,
+		ParameterTypeArray, ParameterTypeObject, ParameterTypeDate, ParameterTypeDateTime:
+		return true
+	default:
+		return false
+	}
+}
+
+// convert 检查值是否符合参数类型，数值统一转换为 float64，整数转换为 int64
+func (p *WorkflowParameter) convert(value interface{}) (interface{}, error) {
+	switch p.Type {
+	case ParameterTypeString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case ParameterTypeNumber:
+		if number, ok := toFloat64(value); ok {
+			return number, nil
+		}
+	case ParameterTypeInteger:
+		if number, ok := toFloat64(value); ok {
+			if number != math.Trunc(number) {
+				return nil, fmt.Errorf("expects integer, got %v", number)
+			}
+			return int64(number), nil
+		}
+	case ParameterTypeBoolean:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case ParameterTypeArray:
+		if value != nil && reflect.ValueOf(value).Kind() == reflect.Slice {
+			return value, nil
+		}
+	case ParameterTypeObject:
+		if value != nil && reflect.ValueOf(value).Kind() == reflect.Map {
+			return value, nil
+		}
+	case ParameterTypeDate:
+		if s, ok := value.(string); ok {
+			if _, err := time.Parse(time.DateOnly, s); err != nil {
+				return nil, fmt.Errorf("expects date in YYYY-MM-DD format, got %q", s)
+			}
+			return s, nil
+		}
+	case ParameterTypeDateTime:
+		if s, ok := value.(string); ok {
+			if _, err := time.Parse(time.RFC3339, s); err != nil {
+				return nil, fmt.Errorf("expects RFC3339 datetime, got %q", s)
+			}
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("expects %s, got %s", p.Type, jsonTypeName(value))
+}
+
+// allowed 判断值是否在可选值中，未配置可选值时总是允许
+func (p *WorkflowParameter) allowed(value interface{}) bool {
+	if len(p.Enum) == 0 {
+		return true
+	}
+	for _, option := range p.Enum {
+		converted, err := p.convert(option)
+		if err == nil && reflect.DeepEqual(converted, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeName 返回值的 JSON 类型名，用于错误信息
+func jsonTypeName(value interface{}) string {
+	if value == nil {
+		return "null"
+	}
+	if _, ok := toFloat64(value); ok {
+		return "number"
+	}
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// ParameterError 输入参数的字段级错误
+type ParameterError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code" example:"required"`
+	Message string `json:"message"`
+}
+
+// InputValidationError 输入参数校验未通过，携带所有字段错误
+type InputValidationError struct {
+	Errors []*ParameterError `json:"errors"`
+}
+
+// Error 实现error接口，汇总所有字段错误
+func (e *InputValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", fieldErr.Field, fieldErr.Message))
+	}
+	return fmt.Sprintf("invalid input: %s", strings.Join(messages, "; "))
+}
+
+// validateParameters 校验参数定义列表，参数名不能重复
+func (w *Workflow) validateParameters() error {
+	names := make(map[string]bool, len(w.Parameters))
+	for i, parameter := range w.Parameters {
+		if parameter == nil {
+			return fmt.Errorf("parameter at index %d is empty", i)
+		}
+		if err := parameter.Validate(); err != nil {
+			return err
+		}
+		if names[parameter.Name] {
+			return fmt.Errorf("duplicate parameter name: %s", parameter.Name)
+		}
+		names[parameter.Name] = true
+	}
+	return nil
+}
+
+// ResolveInput 按参数定义校验输入并补齐默认值，返回校验后的输入；未声明参数时原样返回
+func (w *Workflow) ResolveInput(input map[string]interface{}) (map[string]interface{}, error) {
+	if len(w.Parameters) == 0 {
+		return input, nil
+	}
+
+	resolved := make(map[string]interface{}, len(w.Parameters))
+	validationErr := &InputValidationError{}
+	declared := make(map[string]bool, len(w.Parameters))
+
+	for _, parameter := range w.Parameters {
+		declared[parameter.Name] = true
+
+		value, exists := input[parameter.Name]
+		if !exists || value == nil {
+			switch {
+			case parameter.Default != nil:
+				value = parameter.Default
+			case parameter.Required:
+				validationErr.Errors = append(validationErr.Errors, &ParameterError{
+					Field:   parameter.Name,
+					Code:    ParameterErrorRequired,
+					Message: "parameter is required",
+				})
+				continue
+			default:
+				continue
+			}
+		}
+
+		converted, err := parameter.convert(value)
+		if err != nil {
+			validationErr.Errors = append(validationErr.Errors, &ParameterError{
+				Field:   parameter.Name,
+				Code:    ParameterErrorInvalidType,
+				Message: err.Error(),
+			})
+			continue
+		}
+		if !parameter.allowed(converted) {
+			validationErr.Errors = append(validationErr.Errors, &ParameterError{
+				Field:   parameter.Name,
+				Code:    ParameterErrorNotInEnum,
+				Message: fmt.Sprintf("value must be one of %s", formatEnum(parameter.Enum)),
+			})
+			continue
+		}
+		resolved[parameter.Name] = converted
+	}
+
+	unknown := make([]string, 0)
+	for key := range input {
+		if !declared[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		validationErr.Errors = append(validationErr.Errors, &ParameterError{
+			Field:   key,
+			Code:    ParameterErrorUnknown,
+			Message: "parameter is not declared by the workflow",
+		})
+	}
+
+	if len(validationErr.Errors) > 0 {
+		return nil, validationErr
+	}
+	return resolved, nil
+}
+
+// DeclaredInput 只保留参数定义中声明的输入字段，供子流程调用传递上游数据；未声明参数时原样返回
+func (w *Workflow) DeclaredInput(input map[string]interface{}) map[string]interface{} {
+	if len(w.Parameters) == 0 {
+		return input
+	}
+
+	declared := make(map[string]interface{}, len(w.Parameters))
+	for _, parameter := range w.Parameters {
+		if value, exists := input[parameter.Name]; exists {
+			declared[parameter.Name] = value
+		}
+	}
+	return declared
+}
+
+// formatEnum 格式化可选值列表，用于错误信息
+func formatEnum(enum []interface{}) string {
+	data, err := json.Marshal(enum)
+	if err != nil {
+		return fmt.Sprint(enum)
+	}
+	return string(data)
+}
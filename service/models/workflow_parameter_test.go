@@ -0,0 +1,169 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWorkflowResolveInput(t *testing.T) {
+	workflow := &Workflow{
+		Parameters: []*WorkflowParameter{
+			{Name: "region", Type: ParameterTypeString, Required: true, Enum: []interface{}{"eu", "us"}},
+			{Name: "limit", Type: ParameterTypeInteger, Default: 100},
+			{Name: "ratio", Type: ParameterTypeNumber},
+			{Name: "dry_run", Type: ParameterTypeBoolean, Default: false},
+			{Name: "ids", Type: ParameterTypeArray},
+			{Name: "options", Type: ParameterTypeObject},
+			{Name: "day", Type: ParameterTypeDate},
+			{Name: "at", Type: ParameterTypeDateTime},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		input      map[string]interface{}
+		want       map[string]interface{}
+		wantErrors []*ParameterError
+	}{
+		{
+			name:  "defaults applied",
+			input: map[string]interface{}{"region": "eu"},
+			want:  map[string]interface{}{"region": "eu", "limit": int64(100), "dry_run": false},
+		},
+		{
+			name:  "null uses default",
+			input: map[string]interface{}{"region": "us", "limit": nil},
+			want:  map[string]interface{}{"region": "us", "limit": int64(100), "dry_run": false},
+		},
+		{
+			name: "all types converted",
+			input: map[string]interface{}{
+				"region":  "us",
+				"limit":   25.0,
+				"ratio":   3,
+				"dry_run": true,
+				"ids":     []interface{}{1.0, 2.0},
+				"options": map[string]interface{}{"verbose": true},
+				"day":     "2024-05-01",
+				"at":      "2024-05-01T10:00:00Z",
+			},
+			want: map[string]interface{}{
+				"region":  "us",
+				"limit":   int64(25),
+				"ratio":   3.0,
+				"dry_run": true,
+				"ids":     []interface{}{1.0, 2.0},
+				"options": map[string]interface{}{"verbose": true},
+				"day":     "2024-05-01",
+				"at":      "2024-05-01T10:00:00Z",
+			},
+		},
+		{
+			name:  "missing required",
+			input: map[string]interface{}{},
+			wantErrors: []*ParameterError{
+				{Field: "region", Code: ParameterErrorRequired},
+			},
+		},
+		{
+			name:  "not in enum",
+			input: map[string]interface{}{"region": "apac"},
+			wantErrors: []*ParameterError{
+				{Field: "region", Code: ParameterErrorNotInEnum},
+			},
+		},
+		{
+			name: "invalid types",
+			input: map[string]interface{}{
+				"region":  "eu",
+				"limit":   1.5,
+				"ratio":   "high",
+				"dry_run": "yes",
+				"ids":     "1,2",
+				"options": []interface{}{},
+				"day":     "05/01/2024",
+				"at":      "2024-05-01",
+			},
+			wantErrors: []*ParameterError{
+				{Field: "limit", Code: ParameterErrorInvalidType},
+				{Field: "ratio", Code: ParameterErrorInvalidType},
+				{Field: "dry_run", Code: ParameterErrorInvalidType},
+				{Field: "ids", Code: ParameterErrorInvalidType},
+				{Field: "options", Code: ParameterErrorInvalidType},
+				{Field: "day", Code: ParameterErrorInvalidType},
+				{Field: "at", Code: ParameterErrorInvalidType},
+			},
+		},
+		{
+			name:  "unknown parameters sorted",
+			input: map[string]interface{}{"region": "eu", "zeta": 1, "alpha": 2},
+			wantErrors: []*ParameterError{
+				{Field: "alpha", Code: ParameterErrorUnknown},
+				{Field: "zeta", Code: ParameterErrorUnknown},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := workflow.ResolveInput(tt.input)
+			if tt.wantErrors != nil {
+				var validationErr *InputValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("ResolveInput() error = %v, want *InputValidationError", err)
+				}
+				if len(validationErr.Errors) != len(tt.wantErrors) {
+					t.Fatalf("ResolveInput() errors = %v, want %d errors", err, len(tt.wantErrors))
+				}
+				for i, want := range tt.wantErrors {
+					got := validationErr.Errors[i]
+					if got.Field != want.Field || got.Code != want.Code {
+						t.Errorf("errors[%d] = %s/%s, want %s/%s", i, got.Field, got.Code, want.Field, want.Code)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveInput() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveInput() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkflowResolveInputWithoutParameters(t *testing.T) {
+	input := map[string]interface{}{"anything": 1}
+	got, err := (&Workflow{}).ResolveInput(input)
+	if err != nil {
+		t.Fatalf("ResolveInput() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, input) {
+		t.Errorf("ResolveInput() = %v, want input unchanged", got)
+	}
+}
+
+func TestWorkflowParameterValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		parameter *WorkflowParameter
+		wantErr   bool
+	}{
+		{name: "valid", parameter: &WorkflowParameter{Name: "n", Type: ParameterTypeInteger, Default: 1, Enum: []interface{}{1, 2}}},
+		{name: "missing name", parameter: &WorkflowParameter{Type: ParameterTypeString}, wantErr: true},
+		{name: "invalid type", parameter: &WorkflowParameter{Name: "n", Type: "decimal"}, wantErr: true},
+		{name: "default of wrong type", parameter: &WorkflowParameter{Name: "n", Type: ParameterTypeBoolean, Default: "yes"}, wantErr: true},
+		{name: "default not in enum", parameter: &WorkflowParameter{Name: "n", Type: ParameterTypeString, Default: "c", Enum: []interface{}{"a", "b"}}, wantErr: true},
+		{name: "enum of wrong type", parameter: &WorkflowParameter{Name: "n", Type: ParameterTypeNumber, Enum: []interface{}{"a"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.parameter.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+/**
+ * @module node_config_template
+ * @description 节点插件配置模板渲染，执行节点前将配置中的 {{ 表达式 }} 替换为触发输入、工作流变量和上游节点输出
+ * @architecture 执行引擎在调用节点插件前渲染配置副本，模板表达式使用条件表达式语言和相同的求值环境；
+ *               字段的处理方式由插件配置模式的 Template 声明
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow render_flow: plugin_config -> env -> rendered_config -> plugin.Execute
+ * @rules 不修改工作流定义中的配置；SQL 字段的模板值绑定为参数追加到 BindTo 字段，语句中只出现 $n 占位符；
+ *        声明为 none 的字段原样传递
+ * @dependencies service/expression/template.go, service/nodes/interface.go
+ * @refs service/workflow_engine.go, service/workflow_validator.go, service/nodes/datasource/postgresql.go
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"flow-service/service/expression"
+	"flow-service/service/models"
+	"flow-service/service/nodes"
+)
+
+// renderNodeConfig 渲染节点插件配置中的模板，返回使用渲染后配置的节点副本，没有模板时返回原节点
+func (e *WorkflowEngine) renderNodeConfig(ctx context.Context, execCtx *ExecutionContext, node *models.Node) (*models.Node, error) {
+	if node.Config == nil || !containsTemplate(node.Config.PluginConfig) {
+		return node, nil
+	}
+
+	fields := make(map[string]nodes.ConfigField)
+	if plugin, err := e.nodeRegistry.Get(node.Plugin); err == nil {
+		if metadata := plugin.GetMetadata(); metadata != nil && metadata.ConfigSchema != nil {
+			for _, field := range metadata.ConfigSchema.Properties {
+				fields[field.Name] = field
+			}
+		}
+	}
+
+	execCtx.mu.RLock()
+	env := e.buildExpressionEnv(execCtx)
+	execCtx.mu.RUnlock()
+
+	rendered := make(map[string]interface{}, len(node.Config.PluginConfig))
+	var sqlFields []string
+	for key, value := range node.Config.PluginConfig {
+		switch fields[key].Template {
+		case nodes.TemplateNone:
+			rendered[key] = value
+		case nodes.TemplateSQL:
+			rendered[key] = value
+			sqlFields = append(sqlFields, key)
+		default:
+			renderedValue, err := renderTemplateValue(ctx, value, env)
+			if err != nil {
+				return nil, fmt.Errorf("config field %s: %w", key, err)
+			}
+			rendered[key] = renderedValue
+		}
+	}
+
+	// SQL 字段在参数字段渲染之后绑定，占位符编号接在已有参数之后
+	sort.Strings(sqlFields)
+	for _, key := range sqlFields {
+		if err := bindSQLTemplate(ctx, rendered, key, fields[key].BindTo, env); err != nil {
+			return nil, fmt.Errorf("config field %s: %w", key, err)
+		}
+	}
+
+	renderedNode := *node
+	renderedConfig := *node.Config
+	renderedConfig.PluginConfig = rendered
+	renderedNode.Config = &renderedConfig
+	return &renderedNode, nil
+}
+
+// bindSQLTemplate 将 SQL 字段中的模板表达式替换为 $n 占位符，表达式的值追加到参数字段
+func bindSQLTemplate(ctx context.Context, config map[string]interface{}, key, bindTo string, env map[string]interface{}) error {
+	source, ok := config[key].(string)
+	if !ok || !expression.IsTemplate(source) {
+		return nil
+	}
+	if bindTo == "" {
+		return fmt.Errorf("sql template has no parameter field to bind to")
+	}
+
+	var params []interface{}
+	switch existing := config[bindTo].(type) {
+	case nil:
+	case []interface{}:
+		params = append(params, existing...)
+	default:
+		return fmt.Errorf("parameter field %s must be an array, got %T", bindTo, existing)
+	}
+
+	template, err := expression.ParseTemplate(source)
+	if err != nil {
+		return err
+	}
+	statement, err := template.Bind(ctx, env, func(value interface{}) (string, error) {
+		params = append(params, value)
+		return fmt.Sprintf("$%d", len(params)), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	config[key] = statement
+	config[bindTo] = params
+	return nil
+}
+
+// renderTemplateValue 递归渲染配置值中的字符串模板
+func renderTemplateValue(ctx context.Context, value interface{}, env map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if !expression.IsTemplate(v) {
+			return v, nil
+		}
+		template, err := expression.ParseTemplate(v)
+		if err != nil {
+			return nil, err
+		}
+		return template.Render(ctx, env)
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			renderedItem, err := renderTemplateValue(ctx, item, env)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			rendered[key] = renderedItem
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := make([]interface{}, len(v))
+		for i, item := range v {
+			renderedItem, err := renderTemplateValue(ctx, item, env)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			rendered[i] = renderedItem
+		}
+		return rendered, nil
+	default:
+		return value, nil
+	}
+}
+
+// containsTemplate 判断配置值中是否包含模板
+func containsTemplate(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return expression.IsTemplate(v)
+	case map[string]interface{}:
+		for _, item := range v {
+			if containsTemplate(item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if containsTemplate(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// templateReferences 递归收集配置值中模板表达式引用的变量路径，语法错误的模板忽略
+func templateReferences(value interface{}) [][]string {
+	var refs [][]string
+	switch v := value.(type) {
+	case string:
+		if !expression.IsTemplate(v) {
+			return nil
+		}
+		if template, err := expression.ParseTemplate(v); err == nil {
+			refs = template.References()
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			refs = append(refs, templateReferences(item)...)
+		}
+	case []interface{}:
+		for _, item := range v {
+			refs = append(refs, templateReferences(item)...)
+		}
+	}
+	return refs
+}
+
+// checkTemplateValue 递归检查配置值中的模板语法，返回出错的字段路径
+func checkTemplateValue(path string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		if expression.IsTemplate(v) {
+			return path, expression.CheckTemplate(v)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			if itemPath, err := checkTemplateValue(path+"."+key, item); err != nil {
+				return itemPath, err
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if itemPath, err := checkTemplateValue(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return itemPath, err
+			}
+		}
+	}
+	return path, nil
+}
@@ -0,0 +1,80 @@
+/**
+ * @module node_context
+ * @description 节点执行的取消和读取限制辅助函数，供插件在读取外部数据和处理大批量数据时响应 ctx
+ * @architecture 插件工具模块，读取上限通过 ctx 传递，调用方（如单节点测试执行）无需了解插件实现即可限制内存占用
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow 无状态
+ * @rules 插件读取文件、HTTP 响应等外部数据时必须经过 ContextReader；逐条处理数据的循环定期调用 CheckCanceled
+ * @dependencies context, io
+ * @refs service/nodes/interface.go, api/controllers/node_controller.go
+ */
+
+package nodes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// cancelCheckInterval 逐条处理数据时检查 ctx 的间隔（条数）
+const cancelCheckInterval = 256
+
+// ErrReadLimitExceeded 读取的数据超过 ctx 设置的上限
+var ErrReadLimitExceeded = errors.New("data read exceeds limit")
+
+// readLimitKey ctx 中读取上限的键
+type readLimitKey struct{}
+
+// WithReadLimit 设置插件读取外部数据的字节上限
+func WithReadLimit(ctx context.Context, maxBytes int64) context.Context {
+	return context.WithValue(ctx, readLimitKey{}, maxBytes)
+}
+
+// ContextReader 包装数据读取：ctx 结束后读取返回 ctx 的错误，ctx 设置了读取上限时超出上限返回 ErrReadLimitExceeded
+func ContextReader(ctx context.Context, r io.Reader) io.Reader {
+	limit, _ := ctx.Value(readLimitKey{}).(int64)
+	return &contextReader{ctx: ctx, reader: r, limit: limit}
+}
+
+// contextReader 响应取消和读取上限的 Reader
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+	limit  int64 // 0 表示不限
+	read   int64
+}
+
+// Read 实现 io.Reader
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if r.limit > 0 {
+		if r.read >= r.limit {
+			// 恰好读到上限时再探测一个字节，区分数据结束和超出上限
+			var probe [1]byte
+			n, err := r.reader.Read(probe[:])
+			if n > 0 {
+				return 0, fmt.Errorf("%w of %d bytes", ErrReadLimitExceeded, r.limit)
+			}
+			return 0, err
+		}
+		if remaining := r.limit - r.read; int64(len(p)) > remaining {
+			p = p[:remaining]
+		}
+	}
+
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	return n, err
+}
+
+// CheckCanceled 每处理 cancelCheckInterval 条数据检查一次 ctx，ctx 结束时返回其错误
+func CheckCanceled(ctx context.Context, processed int) error {
+	if processed%cancelCheckInterval != 0 {
+		return nil
+	}
+	return ctx.Err()
+}
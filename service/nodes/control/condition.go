@@ -0,0 +1,196 @@
+/**
+ * @module condition_control
+ * @description 条件控制节点，根据输入数据判断条件并激活匹配的分支
+ * @architecture 控制节点插件，只提供元数据和配置校验，分支选择由执行引擎完成
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow condition_states: evaluating -> branch_selected -> completed
+ * @rules 出边以源端口或目标节点ID对应分支，未选中分支的出边被剪枝，其下游节点被跳过
+ * @dependencies context, time, service/expression
+ * @refs service/nodes/interface.go, service/control_executor.go
+ */
+
+package control
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"flow-service/service/expression"
+	"flow-service/service/nodes"
+)
+
+// init 自动注册条件节点
+func init() {
+	registry := nodes.GetRegistry()
+	if err := registry.Register(NewConditionNode()); err != nil {
+		log.Printf("注册条件节点失败: %v", err)
+	} else {
+		log.Println("条件节点注册成功")
+	}
+}
+
+// ConditionNode 条件控制节点
+type ConditionNode struct{}
+
+// NewConditionNode 创建条件节点
+func NewConditionNode() *ConditionNode {
+	return &ConditionNode{}
+}
+
+// GetMetadata 获取节点元数据
+func (c *ConditionNode) GetMetadata() *nodes.NodeMetadata {
+	return &nodes.NodeMetadata{
+		ID:          nodes.TypeCondition,
+		Name:        nodes.TypeConditionDisplayName,
+		Description: "根据输入数据判断条件，激活成立、不成立或多路分支中匹配的分支，未选中的分支被跳过",
+		Version:     "1.0.0",
+		Category:    nodes.CategoryControl,
+		Type:        nodes.TypeCondition,
+		Icon:        nodes.TypeConditionIcon,
+		Tags:        []string{"条件", "分支", "控制"},
+
+		InputPorts: []nodes.PortDefinition{
+			{
+				ID:          nodes.PortConditionInput,
+				Name:        "输入数据",
+				Description: "参与条件判断的数据，原样传给选中的分支",
+				DataType:    nodes.DataTypeAny,
+				Required:    false,
+				Multiple:    false,
+			},
+		},
+
+		OutputPorts: []nodes.PortDefinition{
+			{
+				ID:          nodes.PortConditionOutput,
+				Name:        "输出数据",
+				Description: "输入数据，连接到分支节点ID的出边使用该端口",
+				DataType:    nodes.DataTypeAny,
+				Required:    false,
+				Multiple:    false,
+			},
+			{
+				ID:          nodes.PortConditionTrue,
+				Name:        "成立",
+				Description: "条件成立时激活",
+				DataType:    nodes.DataTypeAny,
+				Required:    false,
+				Multiple:    false,
+			},
+			{
+				ID:          nodes.PortConditionFalse,
+				Name:        "不成立",
+				Description: "条件不成立时激活",
+				DataType:    nodes.DataTypeAny,
+				Required:    false,
+				Multiple:    false,
+			},
+			{
+				ID:          nodes.PortConditionDefault,
+				Name:        "默认",
+				Description: "多路分支均不匹配时激活",
+				DataType:    nodes.DataTypeAny,
+				Required:    false,
+				Multiple:    false,
+			},
+		},
+
+		ConfigSchema: &nodes.ConfigSchema{
+			Type: "object",
+			Properties: []nodes.ConfigField{
+				{
+					Name:        "expression",
+					Type:        "string",
+					Title:       "条件表达式",
+					Description: "可引用输入数据字段、执行变量和上游节点输出，如 count > 10 && node1.output.status == 'ok'",
+					Widget:      nodes.WidgetText,
+				},
+				{
+					Name:        "true_branch",
+					Type:        "string",
+					Title:       "成立分支",
+					Description: "条件成立时激活的分支名或目标节点ID",
+					Default:     nodes.PortConditionTrue,
+					Widget:      nodes.WidgetText,
+				},
+				{
+					Name:        "false_branch",
+					Type:        "string",
+					Title:       "不成立分支",
+					Description: "条件不成立时激活的分支名或目标节点ID",
+					Default:     nodes.PortConditionFalse,
+					Widget:      nodes.WidgetText,
+				},
+				{
+					Name:        "cases",
+					Type:        "array",
+					Title:       "多路分支",
+					Description: "按顺序匹配的分支列表，每项包含 branch 和 expression，配置后忽略条件表达式",
+					Widget:      nodes.WidgetJSON,
+				},
+				{
+					Name:        "default_branch",
+					Type:        "string",
+					Title:       "默认分支",
+					Description: "多路分支均不匹配时激活的分支名或目标节点ID",
+					Default:     nodes.PortConditionDefault,
+					Widget:      nodes.WidgetText,
+				},
+			},
+		},
+
+		Author:    "Flow Service Team",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Validate 验证节点配置
+func (c *ConditionNode) Validate(config map[string]interface{}) error {
+	cases, hasCases := config["cases"]
+	if !hasCases || cases == nil {
+		source, _ := config["expression"].(string)
+		if source == "" {
+			return fmt.Errorf("expression or cases is required")
+		}
+		if err := expression.Check(source); err != nil {
+			return fmt.Errorf("invalid expression: %w", err)
+		}
+		return nil
+	}
+
+	list, ok := cases.([]interface{})
+	if !ok {
+		return fmt.Errorf("cases must be an array")
+	}
+	for i, item := range list {
+		branchCase, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("cases[%d] must be an object", i)
+		}
+		if branch, _ := branchCase["branch"].(string); branch == "" {
+			return fmt.Errorf("cases[%d].branch is required", i)
+		}
+		source, _ := branchCase["expression"].(string)
+		if source == "" {
+			return fmt.Errorf("cases[%d].expression is required", i)
+		}
+		if err := expression.Check(source); err != nil {
+			return fmt.Errorf("invalid cases[%d].expression: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// Execute 执行节点，分支选择由执行引擎完成，插件本身不执行
+func (c *ConditionNode) Execute(ctx context.Context, input *nodes.NodeInput) (*nodes.NodeOutput, error) {
+	return nil, nodes.ErrEngineExecuted
+}
+
+// GetDynamicData 获取动态配置数据（默认实现）
+func (c *ConditionNode) GetDynamicData(method string, params map[string]interface{}) (interface{}, error) {
+	return nil, fmt.Errorf("条件节点暂不支持动态数据获取方法: %s", method)
+}
@@ -0,0 +1,198 @@
+/**
+ * @module delay_control
+ * @description 延迟控制节点，等待固定时长或到达指定时间后原样输出输入数据
+ * @architecture 控制节点插件，只提供元数据和配置校验，等待由执行引擎以持久化唤醒时间的定时器完成
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow delay_states: waiting -> woken -> completed
+ * @rules 等待期间不占用工作协程，唤醒时间写入检查点，服务重启后继续等待剩余时间
+ * @dependencies context, time
+ * @refs service/nodes/interface.go, service/control_executor.go
+ */
+
+package control
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"flow-service/service/nodes"
+)
+
+// init 自动注册延迟节点
+func init() {
+	registry := nodes.GetRegistry()
+	if err := registry.Register(NewDelayNode()); err != nil {
+		log.Printf("注册延迟节点失败: %v", err)
+	} else {
+		log.Println("延迟节点注册成功")
+	}
+}
+
+// DelayNode 延迟控制节点
+type DelayNode struct{}
+
+// NewDelayNode 创建延迟节点
+func NewDelayNode() *DelayNode {
+	return &DelayNode{}
+}
+
+// GetMetadata 获取节点元数据
+func (d *DelayNode) GetMetadata() *nodes.NodeMetadata {
+	return &nodes.NodeMetadata{
+		ID:          nodes.TypeDelay,
+		Name:        nodes.TypeDelayDisplayName,
+		Description: "等待固定时长，或等待到指定时间、上游输出或变量给出的时间后继续执行",
+		Version:     "1.0.0",
+		Category:    nodes.CategoryControl,
+		Type:        nodes.TypeDelay,
+		Icon:        nodes.TypeDelayIcon,
+		Tags:        []string{"延迟", "等待", "控制"},
+
+		InputPorts: []nodes.PortDefinition{
+			{
+				ID:          nodes.PortWaitInput,
+				Name:        "输入数据",
+				Description: "等待结束后原样输出的数据",
+				DataType:    nodes.DataTypeAny,
+				Required:    false,
+				Multiple:    false,
+			},
+		},
+
+		OutputPorts: []nodes.PortDefinition{
+			{
+				ID:          nodes.PortWaitOutput,
+				Name:        "输出数据",
+				Description: "输入数据",
+				DataType:    nodes.DataTypeAny,
+				Required:    false,
+				Multiple:    false,
+			},
+		},
+
+		ConfigSchema: &nodes.ConfigSchema{
+			Type: "object",
+			Properties: []nodes.ConfigField{
+				{
+					Name:        "duration",
+					Type:        "string",
+					Title:       "延迟时长",
+					Description: "等待的时长，如 30s、5m、2h，或秒数",
+					Placeholder: "5m",
+					Widget:      nodes.WidgetText,
+				},
+				{
+					Name:        "until",
+					Type:        "string",
+					Title:       "等待到",
+					Description: "等待到的绝对时间，RFC3339 或 2006-01-02 15:04:05 格式",
+					Widget:      nodes.WidgetText,
+				},
+				{
+					Name:        "until_field",
+					Type:        "string",
+					Title:       "等待到（变量）",
+					Description: "从执行变量或上游输出（<节点ID>_<端口>）读取等待到的时间",
+					Widget:      nodes.WidgetText,
+				},
+			},
+		},
+
+		Author:    "Flow Service Team",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Validate 验证节点配置
+func (d *DelayNode) Validate(config map[string]interface{}) error {
+	count := 0
+	if value, exists := config["duration"]; exists && value != nil && value != "" {
+		if _, err := ParseDelayDuration(value); err != nil {
+			return err
+		}
+		count++
+	}
+	if value, exists := config["until"]; exists && value != nil && value != "" {
+		if _, err := ParseWaitTime(value); err != nil {
+			return err
+		}
+		count++
+	}
+	if field, _ := config["until_field"].(string); field != "" {
+		count++
+	}
+
+	if count != 1 {
+		return fmt.Errorf("exactly one of duration, until or until_field is required")
+	}
+	return nil
+}
+
+// ParseDelayDuration 解析延迟时长，支持时长字符串（如 30s、5m）和秒数
+func ParseDelayDuration(value interface{}) (time.Duration, error) {
+	var duration time.Duration
+	switch v := value.(type) {
+	case string:
+		if seconds, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			duration = time.Duration(seconds * float64(time.Second))
+			break
+		}
+		parsed, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %s", v)
+		}
+		duration = parsed
+	default:
+		seconds, ok := toNumber(value)
+		if !ok {
+			return 0, fmt.Errorf("invalid duration: %v", value)
+		}
+		duration = time.Duration(seconds * float64(time.Second))
+	}
+
+	if duration < 0 {
+		return 0, fmt.Errorf("duration must not be negative: %v", value)
+	}
+	return duration, nil
+}
+
+// ParseWaitTime 解析绝对时间，支持 RFC3339、2006-01-02 15:04:05（本地时区）和Unix秒
+func ParseWaitTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		text := strings.TrimSpace(v)
+		if t, err := time.Parse(time.RFC3339, text); err == nil {
+			return t, nil
+		}
+		if t, err := time.ParseInLocation("2006-01-02 15:04:05", text, time.Local); err == nil {
+			return t, nil
+		}
+		if seconds, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return time.Unix(seconds, 0), nil
+		}
+		return time.Time{}, fmt.Errorf("invalid time: %s", v)
+	default:
+		seconds, ok := toNumber(value)
+		if !ok {
+			return time.Time{}, fmt.Errorf("invalid time: %v", value)
+		}
+		return time.Unix(int64(seconds), 0), nil
+	}
+}
+
+// Execute 执行节点，等待由执行引擎完成，插件本身不执行
+func (d *DelayNode) Execute(ctx context.Context, input *nodes.NodeInput) (*nodes.NodeOutput, error) {
+	return nil, nodes.ErrEngineExecuted
+}
+
+// GetDynamicData 获取动态配置数据（默认实现）
+func (d *DelayNode) GetDynamicData(method string, params map[string]interface{}) (interface{}, error) {
+	return nil, fmt.Errorf("延迟节点暂不支持动态数据获取方法: %s", method)
+}
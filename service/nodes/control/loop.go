@@ -0,0 +1,203 @@
+/**
+ * @module loop_control
+ * @description 循环控制节点，按数组元素、计数或条件重复执行循环体子图
+ * @architecture 控制节点插件，只提供元数据和配置校验，循环体由执行引擎调度
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow loop_states: configured -> iterating -> collecting -> completed
+ * @rules 循环体为经循环边可达的节点，循环体节点不得连接到循环体之外的节点
+ * @dependencies context, time, service/expression
+ * @refs service/nodes/interface.go, service/workflow_engine.go
+ */
+
+package control
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"flow-service/service/expression"
+	"flow-service/service/nodes"
+)
+
+// init 自动注册循环节点
+func init() {
+	registry := nodes.GetRegistry()
+	if err := registry.Register(NewLoopNode()); err != nil {
+		log.Printf("注册循环节点失败: %v", err)
+	} else {
+		log.Println("循环节点注册成功")
+	}
+}
+
+// LoopNode 循环控制节点
+type LoopNode struct{}
+
+// NewLoopNode 创建循环节点
+func NewLoopNode() *LoopNode {
+	return &LoopNode{}
+}
+
+// GetMetadata 获取节点元数据
+func (l *LoopNode) GetMetadata() *nodes.NodeMetadata {
+	return &nodes.NodeMetadata{
+		ID:          nodes.TypeLoop,
+		Name:        nodes.TypeLoopDisplayName,
+		Description: "按数组元素、次数或条件重复执行循环体，循环体通过循环边连接，迭代结果汇总为数组输出",
+		Version:     "1.0.0",
+		Category:    nodes.CategoryControl,
+		Type:        nodes.TypeLoop,
+		Icon:        nodes.TypeLoopIcon,
+		Tags:        []string{"循环", "遍历", "控制"},
+
+		InputPorts: []nodes.PortDefinition{
+			{
+				ID:          nodes.PortLoopItems,
+				Name:        "遍历数据",
+				Description: "foreach 循环遍历的数组，未连接时使用配置中的数据",
+				DataType:    nodes.DataTypeArray,
+				Required:    false,
+				Multiple:    false,
+			},
+		},
+
+		OutputPorts: []nodes.PortDefinition{
+			{
+				ID:          nodes.PortLoopResults,
+				Name:        "迭代结果",
+				Description: "每次迭代循环体输出组成的数组",
+				DataType:    nodes.DataTypeArray,
+				Required:    true,
+				Multiple:    false,
+			},
+			{
+				ID:          nodes.PortLoopItem,
+				Name:        "当前元素",
+				Description: "当前迭代的元素，通过循环边传给循环体",
+				DataType:    nodes.DataTypeAny,
+				Required:    false,
+				Multiple:    false,
+			},
+		},
+
+		ConfigSchema: &nodes.ConfigSchema{
+			Type: "object",
+			Properties: []nodes.ConfigField{
+				{
+					Name:        "type",
+					Type:        "string",
+					Title:       "循环类型",
+					Description: "foreach 遍历数组，for 按次数循环，while 条件成立时循环",
+					Default:     "foreach",
+					Enum:        []interface{}{"foreach", "for", "while"},
+					Widget:      nodes.WidgetSelect,
+				},
+				{
+					Name:        "condition",
+					Type:        "string",
+					Title:       "循环条件",
+					Description: "while 循环的条件表达式，可引用 loop_index 和上次迭代的节点输出",
+					Widget:      nodes.WidgetText,
+				},
+				{
+					Name:        "variable",
+					Type:        "string",
+					Title:       "循环变量",
+					Description: "循环体中引用当前元素的变量名，索引变量为 loop_index",
+					Default:     "item",
+					Widget:      nodes.WidgetText,
+				},
+				{
+					Name:        "data",
+					Type:        "string",
+					Title:       "循环数据",
+					Description: "foreach 的数组或 for 的循环次数",
+					Widget:      nodes.WidgetJSON,
+				},
+				{
+					Name:        "max_iterations",
+					Type:        "number",
+					Title:       "最大迭代次数",
+					Description: "超过该次数时循环节点失败",
+					Default:     1000,
+					Widget:      nodes.WidgetNumber,
+				},
+				{
+					Name:        "parallel",
+					Type:        "boolean",
+					Title:       "并行执行",
+					Description: "foreach 和 for 循环是否并行执行迭代",
+					Default:     false,
+					Widget:      nodes.WidgetBoolean,
+				},
+				{
+					Name:        "concurrency",
+					Type:        "number",
+					Title:       "并发数",
+					Description: "并行执行时同时运行的迭代数",
+					Default:     1,
+					Widget:      nodes.WidgetNumber,
+				},
+			},
+		},
+
+		Author:    "Flow Service Team",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Validate 验证节点配置
+func (l *LoopNode) Validate(config map[string]interface{}) error {
+	loopType, _ := config["type"].(string)
+	switch loopType {
+	case "", "foreach", "for":
+	case "while":
+		condition, _ := config["condition"].(string)
+		if condition == "" {
+			return fmt.Errorf("while loop requires condition")
+		}
+		if err := expression.Check(condition); err != nil {
+			return fmt.Errorf("invalid loop condition: %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid loop type: %s", loopType)
+	}
+
+	for _, field := range []string{"max_iterations", "concurrency"} {
+		value, exists := config[field]
+		if !exists {
+			continue
+		}
+		if number, ok := toNumber(value); !ok || number < 1 {
+			return fmt.Errorf("%s must be a positive number", field)
+		}
+	}
+
+	return nil
+}
+
+// toNumber 将配置中的数值转换为float64
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// Execute 执行节点，循环体由执行引擎调度，插件本身不执行
+func (l *LoopNode) Execute(ctx context.Context, input *nodes.NodeInput) (*nodes.NodeOutput, error) {
+	return nil, nodes.ErrEngineExecuted
+}
+
+// GetDynamicData 获取动态配置数据（默认实现）
+func (l *LoopNode) GetDynamicData(method string, params map[string]interface{}) (interface{}, error) {
+	return nil, fmt.Errorf("循环节点暂不支持动态数据获取方法: %s", method)
+}
@@ -0,0 +1,140 @@
+/**
+ * @module subdag_control
+ * @description 子流程控制节点，以关联的子执行运行另一个已保存的工作流并等待其结果
+ * @architecture 控制节点插件，只提供元数据和配置校验，子执行由执行引擎通过执行服务创建和等待
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow subdag_states: mapping_input -> child_running -> child_finished -> completed/failed
+ * @rules 子执行记录父执行ID和父节点ID，取消父执行时级联取消子执行
+ * @dependencies context, time
+ * @refs service/nodes/interface.go, service/control_executor.go
+ */
+
+package control
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"flow-service/service/nodes"
+)
+
+// init 自动注册子流程节点
+func init() {
+	registry := nodes.GetRegistry()
+	if err := registry.Register(NewSubDAGNode()); err != nil {
+		log.Printf("注册子流程节点失败: %v", err)
+	} else {
+		log.Println("子流程节点注册成功")
+	}
+}
+
+// SubDAGNode 子流程控制节点
+type SubDAGNode struct{}
+
+// NewSubDAGNode 创建子流程节点
+func NewSubDAGNode() *SubDAGNode {
+	return &SubDAGNode{}
+}
+
+// GetMetadata 获取节点元数据
+func (s *SubDAGNode) GetMetadata() *nodes.NodeMetadata {
+	return &nodes.NodeMetadata{
+		ID:          nodes.TypeSubDAG,
+		Name:        nodes.TypeSubDAGDisplayName,
+		Description: "运行另一个已保存的工作流，输入数据作为子工作流的输入参数，子工作流的输出作为节点输出",
+		Version:     "1.0.0",
+		Category:    nodes.CategoryControl,
+		Type:        nodes.TypeSubDAG,
+		Icon:        nodes.TypeSubDAGIcon,
+		Tags:        []string{"子流程", "复用", "控制"},
+
+		InputPorts: []nodes.PortDefinition{
+			{
+				ID:          nodes.PortSubWorkflowInput,
+				Name:        "输入参数",
+				Description: "子工作流执行的输入参数，覆盖配置中的同名参数",
+				DataType:    nodes.DataTypeObject,
+				Required:    false,
+				Multiple:    false,
+			},
+		},
+
+		OutputPorts: []nodes.PortDefinition{
+			{
+				ID:          nodes.PortSubWorkflowOutput,
+				Name:        "输出结果",
+				Description: "子工作流执行的输出结果",
+				DataType:    nodes.DataTypeObject,
+				Required:    true,
+				Multiple:    false,
+			},
+			{
+				ID:          nodes.PortSubWorkflowExecutionID,
+				Name:        "子执行ID",
+				Description: "子工作流执行记录ID",
+				DataType:    nodes.DataTypeString,
+				Required:    false,
+				Multiple:    false,
+			},
+		},
+
+		ConfigSchema: &nodes.ConfigSchema{
+			Type: "object",
+			Properties: []nodes.ConfigField{
+				{
+					Name:        "workflow_id",
+					Type:        "string",
+					Title:       "工作流ID",
+					Description: "要运行的子工作流ID",
+					Widget:      nodes.WidgetText,
+				},
+				{
+					Name:        "version",
+					Type:        "string",
+					Title:       "工作流版本",
+					Description: "指定时要求子工作流为该版本，为空时使用当前版本",
+					Widget:      nodes.WidgetText,
+				},
+				{
+					Name:        "input",
+					Type:        "object",
+					Title:       "输入参数",
+					Description: "传给子工作流的固定输入参数",
+					Widget:      nodes.WidgetJSON,
+				},
+			},
+			Required: []string{"workflow_id"},
+		},
+
+		Author:    "Flow Service Team",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Validate 验证节点配置
+func (s *SubDAGNode) Validate(config map[string]interface{}) error {
+	if workflowID, _ := config["workflow_id"].(string); workflowID == "" {
+		return fmt.Errorf("workflow_id is required")
+	}
+
+	if input, exists := config["input"]; exists && input != nil {
+		if _, ok := input.(map[string]interface{}); !ok {
+			return fmt.Errorf("input must be an object")
+		}
+	}
+
+	return nil
+}
+
+// Execute 执行节点，子执行由执行引擎创建和等待，插件本身不执行
+func (s *SubDAGNode) Execute(ctx context.Context, input *nodes.NodeInput) (*nodes.NodeOutput, error) {
+	return nil, nodes.ErrEngineExecuted
+}
+
+// GetDynamicData 获取动态配置数据（默认实现）
+func (s *SubDAGNode) GetDynamicData(method string, params map[string]interface{}) (interface{}, error) {
+	return nil, fmt.Errorf("子流程节点暂不支持动态数据获取方法: %s", method)
+}
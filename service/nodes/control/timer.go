@@ -0,0 +1,146 @@
+/**
+ * @module timer_control
+ * @description 定时器控制节点，等待到下一个每日定时时刻后继续执行
+ * @architecture 控制节点插件，只提供元数据和配置校验，等待由执行引擎以持久化唤醒时间的定时器完成
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow timer_states: waiting -> fired -> completed
+ * @rules 时刻按配置的时区计算，未配置时区时使用服务本地时区
+ * @dependencies context, time
+ * @refs service/nodes/interface.go, service/control_executor.go
+ */
+
+package control
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"flow-service/service/nodes"
+)
+
+// init 自动注册定时器节点
+func init() {
+	registry := nodes.GetRegistry()
+	if err := registry.Register(NewTimerNode()); err != nil {
+		log.Printf("注册定时器节点失败: %v", err)
+	} else {
+		log.Println("定时器节点注册成功")
+	}
+}
+
+// TimerNode 定时器控制节点
+type TimerNode struct{}
+
+// NewTimerNode 创建定时器节点
+func NewTimerNode() *TimerNode {
+	return &TimerNode{}
+}
+
+// GetMetadata 获取节点元数据
+func (t *TimerNode) GetMetadata() *nodes.NodeMetadata {
+	return &nodes.NodeMetadata{
+		ID:          nodes.TypeTimer,
+		Name:        nodes.TypeTimerDisplayName,
+		Description: "等待到下一个每日定时时刻（如 02:00）后继续执行",
+		Version:     "1.0.0",
+		Category:    nodes.CategoryControl,
+		Type:        nodes.TypeTimer,
+		Icon:        nodes.TypeTimerIcon,
+		Tags:        []string{"定时", "等待", "控制"},
+
+		InputPorts: []nodes.PortDefinition{
+			{
+				ID:          nodes.PortWaitInput,
+				Name:        "输入数据",
+				Description: "定时到达后原样输出的数据",
+				DataType:    nodes.DataTypeAny,
+				Required:    false,
+				Multiple:    false,
+			},
+		},
+
+		OutputPorts: []nodes.PortDefinition{
+			{
+				ID:          nodes.PortWaitOutput,
+				Name:        "输出数据",
+				Description: "输入数据",
+				DataType:    nodes.DataTypeAny,
+				Required:    false,
+				Multiple:    false,
+			},
+		},
+
+		ConfigSchema: &nodes.ConfigSchema{
+			Type: "object",
+			Properties: []nodes.ConfigField{
+				{
+					Name:        "at",
+					Type:        "string",
+					Title:       "定时时刻",
+					Description: "每日触发时刻，HH:MM 或 HH:MM:SS 格式",
+					Placeholder: "02:00",
+					Widget:      nodes.WidgetText,
+				},
+				{
+					Name:        "timezone",
+					Type:        "string",
+					Title:       "时区",
+					Description: "计算时刻使用的时区，如 Asia/Shanghai，为空时使用服务本地时区",
+					Widget:      nodes.WidgetText,
+				},
+			},
+			Required: []string{"at"},
+		},
+
+		Author:    "Flow Service Team",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Validate 验证节点配置
+func (t *TimerNode) Validate(config map[string]interface{}) error {
+	at, _ := config["at"].(string)
+	timezone, _ := config["timezone"].(string)
+	_, err := NextTimeOfDay(at, timezone, time.Now())
+	return err
+}
+
+// NextTimeOfDay 计算 after 之后下一次到达每日时刻 at 的时间
+func NextTimeOfDay(at string, timezone string, after time.Time) (time.Time, error) {
+	location := time.Local
+	if timezone != "" {
+		loaded, err := time.LoadLocation(timezone)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timezone: %s", timezone)
+		}
+		location = loaded
+	}
+
+	var clock time.Time
+	var err error
+	if clock, err = time.Parse("15:04:05", at); err != nil {
+		if clock, err = time.Parse("15:04", at); err != nil {
+			return time.Time{}, fmt.Errorf("invalid time of day: %s", at)
+		}
+	}
+
+	local := after.In(location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, location)
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next, nil
+}
+
+// Execute 执行节点，等待由执行引擎完成，插件本身不执行
+func (t *TimerNode) Execute(ctx context.Context, input *nodes.NodeInput) (*nodes.NodeOutput, error) {
+	return nil, nodes.ErrEngineExecuted
+}
+
+// GetDynamicData 获取动态配置数据（默认实现）
+func (t *TimerNode) GetDynamicData(method string, params map[string]interface{}) (interface{}, error) {
+	return nil, fmt.Errorf("定时器节点暂不支持动态数据获取方法: %s", method)
+}
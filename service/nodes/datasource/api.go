@@ -213,6 +213,17 @@ func (a *APINode) GetMetadata() *nodes.NodeMetadata {
 	}
 }
 
+// IsReadOnly 只有 GET、HEAD、OPTIONS 请求视为只读，未配置方法时按默认的 GET 处理
+func (a *APINode) IsReadOnly(config map[string]interface{}) bool {
+	method, _ := config["method"].(string)
+	switch strings.ToUpper(method) {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
 // Validate 验证节点配置
 func (a *APINode) Validate(config map[string]interface{}) error {
 	// 验证URL
@@ -277,8 +288,8 @@ func (a *APINode) Execute(ctx context.Context, input *nodes.NodeInput) (*nodes.N
 	}
 	defer resp.Body.Close()
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
+	// 读取响应体，ctx 结束或超出读取上限时中止
+	body, err := io.ReadAll(nodes.ContextReader(ctx, resp.Body))
 	if err != nil {
 		output.Error = fmt.Sprintf("failed to read response body: %v", err)
 		output.Duration = time.Since(startTime)
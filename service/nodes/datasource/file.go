@@ -106,6 +106,11 @@ func (f *FileNode) GetMetadata() *nodes.NodeMetadata {
 	}
 }
 
+// IsReadOnly 文件数据源只读取文件
+func (f *FileNode) IsReadOnly(config map[string]interface{}) bool {
+	return true
+}
+
 // Validate 验证节点配置
 func (f *FileNode) Validate(config map[string]interface{}) error {
 	path, ok := config["path"].(string)
@@ -145,7 +150,7 @@ func (f *FileNode) Execute(ctx context.Context, input *nodes.NodeInput) (*nodes.
 	}
 
 	// 读取文件
-	data, err := f.readFile(path, format)
+	data, err := f.readFile(ctx, path, format)
 	if err != nil {
 		output.Error = fmt.Sprintf("读取文件失败: %v", err)
 		output.Duration = time.Since(startTime)
@@ -165,32 +170,32 @@ func (f *FileNode) Execute(ctx context.Context, input *nodes.NodeInput) (*nodes.
 }
 
 // readFile 读取文件
-func (f *FileNode) readFile(path, format string) ([]map[string]interface{}, error) {
+func (f *FileNode) readFile(ctx context.Context, path, format string) ([]map[string]interface{}, error) {
 	switch strings.ToLower(format) {
 	case "csv":
-		return f.readCSV(path)
+		return f.readCSV(ctx, path)
 	case "json":
-		return f.readJSON(path)
+		return f.readJSON(ctx, path)
 	case "txt":
-		return f.readText(path)
+		return f.readText(ctx, path)
 	case "xml":
-		return f.readXML(path)
+		return f.readXML(ctx, path)
 	case "yaml", "yml":
-		return f.readYAML(path)
+		return f.readYAML(ctx, path)
 	default:
 		return nil, fmt.Errorf("unsupported file format: %s", format)
 	}
 }
 
 // readCSV 读取CSV文件
-func (f *FileNode) readCSV(path string) ([]map[string]interface{}, error) {
+func (f *FileNode) readCSV(ctx context.Context, path string) ([]map[string]interface{}, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
+	reader := csv.NewReader(nodes.ContextReader(ctx, file))
 	records, err := reader.ReadAll()
 	if err != nil {
 		return nil, err
@@ -218,7 +223,7 @@ func (f *FileNode) readCSV(path string) ([]map[string]interface{}, error) {
 }
 
 // readJSON 读取JSON文件
-func (f *FileNode) readJSON(path string) ([]map[string]interface{}, error) {
+func (f *FileNode) readJSON(ctx context.Context, path string) ([]map[string]interface{}, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -226,7 +231,7 @@ func (f *FileNode) readJSON(path string) ([]map[string]interface{}, error) {
 	defer file.Close()
 
 	var data interface{}
-	decoder := json.NewDecoder(file)
+	decoder := json.NewDecoder(nodes.ContextReader(ctx, file))
 	if err := decoder.Decode(&data); err != nil {
 		return nil, err
 	}
@@ -249,14 +254,14 @@ func (f *FileNode) readJSON(path string) ([]map[string]interface{}, error) {
 }
 
 // readText 读取文本文件
-func (f *FileNode) readText(path string) ([]map[string]interface{}, error) {
+func (f *FileNode) readText(ctx context.Context, path string) ([]map[string]interface{}, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	content, err := io.ReadAll(file)
+	content, err := io.ReadAll(nodes.ContextReader(ctx, file))
 	if err != nil {
 		return nil, err
 	}
@@ -277,15 +282,15 @@ func (f *FileNode) readText(path string) ([]map[string]interface{}, error) {
 }
 
 // readXML 读取XML文件
-func (f *FileNode) readXML(path string) ([]map[string]interface{}, error) {
+func (f *FileNode) readXML(ctx context.Context, path string) ([]map[string]interface{}, error) {
 	// 为了简化，目前返回XML文件的文本内容，后续可以扩展为真正的XML解析
-	return f.readText(path)
+	return f.readText(ctx, path)
 }
 
 // readYAML 读取YAML文件
-func (f *FileNode) readYAML(path string) ([]map[string]interface{}, error) {
+func (f *FileNode) readYAML(ctx context.Context, path string) ([]map[string]interface{}, error) {
 	// 为了简化，目前返回YAML文件的文本内容，后续可以扩展为真正的YAML解析
-	return f.readText(path)
+	return f.readText(ctx, path)
 }
 
 // GetDynamicData 获取动态配置数据（默认实现）
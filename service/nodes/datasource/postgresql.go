@@ -16,6 +16,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"flow-service/service/nodes"
@@ -132,9 +133,11 @@ func (p *PostgreSQLNode) GetMetadata() *nodes.NodeMetadata {
 					Name:        "sql",
 					Type:        "string",
 					Title:       "SQL语句",
-					Description: "要执行的SQL查询语句，支持参数占位符 $1, $2...",
+					Description: "要执行的SQL查询语句，支持参数占位符 $1, $2...；{{ }} 模板的值作为参数绑定，不拼接进语句",
 					Widget:      nodes.WidgetCode,
-					Placeholder: "SELECT * FROM table_name WHERE id = $1",
+					Placeholder: "SELECT * FROM table_name WHERE id = $1 AND ds = {{ input.date }}",
+					Template:    nodes.TemplateSQL,
+					BindTo:      "params",
 				},
 				{
 					Name:        "params",
@@ -188,6 +191,80 @@ func (p *PostgreSQLNode) GetMetadata() *nodes.NodeMetadata {
 	}
 }
 
+// IsReadOnly 判断 SQL 是否为只读查询：单条 SELECT/WITH/VALUES/SHOW/TABLE 语句，且不含写入、DDL 和加锁关键字
+func (p *PostgreSQLNode) IsReadOnly(config map[string]interface{}) bool {
+	statement, _ := config["sql"].(string)
+	words := sqlKeywords(statement)
+	if len(words) == 0 {
+		return false
+	}
+
+	switch words[0] {
+	case "SELECT", "WITH", "VALUES", "SHOW", "TABLE":
+	default:
+		return false
+	}
+	for _, word := range words {
+		if writeSQLKeywords[word] {
+			return false
+		}
+	}
+	return !strings.Contains(strings.TrimRight(strings.TrimSpace(statement), ";"), ";")
+}
+
+// writeSQLKeywords 出现即视为非只读的关键字，SELECT INTO 会建表，FOR UPDATE/SHARE 会加锁
+var writeSQLKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "UPSERT": true,
+	"TRUNCATE": true, "DROP": true, "ALTER": true, "CREATE": true, "GRANT": true, "REVOKE": true,
+	"COPY": true, "CALL": true, "DO": true, "INTO": true, "SHARE": true, "LOCK": true,
+	"NEXTVAL": true, "SETVAL": true, "VACUUM": true, "REINDEX": true, "CLUSTER": true,
+}
+
+// sqlKeywords 提取 SQL 中的大写单词，忽略字符串字面量、带引号的标识符和注释
+func sqlKeywords(statement string) []string {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, strings.ToUpper(word.String()))
+			word.Reset()
+		}
+	}
+
+	for i := 0; i < len(statement); i++ {
+		c := statement[i]
+		switch {
+		case c == '\'' || c == '"':
+			flush()
+			end := strings.IndexByte(statement[i+1:], c)
+			if end < 0 {
+				return words
+			}
+			i += end + 1
+		case c == '-' && i+1 < len(statement) && statement[i+1] == '-':
+			flush()
+			end := strings.IndexByte(statement[i:], '\n')
+			if end < 0 {
+				return words
+			}
+			i += end
+		case c == '/' && i+1 < len(statement) && statement[i+1] == '*':
+			flush()
+			end := strings.Index(statement[i+2:], "*/")
+			if end < 0 {
+				return words
+			}
+			i += end + 3
+		case c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9'):
+			word.WriteByte(c)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
 // Validate 验证节点配置
 func (p *PostgreSQLNode) Validate(config map[string]interface{}) error {
 	// 验证连接配置
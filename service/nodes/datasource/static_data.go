@@ -93,6 +93,11 @@ func (s *StaticDataNode) GetMetadata() *nodes.NodeMetadata {
 	}
 }
 
+// IsReadOnly 静态数据源不访问外部系统
+func (s *StaticDataNode) IsReadOnly(config map[string]interface{}) bool {
+	return true
+}
+
 // Validate 验证节点配置
 func (s *StaticDataNode) Validate(config map[string]interface{}) error {
 	jsonData, ok := config["json_data"].(string)
@@ -4,7 +4,7 @@
  * @architecture 插件化节点系统设计，支持动态注册和配置，扩展了动态数据获取功能
  * @documentReference ai_docs/refactor_plan.md
  * @stateFlow node_states: created -> configured -> ready -> executing -> completed/failed
- * @rules 所有节点必须实现NodePlugin接口，元数据必须包含完整的配置定义，支持动态数据获取
+ * @rules 所有节点必须实现NodePlugin接口，元数据必须包含完整的配置定义，支持动态数据获取；Execute 必须响应 ctx 取消
  * @dependencies context, time
  * @refs service/models/node.go
  */
@@ -24,13 +24,21 @@ type NodePlugin interface {
 	// Validate 验证节点配置
 	Validate(config map[string]interface{}) error
 
-	// Execute 执行节点
+	// Execute 执行节点。ctx 结束（取消或超时）后必须尽快返回：读取文件、HTTP 响应等外部数据
+	// 使用 ContextReader 包装，逐条处理数据的循环定期调用 CheckCanceled，阻塞调用传入 ctx。
+	// 调用方超时后不再等待插件返回，不响应 ctx 的插件会继续占用执行资源
 	Execute(ctx context.Context, input *NodeInput) (*NodeOutput, error)
 
 	// GetDynamicData 获取动态配置数据（新增）
 	GetDynamicData(method string, params map[string]interface{}) (interface{}, error)
 }
 
+// ReadOnlyChecker 可选接口，数据源插件实现后按配置判断执行是否只读（不对外写入）；
+// 模拟运行只实际执行只读的数据源节点，未实现该接口的数据源节点视为有外部写入
+type ReadOnlyChecker interface {
+	IsReadOnly(config map[string]interface{}) bool
+}
+
 // NodeMetadata 节点元数据（简化版）
 type NodeMetadata struct {
 	// 基础信息
@@ -91,6 +99,10 @@ type ConfigField struct {
 
 	// 动态数据源配置（新增）
 	DataSource *DataSourceConfig `json:"data_source,omitempty"`
+
+	// 模板处理方式，为空时渲染 {{ }} 模板；sql 时模板值绑定为参数并追加到 BindTo 字段
+	Template string `json:"template,omitempty"`
+	BindTo   string `json:"bind_to,omitempty"`
 }
 
 // DataSourceConfig 动态数据源配置（新增）
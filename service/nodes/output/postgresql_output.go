@@ -120,8 +120,9 @@ func (p *PostgreSQLOutputNode) GetMetadata() *nodes.NodeMetadata {
 					Name:        "table_name",
 					Type:        "string",
 					Title:       "表名",
-					Description: "目标数据表名称",
+					Description: "目标数据表名称，拼接进SQL语句，不支持 {{ }} 模板",
 					Widget:      nodes.WidgetText,
+					Template:    nodes.TemplateNone,
 				},
 				{
 					Name:        "operation",
@@ -153,11 +154,12 @@ func (p *PostgreSQLOutputNode) GetMetadata() *nodes.NodeMetadata {
 					Name:        "primary_key",
 					Type:        "array",
 					Title:       "主键字段",
-					Description: "用于冲突检测的主键字段列表",
+					Description: "用于冲突检测的主键字段列表，拼接进SQL语句，不支持 {{ }} 模板",
 					Items: &nodes.ConfigField{
 						Type: "string",
 					},
-					Widget: nodes.WidgetJSON,
+					Widget:   nodes.WidgetJSON,
+					Template: nodes.TemplateNone,
 				},
 			},
 			Required: []string{"host", "database", "username", "password", "table_name"},
@@ -300,7 +300,10 @@ func (d *DataAggregateNode) Execute(ctx context.Context, input *nodes.NodeInput)
 	includeStats := d.getIncludeStats(input.Config)
 
 	// 执行聚合
-	aggregatedData, stats := d.aggregateData(data, groupBy, aggregations, having, orderBy, limit)
+	aggregatedData, stats, err := d.aggregateData(ctx, data, groupBy, aggregations, having, orderBy, limit)
+	if err != nil {
+		return nil, err
+	}
 
 	// 构建输出
 	output := &nodes.NodeOutput{
@@ -318,8 +321,8 @@ func (d *DataAggregateNode) Execute(ctx context.Context, input *nodes.NodeInput)
 	return output, nil
 }
 
-// aggregateData 聚合数据
-func (d *DataAggregateNode) aggregateData(data []interface{}, groupBy []string, aggregations []AggregationConfig, having []HavingConfig, orderBy []OrderByConfig, limit int) ([]interface{}, map[string]interface{}) {
+// aggregateData 聚合数据，ctx 结束时返回其错误
+func (d *DataAggregateNode) aggregateData(ctx context.Context, data []interface{}, groupBy []string, aggregations []AggregationConfig, having []HavingConfig, orderBy []OrderByConfig, limit int) ([]interface{}, map[string]interface{}, error) {
 	stats := map[string]interface{}{
 		"total_records":    len(data),
 		"groups_count":     0,
@@ -328,7 +331,10 @@ func (d *DataAggregateNode) aggregateData(data []interface{}, groupBy []string,
 	}
 
 	// 分组
-	groups := d.groupData(data, groupBy)
+	groups, err := d.groupData(ctx, data, groupBy)
+	if err != nil {
+		return nil, nil, err
+	}
 	stats["groups_count"] = len(groups)
 
 	// 聚合
@@ -389,14 +395,18 @@ func (d *DataAggregateNode) aggregateData(data []interface{}, groupBy []string,
 		aggregatedData = aggregatedData[:limit]
 	}
 
-	return aggregatedData, stats
+	return aggregatedData, stats, nil
 }
 
-// groupData 分组数据
-func (d *DataAggregateNode) groupData(data []interface{}, groupBy []string) map[string][]map[string]interface{} {
+// groupData 分组数据，ctx 结束时返回其错误
+func (d *DataAggregateNode) groupData(ctx context.Context, data []interface{}, groupBy []string) (map[string][]map[string]interface{}, error) {
 	groups := make(map[string][]map[string]interface{})
 
-	for _, itemRaw := range data {
+	for i, itemRaw := range data {
+		if err := nodes.CheckCanceled(ctx, i); err != nil {
+			return nil, err
+		}
+
 		item, ok := itemRaw.(map[string]interface{})
 		if !ok {
 			continue
@@ -425,7 +435,7 @@ func (d *DataAggregateNode) groupData(data []interface{}, groupBy []string) map[
 		groups[groupKey] = append(groups[groupKey], item)
 	}
 
-	return groups
+	return groups, nil
 }
 
 // executeAggregation 执行聚合函数
@@ -236,7 +236,10 @@ func (d *DataFilterNode) Execute(ctx context.Context, input *nodes.NodeInput) (*
 	outputConfig := d.getOutputConfig(input.Config)
 
 	// 执行过滤
-	filteredData, excludedData, stats := d.filterData(dataArray, conditions, logic)
+	filteredData, excludedData, stats, err := d.filterData(ctx, dataArray, conditions, logic)
+	if err != nil {
+		return nil, err
+	}
 
 	// 应用结果限制
 	if outputConfig.Limit > 0 && len(filteredData) > outputConfig.Limit {
@@ -270,8 +273,8 @@ func (d *DataFilterNode) Execute(ctx context.Context, input *nodes.NodeInput) (*
 	return output, nil
 }
 
-// filterData 执行数据过滤
-func (d *DataFilterNode) filterData(data []interface{}, conditions []interface{}, logic string) ([]interface{}, []interface{}, map[string]interface{}) {
+// filterData 执行数据过滤，ctx 结束时返回其错误
+func (d *DataFilterNode) filterData(ctx context.Context, data []interface{}, conditions []interface{}, logic string) ([]interface{}, []interface{}, map[string]interface{}, error) {
 	var filteredData []interface{}
 	var excludedData []interface{}
 
@@ -282,7 +285,11 @@ func (d *DataFilterNode) filterData(data []interface{}, conditions []interface{}
 		"filter_time": time.Now(),
 	}
 
-	for _, item := range data {
+	for i, item := range data {
+		if err := nodes.CheckCanceled(ctx, i); err != nil {
+			return nil, nil, nil, err
+		}
+
 		itemMap, ok := item.(map[string]interface{})
 		if !ok {
 			excludedData = append(excludedData, item)
@@ -300,7 +307,7 @@ func (d *DataFilterNode) filterData(data []interface{}, conditions []interface{}
 	stats["excluded_count"] = len(excludedData)
 	stats["filter_rate"] = float64(len(filteredData)) / float64(len(data))
 
-	return filteredData, excludedData, stats
+	return filteredData, excludedData, stats, nil
 }
 
 // evaluateConditions 评估条件
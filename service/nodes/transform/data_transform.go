@@ -241,7 +241,10 @@ func (d *DataTransformNode) Execute(ctx context.Context, input *nodes.NodeInput)
 	globalConfig := d.getGlobalConfig(input.Config)
 
 	// 执行转换
-	transformedData, stats := d.transformData(data, mappings, globalConfig)
+	transformedData, stats, err := d.transformData(ctx, data, mappings, globalConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	// 构建输出
 	output := &nodes.NodeOutput{
@@ -259,8 +262,8 @@ func (d *DataTransformNode) Execute(ctx context.Context, input *nodes.NodeInput)
 	return output, nil
 }
 
-// transformData 转换数据
-func (d *DataTransformNode) transformData(data []interface{}, mappings []MappingConfig, globalConfig *GlobalConfig) ([]interface{}, map[string]interface{}) {
+// transformData 转换数据，ctx 结束时返回其错误
+func (d *DataTransformNode) transformData(ctx context.Context, data []interface{}, mappings []MappingConfig, globalConfig *GlobalConfig) ([]interface{}, map[string]interface{}, error) {
 	transformedData := make([]interface{}, 0, len(data))
 	stats := map[string]interface{}{
 		"total_records":     len(data),
@@ -271,7 +274,11 @@ func (d *DataTransformNode) transformData(data []interface{}, mappings []Mapping
 
 	fieldStats := make(map[string]map[string]int)
 
-	for _, itemRaw := range data {
+	for i, itemRaw := range data {
+		if err := nodes.CheckCanceled(ctx, i); err != nil {
+			return nil, nil, err
+		}
+
 		item, ok := itemRaw.(map[string]interface{})
 		if !ok {
 			stats["error_count"] = stats["error_count"].(int) + 1
@@ -318,7 +325,7 @@ func (d *DataTransformNode) transformData(data []interface{}, mappings []Mapping
 	}
 
 	stats["field_stats"] = fieldStats
-	return transformedData, stats
+	return transformedData, stats, nil
 }
 
 // applyMapping 应用单个映射
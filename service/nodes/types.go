@@ -4,13 +4,15 @@
  * @architecture 常量定义模块，提供系统级别的节点分类和类型定义
  * @documentReference ai_docs/refactor_plan.md
  * @stateFlow 无状态常量定义
- * @rules 只包含常量定义，不引用其他包，避免循环导入
+ * @rules 只包含常量和哨兵错误定义，不引用其他包，避免循环导入
  * @dependencies 无
  * @refs service/nodes/interface.go
  */
 
 package nodes
 
+import "errors"
+
 // 节点分类常量
 const (
 	CategoryDataSource = "datasource" // 数据源
@@ -187,3 +189,34 @@ const (
 	WidgetCode     = "code"
 	WidgetJSON     = "json"
 )
+
+// 配置字段模板处理方式
+const (
+	TemplateText = ""     // 渲染 {{ }} 模板，单个表达式保留原类型
+	TemplateSQL  = "sql"  // 模板值绑定为 SQL 参数，文本中替换为 $n 占位符
+	TemplateNone = "none" // 不渲染模板，用于不能参数化的标识符等字段
+)
+
+// 控制节点端口常量
+const (
+	PortLoopItems   = "items"   // 循环节点输入：待遍历的数组
+	PortLoopItem    = "item"    // 循环节点输出：当前迭代元素，经循环边传给循环体
+	PortLoopResults = "results" // 循环节点输出：所有迭代结果组成的数组
+
+	PortConditionInput   = "input"   // 条件节点输入：参与条件判断的数据
+	PortConditionOutput  = "output"  // 条件节点输出：不区分分支的透传数据
+	PortConditionTrue    = "true"    // 条件节点输出：条件成立分支
+	PortConditionFalse   = "false"   // 条件节点输出：条件不成立分支
+	PortConditionDefault = "default" // 条件节点输出：没有分支匹配时的默认分支
+	PortConditionBranch  = "branch"  // 条件节点输出：选中的分支名
+
+	PortSubWorkflowInput       = "input"        // 子流程节点输入：映射为子工作流执行的输入参数
+	PortSubWorkflowOutput      = "output"       // 子流程节点输出：子工作流执行的输出结果，声明了输出时按输出名索引
+	PortSubWorkflowExecutionID = "execution_id" // 子流程节点输出：子工作流执行ID
+
+	PortWaitInput  = "input"  // 延迟/定时器节点输入：等待结束后原样输出的数据
+	PortWaitOutput = "output" // 延迟/定时器节点输出：输入数据
+)
+
+// ErrEngineExecuted 控制节点由执行引擎直接调度，插件本身不执行
+var ErrEngineExecuted = errors.New("control node is executed by the workflow engine")
@@ -4,9 +4,10 @@
  * @architecture 轻量级调度器设计，专注于基本调度功能
  * @documentReference ai_docs/refactor_plan.md
  * @stateFlow scheduler_states: stopped -> running -> stopping -> stopped
- * @rules 调度器状态变更必须遵循状态机规则，支持基本的定时任务调度
- * @dependencies service/models/workflow.go
- * @refs service/workflow_service.go
+ * @rules 调度器状态变更必须遵循状态机规则，支持基本的定时任务调度；
+ *        cron 按调度时区计算触发时间，定时执行的数据区间为上一次到本次计划触发时间
+ * @dependencies service/models/workflow.go, service/cron_schedule.go
+ * @refs service/workflow_service.go, service/execution_service.go, service/execution_dates.go
  */
 
 package service
@@ -30,6 +31,9 @@ const (
 	SchedulerStatusStopping
 )
 
+// ScheduleTrigger 调度触发回调，由执行服务实现，按计划触发时间和数据区间创建并启动执行
+type ScheduleTrigger func(workflowID string, scheduledAt, intervalStart, intervalEnd time.Time) error
+
 // SimpleScheduler 简化调度器
 type SimpleScheduler struct {
 	status       SchedulerStatus
@@ -39,6 +43,7 @@ type SimpleScheduler struct {
 	mu           sync.RWMutex
 	wg           sync.WaitGroup
 	tickInterval time.Duration
+	trigger      ScheduleTrigger
 }
 
 // ScheduledTask 调度任务
@@ -51,6 +56,8 @@ type ScheduledTask struct {
 	LastRun    *time.Time
 	RunCount   int64
 	Enabled    bool
+	cron       *cronSchedule  // 解析后的 cron 表达式，非 cron 调度为 nil
+	location   *time.Location // 调度时区
 	mu         sync.RWMutex
 }
 
@@ -63,6 +70,13 @@ func NewSimpleScheduler() *SimpleScheduler {
 	}
 }
 
+// SetTrigger 设置调度触发回调
+func (s *SimpleScheduler) SetTrigger(trigger ScheduleTrigger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trigger = trigger
+}
+
 // Start 启动调度器
 func (s *SimpleScheduler) Start(ctx context.Context) error {
 	s.mu.Lock()
@@ -121,6 +135,28 @@ func (s *SimpleScheduler) AddTask(workflowID string, workflow *models.Workflow)
 		Name:       workflow.Name,
 		Schedule:   workflow.Schedule,
 		Enabled:    workflow.Schedule.Enabled,
+		location:   time.Local,
+	}
+
+	if workflow.Schedule.Timezone != "" {
+		location, err := time.LoadLocation(workflow.Schedule.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", workflow.Schedule.Timezone, err)
+		}
+		task.location = location
+	}
+
+	if workflow.Schedule.Type == models.ScheduleTypeCron {
+		cron, err := parseCronExpression(workflow.Schedule.CronExpression)
+		if err != nil {
+			return err
+		}
+		task.cron = cron
+	}
+
+	// 已过期的一次性调度不再触发，避免服务重启后重复执行
+	if workflow.Schedule.Type == models.ScheduleTypeOnce && workflow.Schedule.ExecuteAt != nil && workflow.Schedule.ExecuteAt.Before(time.Now()) {
+		task.Enabled = false
 	}
 
 	if err := s.calculateNextRun(task); err != nil {
@@ -184,10 +220,15 @@ func (s *SimpleScheduler) schedulingLoop(ctx context.Context) {
 
 // checkAndTriggerTasks 检查并触发任务
 func (s *SimpleScheduler) checkAndTriggerTasks() {
+	now := time.Now()
+
 	s.mu.RLock()
 	tasks := make([]*ScheduledTask, 0, len(s.tasks))
 	for _, task := range s.tasks {
-		if task.Enabled && time.Now().After(task.NextRun) {
+		task.mu.RLock()
+		due := task.Enabled && now.After(task.NextRun)
+		task.mu.RUnlock()
+		if due {
 			tasks = append(tasks, task)
 		}
 	}
@@ -198,11 +239,18 @@ func (s *SimpleScheduler) checkAndTriggerTasks() {
 	}
 }
 
-// triggerTask 触发任务执行
+// triggerTask 触发任务执行，数据区间为上一次计划触发时间到本次计划触发时间
 func (s *SimpleScheduler) triggerTask(task *ScheduledTask) {
+	s.mu.RLock()
+	trigger := s.trigger
+	s.mu.RUnlock()
+
 	task.mu.Lock()
 	defer task.mu.Unlock()
 
+	scheduledAt := task.NextRun
+	intervalStart := s.previousRun(task, scheduledAt)
+
 	// 更新执行统计
 	now := time.Now()
 	task.LastRun = &now
@@ -214,21 +262,35 @@ func (s *SimpleScheduler) triggerTask(task *ScheduledTask) {
 		return
 	}
 
-	// 触发工作流执行
-	// 这里通过回调函数或者事件总线来触发工作流执行
-	// 目前先记录日志
-	log.Printf("Triggering workflow execution: %s (task: %s)", task.WorkflowID, task.ID)
-
-	// TODO: 实际触发工作流执行的逻辑
-	// 可以通过全局的WorkflowService来触发执行
-	if GlobalWorkflowService != nil {
-		go func() {
-			// 异步执行，避免阻塞调度器
-			// 这里需要创建执行记录并触发执行
-			log.Printf("Scheduled trigger for workflow: %s", task.WorkflowID)
-			// 实际的触发逻辑将在WorkflowService中实现
-		}()
+	if trigger == nil {
+		log.Printf("No trigger configured, skipping scheduled run of workflow %s", task.WorkflowID)
+		return
 	}
+
+	log.Printf("Triggering workflow execution: %s (task: %s, data interval: %s - %s)",
+		task.WorkflowID, task.ID, intervalStart.Format(time.RFC3339), scheduledAt.Format(time.RFC3339))
+
+	workflowID := task.WorkflowID
+	go func() {
+		// 异步执行，避免阻塞调度器
+		if err := trigger(workflowID, scheduledAt, intervalStart, scheduledAt); err != nil {
+			log.Printf("Failed to trigger scheduled execution for workflow %s: %v", workflowID, err)
+		}
+	}()
+}
+
+// previousRun 返回计划触发时间之前的上一次计划触发时间，作为数据区间开始：
+// cron 按调度时区的表达式向前推算，间隔调度为前一个间隔，一次性调度区间为空
+func (s *SimpleScheduler) previousRun(task *ScheduledTask, scheduledAt time.Time) time.Time {
+	switch {
+	case task.cron != nil:
+		if previous := task.cron.Prev(scheduledAt.In(task.location)); !previous.IsZero() {
+			return previous
+		}
+	case task.Schedule.Type == models.ScheduleTypeInterval:
+		return scheduledAt.Add(-task.Schedule.Interval)
+	}
+	return scheduledAt
 }
 
 // calculateNextRun 计算下次执行时间
@@ -242,14 +304,20 @@ func (s *SimpleScheduler) calculateNextRun(task *ScheduledTask) error {
 
 	switch task.Schedule.Type {
 	case models.ScheduleTypeCron:
-		// 简化的cron处理，仅支持基本的时间间隔
-		if task.Schedule.CronExpression == "" {
-			return fmt.Errorf("cron expression is empty")
+		if task.cron == nil {
+			return fmt.Errorf("cron expression is not parsed")
 		}
 
-		// 基本的cron表达式处理（简化版）
-		// 这里只是一个简单的示例，实际应该使用更完善的cron解析
-		nextRun = now.Add(time.Hour) // 默认1小时后执行
+		// 按调度时区匹配，开始时间之前不触发
+		from := now
+		if task.Schedule.StartTime != nil && task.Schedule.StartTime.After(now) {
+			from = task.Schedule.StartTime.Add(-time.Nanosecond)
+		}
+		nextRun = task.cron.Next(from.In(task.location))
+		if nextRun.IsZero() {
+			task.Enabled = false
+			return nil
+		}
 
 	case models.ScheduleTypeInterval:
 		if task.Schedule.Interval <= 0 {
@@ -261,6 +329,11 @@ func (s *SimpleScheduler) calculateNextRun(task *ScheduledTask) error {
 		if task.Schedule.ExecuteAt == nil {
 			return fmt.Errorf("execute_at is required for once schedule")
 		}
+		// 一次性调度触发后不再执行
+		if task.LastRun != nil {
+			task.Enabled = false
+			return nil
+		}
 		nextRun = *task.Schedule.ExecuteAt
 
 	case models.ScheduleTypeManual:
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"flow-service/service/models"
+)
+
+// scheduledRun 调度器触发的一次定时执行
+type scheduledRun struct {
+	workflowID    string
+	scheduledAt   time.Time
+	intervalStart time.Time
+	intervalEnd   time.Time
+}
+
+// newTestScheduler 创建未启动的调度器，触发的执行写入返回的通道
+func newTestScheduler() (*SimpleScheduler, chan scheduledRun) {
+	runs := make(chan scheduledRun, 4)
+	scheduler := NewSimpleScheduler()
+	scheduler.SetTrigger(func(workflowID string, scheduledAt, intervalStart, intervalEnd time.Time) error {
+		runs <- scheduledRun{workflowID, scheduledAt, intervalStart, intervalEnd}
+		return nil
+	})
+	return scheduler, runs
+}
+
+// fireDue 将任务的下次执行时间设为 plan 并触发到期任务
+func fireDue(t *testing.T, scheduler *SimpleScheduler, workflowID string, plan time.Time, runs chan scheduledRun) scheduledRun {
+	t.Helper()
+	task := scheduler.GetTasks()["task_"+workflowID]
+	task.mu.Lock()
+	task.NextRun = plan
+	task.mu.Unlock()
+
+	scheduler.checkAndTriggerTasks()
+	select {
+	case run := <-runs:
+		return run
+	case <-time.After(time.Second):
+		t.Fatal("scheduled execution was not triggered")
+		return scheduledRun{}
+	}
+}
+
+func TestSimpleSchedulerCronDataInterval(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scheduler, runs := newTestScheduler()
+	workflow := &models.Workflow{ID: "daily", Schedule: &models.WorkflowSchedule{
+		Type:           models.ScheduleTypeCron,
+		CronExpression: "0 2 * * *",
+		Timezone:       "Asia/Shanghai",
+		Enabled:        true,
+	}}
+	if err := scheduler.AddTask(workflow.ID, workflow); err != nil {
+		t.Fatalf("AddTask() error = %v", err)
+	}
+
+	// 下次执行为调度时区的 02:00
+	nextRun := scheduler.GetTasks()["task_daily"].NextRun.In(shanghai)
+	if nextRun.Hour() != 2 || nextRun.Minute() != 0 || !nextRun.After(time.Now()) {
+		t.Errorf("NextRun = %v, want a future 02:00 in Asia/Shanghai", nextRun)
+	}
+
+	plan := time.Date(2024, 5, 2, 2, 0, 0, 0, shanghai)
+	run := fireDue(t, scheduler, workflow.ID, plan, runs)
+	want := scheduledRun{workflowID: "daily", scheduledAt: plan, intervalStart: plan.AddDate(0, 0, -1), intervalEnd: plan}
+	if run.workflowID != want.workflowID || !run.scheduledAt.Equal(want.scheduledAt) ||
+		!run.intervalStart.Equal(want.intervalStart) || !run.intervalEnd.Equal(want.intervalEnd) {
+		t.Errorf("triggered run = %+v, want %+v", run, want)
+	}
+
+	// 逻辑日期为数据区间开始，ds 按调度时区取前一天
+	variables := executionDateVariables(&models.Execution{
+		LogicalDate:       &run.intervalStart,
+		DataIntervalStart: &run.intervalStart,
+		DataIntervalEnd:   &run.intervalEnd,
+	}, scheduleLocation(workflow))
+	if variables["ds"] != "2024-05-01" || variables["data_interval_end"] != "2024-05-02T02:00:00+08:00" {
+		t.Errorf("date variables = %v, want ds 2024-05-01 and interval end 2024-05-02T02:00:00+08:00", variables)
+	}
+}
+
+func TestSimpleSchedulerIntervalAndOnce(t *testing.T) {
+	scheduler, runs := newTestScheduler()
+	plan := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	interval := &models.Workflow{ID: "interval", Schedule: &models.WorkflowSchedule{
+		Type: models.ScheduleTypeInterval, Interval: 15 * time.Minute, Enabled: true,
+	}}
+	if err := scheduler.AddTask(interval.ID, interval); err != nil {
+		t.Fatalf("AddTask(interval) error = %v", err)
+	}
+	run := fireDue(t, scheduler, interval.ID, plan, runs)
+	if !run.intervalStart.Equal(plan.Add(-15*time.Minute)) || !run.intervalEnd.Equal(plan) {
+		t.Errorf("interval run = %+v, want data interval of 15m ending at %v", run, plan)
+	}
+	scheduler.RemoveTask(interval.ID)
+
+	executeAt := time.Now().Add(time.Hour)
+	once := &models.Workflow{ID: "once", Schedule: &models.WorkflowSchedule{
+		Type: models.ScheduleTypeOnce, ExecuteAt: &executeAt, Enabled: true,
+	}}
+	if err := scheduler.AddTask(once.ID, once); err != nil {
+		t.Fatalf("AddTask(once) error = %v", err)
+	}
+	fireDue(t, scheduler, once.ID, plan, runs)
+	if task := scheduler.GetTasks()["task_once"]; task.Enabled {
+		t.Error("once schedule is still enabled after it fired")
+	}
+
+	// 已过期的一次性调度加入时即停用
+	expired := time.Now().Add(-time.Hour)
+	once.Schedule.ExecuteAt = &expired
+	if err := scheduler.AddTask(once.ID, once); err != nil {
+		t.Fatalf("AddTask(expired once) error = %v", err)
+	}
+	if task := scheduler.GetTasks()["task_once"]; task.Enabled {
+		t.Error("expired once schedule is enabled")
+	}
+}
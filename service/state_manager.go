@@ -75,18 +75,38 @@ func (sm *StateManager) initializeTransitionRules() {
 	// 执行状态转换规则
 	sm.executionTransitions = map[models.ExecutionStatus][]models.ExecutionStatus{
 		models.ExecutionStatusPending: {
+			models.ExecutionStatusQueued,
 			models.ExecutionStatusRunning,
 			models.ExecutionStatusCancelled,
 		},
+		models.ExecutionStatusQueued: {
+			models.ExecutionStatusRunning,
+			models.ExecutionStatusFailed, // 准入时工作流已不存在
+			models.ExecutionStatusCancelled,
+		},
 		models.ExecutionStatusRunning: {
 			models.ExecutionStatusCompleted,
 			models.ExecutionStatusFailed,
 			models.ExecutionStatusCancelled,
+			models.ExecutionStatusPaused,
+			models.ExecutionStatusTimeout,
+		},
+		models.ExecutionStatusPaused: {
+			models.ExecutionStatusRunning,   // 恢复执行
+			models.ExecutionStatusCompleted, // 暂停时在途节点执行完毕
+			models.ExecutionStatusFailed,
+			models.ExecutionStatusCancelled,
+			models.ExecutionStatusTimeout,
 		},
 		models.ExecutionStatusFailed: {
 			models.ExecutionStatusPending, // 允许重试
 		},
-		models.ExecutionStatusCompleted: {}, // 终态
+		models.ExecutionStatusTimeout: {
+			models.ExecutionStatusPending, // 允许重试
+		},
+		models.ExecutionStatusCompleted: {
+			models.ExecutionStatusPending, // 允许从指定节点重新运行
+		},
 		models.ExecutionStatusCancelled: {}, // 终态
 	}
 }
@@ -13,11 +13,14 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"flow-service/service/config"
+	"flow-service/service/expression"
 	"flow-service/service/models"
 	"flow-service/service/nodes"
 )
@@ -29,6 +32,34 @@ const (
 	EngineStatusStopped EngineStatus = iota
 	EngineStatusRunning
 	EngineStatusStopping
+	EngineStatusDraining // 排空中：不再接收新执行，在途节点结束后执行保存检查点
+)
+
+// 执行错误码
+const (
+	ErrorCodeWorkflowFailed = "WORKFLOW_EXECUTION_FAILED" // 工作流执行失败
+	ErrorCodeNodeFailed     = "NODE_EXECUTION_FAILED"     // 节点执行失败
+	ErrorCodeInterrupted    = "EXECUTION_INTERRUPTED"     // 执行因服务重启中断
+	ErrorCodeTimeout        = "EXECUTION_TIMEOUT"         // 执行超过整体超时时间
+	ErrorCodeOutputFailed   = "OUTPUT_EVALUATION_FAILED"  // 工作流输出求值失败
+)
+
+// errExecutionStopped 执行已停止领取新节点（节点失败或排空），放弃等待槽位
+var errExecutionStopped = errors.New("execution stopped dispatching nodes")
+
+// ErrEngineSaturated 引擎并发执行数已达上限，执行需要排队等待
+var ErrEngineSaturated = errors.New("maximum concurrent executions reached")
+
+// ErrEngineDraining 引擎正在排空，不再接收新执行
+var ErrEngineDraining = errors.New("engine is draining for shutdown")
+
+// drainPollInterval 排空时检查执行是否全部结束的间隔
+const drainPollInterval = 100 * time.Millisecond
+
+// 重启恢复策略
+const (
+	RecoveryPolicyResume = "resume" // 从检查点继续执行
+	RecoveryPolicyFail   = "fail"   // 标记为失败
 )
 
 // WorkflowEngine 工作流执行引擎
@@ -38,6 +69,78 @@ type WorkflowEngine struct {
 	mu             sync.RWMutex
 	maxConcurrency int
 	nodeRegistry   *nodes.NodeRegistry
+	callback       ExecutionCallback
+	config         *config.EngineConfig
+	workerSlots    chan struct{}                      // 全局节点执行槽位，限制所有执行中同时运行的节点数
+	pools          *ConcurrencyPoolManager            // 命名并发池，限制访问同一资源的节点同时运行的数量
+	waiters        map[string][]chan *ExecutionResult // 等待执行结束的子流程节点，按执行ID索引
+	drainCh        chan struct{}                      // 开始排空时关闭，执行停止派发新节点
+}
+
+// ExecutionCallback 执行结果回调接口，由执行服务实现，用于持久化节点记录和最终状态
+type ExecutionCallback interface {
+	// SaveNodeRecord 保存节点执行记录
+	SaveNodeRecord(executionID string, record *models.ExecutionNodeRecord) error
+
+	// FinishExecution 保存执行最终状态
+	FinishExecution(executionID string, result *ExecutionResult) error
+
+	// SaveCheckpoint 保存编码后的执行检查点
+	SaveCheckpoint(executionID string, checkpoint string) error
+
+	// StartSubExecution 创建并启动子流程节点的子执行，返回子执行ID
+	StartSubExecution(request *SubExecutionRequest) (string, error)
+
+	// GetExecution 获取执行记录
+	GetExecution(executionID string) (*models.Execution, error)
+
+	// CancelExecution 取消执行
+	CancelExecution(executionID string) error
+
+	// ExecutionReleased 执行结束并释放并发槽位后调用，用于准入排队中的执行
+	ExecutionReleased(executionID string)
+}
+
+// SubExecutionRequest 子执行请求
+type SubExecutionRequest struct {
+	ParentExecutionID string                 `json:"parent_execution_id"`
+	ParentNodeID      string                 `json:"parent_node_id"`
+	WorkflowID        string                 `json:"workflow_id"`
+	Version           string                 `json:"version,omitempty"`
+	Input             map[string]interface{} `json:"input,omitempty"`
+}
+
+// EdgeState 运行时边状态，未出现在状态表中的边表示尚未决议
+type EdgeState string
+
+const (
+	EdgeStateTaken  EdgeState = "taken"  // 边已激活，目标节点可以执行
+	EdgeStatePruned EdgeState = "pruned" // 边被剪枝（条件不满足、源节点失败或被跳过）
+)
+
+// ExecutionResult 执行结果
+type ExecutionResult struct {
+	Status    models.ExecutionStatus   `json:"status"`
+	ErrorMsg  string                   `json:"error_msg,omitempty"`
+	ErrorCode string                   `json:"error_code,omitempty"`
+	Metrics   *models.ExecutionMetrics `json:"metrics,omitempty"`
+	Output    map[string]interface{}   `json:"output,omitempty"`
+}
+
+// NodeExecutionError 节点执行错误
+type NodeExecutionError struct {
+	NodeID string
+	Err    error
+}
+
+// Error 实现error接口
+func (e *NodeExecutionError) Error() string {
+	return fmt.Sprintf("node %s failed: %v", e.NodeID, e.Err)
+}
+
+// Unwrap 返回原始错误
+func (e *NodeExecutionError) Unwrap() error {
+	return e.Err
 }
 
 // ExecutionContext 执行上下文
@@ -47,13 +150,47 @@ type ExecutionContext struct {
 	Workflow    *models.Workflow
 	Execution   *models.Execution
 	Variables   map[string]interface{}
-	NodeStates  map[string]models.ExecutionStatus
+	NodeStates  map[string]models.NodeStatusEnum
 
 	// 新增字段用于依赖管理
-	NodeDependencies map[string][]string // 节点依赖关系
-	CompletedNodes   map[string]bool     // 已完成的节点
-	ExecutingNodes   map[string]bool     // 正在执行的节点
-	ReadyNodes       chan string         // 准备执行的节点队列
+	NodeDependencies map[string][]string               // 节点依赖关系
+	CompletedNodes   map[string]bool                   // 已完成的节点
+	ExecutingNodes   map[string]bool                   // 正在执行的节点
+	ReadyNodes       chan string                       // 准备执行的节点队列
+	EdgeStates       map[string]EdgeState              // 运行时边状态
+	ScheduledNodes   map[string]bool                   // 已入队或已跳过的节点
+	NodeFailures     map[string]map[string]interface{} // 节点失败详情
+	NodeOutputs      map[string]map[string]interface{} // 节点输出，按输出端口ID索引
+
+	// 节点执行记录
+	NodeRecords map[string]*models.ExecutionNodeRecord
+
+	// 检查点
+	checkpointDirty bool // 自上次保存后状态是否有变化
+
+	// 暂停控制
+	paused     bool                 // 暂停时不再派发新节点
+	resumeCh   chan struct{}        // 恢复执行时关闭
+	pausedAt   time.Time            // 最近一次暂停时间
+	resumedAt  time.Time            // 最近一次恢复时间
+	readyTimes map[string]time.Time // 节点进入准备队列的时间
+
+	// 延迟等待
+	wakeTimes map[string]time.Time   // 等待中节点的唤醒时间，写入检查点
+	timers    map[string]*time.Timer // 等待中节点的唤醒定时器
+
+	// 调度终止控制
+	pendingWork int           // 已入队但尚未处理完成的节点数
+	workDone    chan struct{} // 所有已入队节点处理完成时关闭
+	stopCh      chan struct{} // 关闭后工作协程不再领取新节点
+
+	// 子图执行（循环体等），与父上下文共享执行ID和执行记录
+	parent       *ExecutionContext // 父执行上下文，顶层执行为nil
+	recordSuffix string            // 子图节点执行记录ID后缀，如 "[0]"
+
+	result *ExecutionResult // 执行结束后的结果，通知等待的子流程节点
+
+	simulator *executionSimulator // 模拟运行状态，正式执行为nil
 
 	mu     sync.RWMutex
 	ctx    context.Context
@@ -62,14 +199,40 @@ type ExecutionContext struct {
 
 // NewWorkflowEngine 创建工作流执行引擎
 func NewWorkflowEngine() *WorkflowEngine {
+	return NewWorkflowEngineWithConfig(config.LoadEngineConfig())
+}
+
+// NewWorkflowEngineWithConfig 使用指定配置创建工作流执行引擎
+func NewWorkflowEngineWithConfig(cfg *config.EngineConfig) *WorkflowEngine {
+	if cfg == nil {
+		cfg = config.LoadEngineConfig()
+	}
+
+	workerCount := cfg.GetEffectiveWorkerCount()
+	if workerCount < 1 {
+		workerCount = 1
+	}
+
 	return &WorkflowEngine{
 		status:         EngineStatusStopped,
 		executions:     make(map[string]*ExecutionContext),
-		maxConcurrency: 10, // 最大并发执行数
+		maxConcurrency: cfg.Executor.MaxConcurrentDAGs, // 最大并发执行数
 		nodeRegistry:   nodes.GetRegistry(),
+		config:         cfg,
+		workerSlots:    make(chan struct{}, workerCount),
+		pools:          NewConcurrencyPoolManager(cfg.ConcurrencyPools),
+		waiters:        make(map[string][]chan *ExecutionResult),
+		drainCh:        make(chan struct{}),
 	}
 }
 
+// SetCallback 设置执行结果回调
+func (e *WorkflowEngine) SetCallback(callback ExecutionCallback) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.callback = callback
+}
+
 // Start 启动引擎
 func (e *WorkflowEngine) Start(ctx context.Context) error {
 	e.mu.Lock()
@@ -107,61 +270,227 @@ func (e *WorkflowEngine) Stop() error {
 	return nil
 }
 
-// ExecuteWorkflow 执行工作流
-func (e *WorkflowEngine) ExecuteWorkflow(ctx context.Context, workflow *models.Workflow, execution *models.Execution) error {
-	if e.status != EngineStatusRunning {
-		return fmt.Errorf("engine is not running")
-	}
+// GetConfig 获取引擎配置
+func (e *WorkflowEngine) GetConfig() *config.EngineConfig {
+	return e.config
+}
+
+// GetConcurrencyPoolUsage 获取各命名并发池的当前占用和上限
+func (e *WorkflowEngine) GetConcurrencyPoolUsage() []ConcurrencyPoolUsage {
+	return e.pools.Usage()
+}
 
-	// 检查并发限制
+// ExecuteWorkflow 执行工作流，执行记录带有检查点时从检查点继续执行
+func (e *WorkflowEngine) ExecuteWorkflow(ctx context.Context, workflow *models.Workflow, execution *models.Execution) error {
+	// 检查并发限制，子执行由等待中的父执行持有槽位，不受限制，避免父子执行互相等待
 	e.mu.RLock()
-	if len(e.executions) >= e.maxConcurrency {
+	if execution.ParentExecutionID == "" && e.admittedCount() >= e.maxConcurrency {
 		e.mu.RUnlock()
-		return fmt.Errorf("maximum concurrent executions reached: %d", e.maxConcurrency)
+		return fmt.Errorf("%w: %d", ErrEngineSaturated, e.maxConcurrency)
 	}
 	e.mu.RUnlock()
 
-	// 创建执行上下文
-	execCtx := &ExecutionContext{
-		ExecutionID:      execution.ID,
-		WorkflowID:       workflow.ID,
-		Workflow:         workflow,
-		Execution:        execution,
-		Variables:        make(map[string]interface{}),
-		NodeStates:       make(map[string]models.ExecutionStatus),
-		NodeDependencies: make(map[string][]string),
-		CompletedNodes:   make(map[string]bool),
-		ExecutingNodes:   make(map[string]bool),
-		ReadyNodes:       make(chan string, len(workflow.Nodes)),
+	checkpoint, err := execution.GetCheckpoint()
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoint: %w", err)
 	}
 
-	execCtx.ctx, execCtx.cancel = context.WithCancel(ctx)
+	return e.startExecution(ctx, workflow, execution, checkpoint)
+}
+
+// ResumeWorkflow 从执行记录中保存的检查点恢复执行，已结束的节点不再重复执行
+// 恢复的执行在重启前已被接纳，不受并发数限制
+func (e *WorkflowEngine) ResumeWorkflow(ctx context.Context, workflow *models.Workflow, execution *models.Execution) error {
+	checkpoint, err := execution.GetCheckpoint()
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	if checkpoint == nil {
+		// 尚未保存检查点，从头执行
+		checkpoint = &models.ExecutionCheckpoint{}
+	}
+
+	log.Printf("Resuming execution %s from checkpoint saved at %v", execution.ID, checkpoint.SavedAt)
+	return e.startExecution(ctx, workflow, execution, checkpoint)
+}
+
+// checkAcceptingExecutions 检查引擎是否接收新执行
+func (e *WorkflowEngine) checkAcceptingExecutions() error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.acceptingExecutionsLocked()
+}
+
+// acceptingExecutionsLocked 检查引擎是否接收新执行（调用方需持有锁）
+func (e *WorkflowEngine) acceptingExecutionsLocked() error {
+	switch e.status {
+	case EngineStatusRunning:
+		return nil
+	case EngineStatusDraining:
+		return ErrEngineDraining
+	default:
+		return fmt.Errorf("engine is not running")
+	}
+}
+
+// startExecution 创建执行上下文并异步执行，checkpoint 不为空时从检查点恢复
+func (e *WorkflowEngine) startExecution(ctx context.Context, workflow *models.Workflow, execution *models.Execution, checkpoint *models.ExecutionCheckpoint) error {
+	if err := e.checkAcceptingExecutions(); err != nil {
+		return err
+	}
 
-	// 注册执行上下文
+	// 创建执行上下文
+	execCtx := newExecutionContext(ctx, workflow, execution)
+	e.initExecutionContext(execCtx)
+
+	// 重启前已暂停的执行恢复后保持暂停
+	if execution.IsPaused() {
+		execCtx.paused = true
+		execCtx.pausedAt = time.Now()
+		execCtx.resumeCh = make(chan struct{})
+	}
+
+	// 注册执行上下文，与状态检查在同一把锁内完成，排空开始后不再注册新执行
 	e.mu.Lock()
+	if err := e.acceptingExecutionsLocked(); err != nil {
+		e.mu.Unlock()
+		execCtx.cancel()
+		return err
+	}
 	e.executions[execution.ID] = execCtx
 	e.mu.Unlock()
 
 	// 异步执行工作流
 	go func() {
 		defer func() {
-			// 清理执行上下文
+			// 清理执行上下文，通知等待该执行结束的子流程节点
 			e.mu.Lock()
 			delete(e.executions, execution.ID)
+			waiters := e.waiters[execution.ID]
+			delete(e.waiters, execution.ID)
 			e.mu.Unlock()
 
-			// 关闭通道
-			close(execCtx.ReadyNodes)
+			execCtx.mu.RLock()
+			result := execCtx.result
+			execCtx.mu.RUnlock()
+			for _, waiter := range waiters {
+				waiter <- result
+			}
+
+			execCtx.cancel()
+
+			if callback := e.getCallback(); callback != nil {
+				callback.ExecutionReleased(execution.ID)
+			}
 		}()
 
-		if err := e.executeWorkflowInternal(execCtx); err != nil {
+		// 按配置间隔持久化检查点
+		if e.config.Storage.StatePersistInterval > 0 {
+			go e.runCheckpointLoop(execCtx)
+		}
+
+		err := e.executeWorkflowInternal(execCtx, checkpoint)
+		if errors.Is(err, ErrEngineDraining) {
+			// 排空时不回写最终状态，保留运行状态和检查点，重启后按恢复策略处理
+			e.saveCheckpoint(execCtx)
+			log.Printf("Execution %s drained, checkpoint saved", execution.ID)
+			return
+		}
+		if err != nil {
 			log.Printf("Workflow execution failed: %v", err)
 		}
+
+		// 回写执行最终状态
+		e.finishExecution(execCtx, err)
 	}()
 
 	return nil
 }
 
+// initExecutionContext 初始化执行变量并设置执行整体超时
+func (e *WorkflowEngine) initExecutionContext(execCtx *ExecutionContext) {
+	workflow := execCtx.Workflow
+	execution := execCtx.Execution
+
+	// 初始化执行变量：工作流变量、执行变量、输入参数和日期宏，输入参数同时以 input 整体引用
+	if workflow.Config != nil {
+		for key, value := range workflow.Config.Variables {
+			execCtx.Variables[key] = value
+		}
+	}
+	if execution.Context != nil {
+		for key, value := range execution.Context.Variables {
+			execCtx.Variables[key] = value
+		}
+		for key, value := range execution.Context.Input {
+			execCtx.Variables[key] = value
+		}
+		if execution.Context.Input != nil {
+			execCtx.Variables["input"] = execution.Context.Input
+		}
+	}
+
+	// 逻辑日期和数据区间按调度时区提供为日期宏
+	for key, value := range executionDateVariables(execution, scheduleLocation(workflow)) {
+		execCtx.Variables[key] = value
+	}
+
+	// 整体超时从执行开始时计算，重启恢复后不重新计时
+	if timeout := e.getExecutionTimeout(workflow, execution); timeout > 0 {
+		startedAt := time.Now()
+		if execution.StartedAt != nil {
+			startedAt = *execution.StartedAt
+		}
+		deadlineCtx, cancelDeadline := context.WithDeadline(execCtx.ctx, startedAt.Add(timeout))
+		cancel := execCtx.cancel
+		execCtx.ctx = deadlineCtx
+		execCtx.cancel = func() {
+			cancelDeadline()
+			cancel()
+		}
+	}
+}
+
+// newExecutionContext 创建执行上下文
+func newExecutionContext(ctx context.Context, workflow *models.Workflow, execution *models.Execution) *ExecutionContext {
+	execCtx := &ExecutionContext{
+		ExecutionID:      execution.ID,
+		WorkflowID:       workflow.ID,
+		Workflow:         workflow,
+		Execution:        execution,
+		Variables:        make(map[string]interface{}),
+		NodeStates:       make(map[string]models.NodeStatusEnum),
+		NodeDependencies: make(map[string][]string),
+		CompletedNodes:   make(map[string]bool),
+		ExecutingNodes:   make(map[string]bool),
+		ReadyNodes:       make(chan string, len(workflow.Nodes)),
+		NodeRecords:      make(map[string]*models.ExecutionNodeRecord),
+		EdgeStates:       make(map[string]EdgeState),
+		ScheduledNodes:   make(map[string]bool),
+		NodeFailures:     make(map[string]map[string]interface{}),
+		NodeOutputs:      make(map[string]map[string]interface{}),
+		readyTimes:       make(map[string]time.Time),
+		wakeTimes:        make(map[string]time.Time),
+		timers:           make(map[string]*time.Timer),
+		workDone:         make(chan struct{}),
+		stopCh:           make(chan struct{}),
+	}
+
+	execCtx.ctx, execCtx.cancel = context.WithCancel(ctx)
+	return execCtx
+}
+
+// getExecutionTimeout 获取执行整体超时时间，触发时指定的超时优先于工作流配置，返回0表示不限制
+func (e *WorkflowEngine) getExecutionTimeout(workflow *models.Workflow, execution *models.Execution) time.Duration {
+	if execution.Timeout > 0 {
+		return execution.Timeout
+	}
+	if workflow.Config != nil && workflow.Config.Timeout > 0 {
+		return workflow.Config.Timeout
+	}
+	return 0
+}
+
 // CancelExecution 取消执行
 func (e *WorkflowEngine) CancelExecution(executionID string) error {
 	e.mu.RLock()
@@ -179,6 +508,86 @@ func (e *WorkflowEngine) CancelExecution(executionID string) error {
 	return nil
 }
 
+// PauseExecution 暂停执行：不再派发新节点，在途节点继续执行，准备队列保持不变
+func (e *WorkflowEngine) PauseExecution(executionID string) error {
+	e.mu.RLock()
+	execCtx, exists := e.executions[executionID]
+	e.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("execution not found: %s", executionID)
+	}
+
+	execCtx.mu.Lock()
+	defer execCtx.mu.Unlock()
+
+	if execCtx.paused {
+		return fmt.Errorf("execution is already paused: %s", executionID)
+	}
+
+	execCtx.paused = true
+	execCtx.pausedAt = time.Now()
+	execCtx.resumeCh = make(chan struct{})
+	execCtx.Execution.Status = models.ExecutionStatusPaused
+
+	log.Printf("Execution %s paused", executionID)
+	return nil
+}
+
+// ResumeExecution 恢复已暂停的执行，继续派发准备队列中的节点
+func (e *WorkflowEngine) ResumeExecution(executionID string) error {
+	e.mu.RLock()
+	execCtx, exists := e.executions[executionID]
+	e.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("execution not found: %s", executionID)
+	}
+
+	execCtx.mu.Lock()
+	defer execCtx.mu.Unlock()
+
+	if !execCtx.paused {
+		return fmt.Errorf("execution is not paused: %s", executionID)
+	}
+
+	execCtx.paused = false
+	execCtx.resumedAt = time.Now()
+	close(execCtx.resumeCh)
+	execCtx.Execution.Status = models.ExecutionStatusRunning
+
+	log.Printf("Execution %s resumed", executionID)
+	return nil
+}
+
+// waitWhilePaused 执行暂停时阻塞，返回false表示执行已取消或已停止派发
+func (e *WorkflowEngine) waitWhilePaused(execCtx *ExecutionContext) bool {
+	// 子图跟随顶层执行的暂停状态
+	root := execCtx
+	for root.parent != nil {
+		root = root.parent
+	}
+
+	for {
+		root.mu.RLock()
+		paused := root.paused
+		resumeCh := root.resumeCh
+		root.mu.RUnlock()
+
+		if !paused {
+			return true
+		}
+
+		select {
+		case <-resumeCh:
+		case <-execCtx.ctx.Done():
+			return false
+		case <-execCtx.stopCh:
+			return false
+		}
+	}
+}
+
 // GetExecutionStatus 获取执行状态
 func (e *WorkflowEngine) GetExecutionStatus(executionID string) (*ExecutionStatus, error) {
 	e.mu.RLock()
@@ -192,26 +601,35 @@ func (e *WorkflowEngine) GetExecutionStatus(executionID string) (*ExecutionStatu
 	execCtx.mu.RLock()
 	defer execCtx.mu.RUnlock()
 
+	nodeStates := make(map[string]models.NodeStatusEnum, len(execCtx.NodeStates))
+	for nodeID, state := range execCtx.NodeStates {
+		nodeStates[nodeID] = state
+	}
+	variables := make(map[string]interface{}, len(execCtx.Variables))
+	for key, value := range execCtx.Variables {
+		variables[key] = value
+	}
+
 	return &ExecutionStatus{
 		ExecutionID: executionID,
 		WorkflowID:  execCtx.WorkflowID,
 		Status:      execCtx.Execution.Status,
-		NodeStates:  execCtx.NodeStates,
-		Variables:   execCtx.Variables,
+		NodeStates:  nodeStates,
+		Variables:   variables,
 	}, nil
 }
 
 // ExecutionStatus 执行状态
 type ExecutionStatus struct {
-	ExecutionID string                            `json:"execution_id"`
-	WorkflowID  string                            `json:"workflow_id"`
-	Status      models.ExecutionStatus            `json:"status"`
-	NodeStates  map[string]models.ExecutionStatus `json:"node_states"`
-	Variables   map[string]interface{}            `json:"variables"`
+	ExecutionID string                           `json:"execution_id"`
+	WorkflowID  string                           `json:"workflow_id"`
+	Status      models.ExecutionStatus           `json:"status"`
+	NodeStates  map[string]models.NodeStatusEnum `json:"node_states"`
+	Variables   map[string]interface{}           `json:"variables"`
 }
 
 // executeWorkflowInternal 内部执行工作流
-func (e *WorkflowEngine) executeWorkflowInternal(execCtx *ExecutionContext) error {
+func (e *WorkflowEngine) executeWorkflowInternal(execCtx *ExecutionContext, checkpoint *models.ExecutionCheckpoint) error {
 	// 检查工作流节点
 	if execCtx.Workflow.Nodes == nil || len(execCtx.Workflow.Nodes) == 0 {
 		return fmt.Errorf("workflow has no nodes")
@@ -230,13 +648,20 @@ func (e *WorkflowEngine) executeWorkflowInternal(execCtx *ExecutionContext) erro
 		return fmt.Errorf("no start nodes found in workflow")
 	}
 
+	if checkpoint != nil {
+		// 从检查点恢复已结束节点，重新调度其余节点
+		e.restoreCheckpoint(execCtx, checkpoint)
+		e.scheduleResumedNodes(execCtx, startNodes)
+		return e.executeDAG(execCtx)
+	}
+
 	// 将起始节点加入准备队列
 	for _, nodeID := range startNodes {
-		select {
-		case execCtx.ReadyNodes <- nodeID:
-		case <-execCtx.ctx.Done():
-			return execCtx.ctx.Err()
-		}
+		execCtx.mu.Lock()
+		execCtx.ScheduledNodes[nodeID] = true
+		execCtx.mu.Unlock()
+
+		e.enqueueNode(execCtx, nodeID)
 	}
 
 	// 执行工作流
@@ -245,10 +670,21 @@ func (e *WorkflowEngine) executeWorkflowInternal(execCtx *ExecutionContext) erro
 
 // buildDependencyGraph 构建依赖关系图
 func (e *WorkflowEngine) buildDependencyGraph(execCtx *ExecutionContext) error {
+	// 执行已登记，状态查询可能同时读取节点状态
+	execCtx.mu.Lock()
+	defer execCtx.mu.Unlock()
+
 	// 初始化所有节点的依赖列表
 	for nodeID := range execCtx.Workflow.Nodes {
 		execCtx.NodeDependencies[nodeID] = []string{}
-		execCtx.NodeStates[nodeID] = models.ExecutionStatusPending
+		execCtx.NodeStates[nodeID] = models.NodeStatusPending
+	}
+
+	// 循环体节点由循环节点在子图中调度，不参与主图调度
+	bodyNodes := e.findLoopBodyNodes(execCtx.Workflow)
+	for nodeID := range bodyNodes {
+		delete(execCtx.NodeDependencies, nodeID)
+		execCtx.ScheduledNodes[nodeID] = true
 	}
 
 	// 根据边构建依赖关系
@@ -258,6 +694,9 @@ func (e *WorkflowEngine) buildDependencyGraph(execCtx *ExecutionContext) error {
 			if !edge.IsEnabled() {
 				continue
 			}
+			if bodyNodes[edge.ToNodeID] {
+				continue
+			}
 
 			toNodeID := edge.ToNodeID
 			fromNodeID := edge.FromNodeID
@@ -301,7 +740,8 @@ func (e *WorkflowEngine) executeDAG(execCtx *ExecutionContext) error {
 	errorChan := make(chan error, len(execCtx.Workflow.Nodes))
 
 	// 启动节点执行协程
-	for i := 0; i < 3; i++ { // 最多3个并发执行节点
+	workerCount := e.getNodeWorkerCount(execCtx.Workflow)
+	for i := 0; i < workerCount; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -309,182 +749,881 @@ func (e *WorkflowEngine) executeDAG(execCtx *ExecutionContext) error {
 		}()
 	}
 
-	// 等待所有节点执行完成
-	go func() {
-		wg.Wait()
-		close(errorChan)
-	}()
+	// 顶层执行在引擎排空时停止派发，子图随所属控制节点执行完毕
+	var drainCh <-chan struct{}
+	if e.drainable(execCtx) {
+		drainCh = e.drainCh
+	}
 
-	// 检查执行结果
-	for err := range errorChan {
-		if err != nil {
-			return err
+	// 等待所有节点处理完成、出现未处理的节点错误、执行被取消或引擎开始排空
+	var err error
+	select {
+	case err = <-errorChan:
+	case <-execCtx.workDone:
+	case <-execCtx.ctx.Done():
+		err = execCtx.ctx.Err()
+	case <-drainCh:
+		err = ErrEngineDraining
+	}
+
+	// 停止领取新节点，等待正在执行的节点结束
+	close(execCtx.stopCh)
+	wg.Wait()
+	e.stopWakeTimers(execCtx)
+
+	if err == nil {
+		// 最后一个节点失败时错误与完成信号可能同时就绪
+		select {
+		case err = <-errorChan:
+		default:
 		}
 	}
+	if err != nil {
+		return err
+	}
+
+	// 所有可执行节点都已处理，仍未决议的节点说明图中存在环
+	if stalled := e.findUnresolvedNodes(execCtx); len(stalled) > 0 {
+		return fmt.Errorf("workflow stalled, nodes never became ready: %v", stalled)
+	}
 
 	log.Printf("Workflow execution completed: %s", execCtx.ExecutionID)
 	return nil
 }
 
-// nodeExecutorWorker 节点执行工作协程
-func (e *WorkflowEngine) nodeExecutorWorker(execCtx *ExecutionContext, errorChan chan<- error) {
-	for {
-		select {
-		case <-execCtx.ctx.Done():
-			return
-		case nodeID, ok := <-execCtx.ReadyNodes:
-			if !ok {
-				return // 通道已关闭
-			}
-
-			// 检查节点是否已经在执行
-			execCtx.mu.Lock()
-			if execCtx.ExecutingNodes[nodeID] {
-				execCtx.mu.Unlock()
-				continue
-			}
-			execCtx.ExecutingNodes[nodeID] = true
-			execCtx.mu.Unlock()
-
-			// 执行节点
-			node := execCtx.Workflow.Nodes[nodeID]
-			if err := e.executeNode(execCtx, nodeID, node); err != nil {
-				log.Printf("Node execution failed: %s, error: %v", nodeID, err)
+// getNodeWorkerCount 计算单次执行的节点工作协程数
+func (e *WorkflowEngine) getNodeWorkerCount(workflow *models.Workflow) int {
+	maxTasks := e.config.Executor.MaxConcurrentTasks
 
-				// 更新节点状态为失败
-				execCtx.mu.Lock()
-				execCtx.NodeStates[nodeID] = models.ExecutionStatusFailed
-				delete(execCtx.ExecutingNodes, nodeID)
-				execCtx.mu.Unlock()
+	count := maxTasks
+	if workflow.Config != nil && workflow.Config.MaxConcurrency > 0 {
+		count = workflow.Config.MaxConcurrency
+	}
+	if maxTasks > 0 && count > maxTasks {
+		count = maxTasks
+	}
+	if count > len(workflow.Nodes) {
+		count = len(workflow.Nodes)
+	}
+	if count < 1 {
+		count = 1
+	}
 
-				errorChan <- fmt.Errorf("node %s failed: %w", nodeID, err)
-				return
-			}
+	return count
+}
 
-			// 节点执行成功
-			execCtx.mu.Lock()
-			execCtx.NodeStates[nodeID] = models.ExecutionStatusCompleted
-			execCtx.CompletedNodes[nodeID] = true
-			delete(execCtx.ExecutingNodes, nodeID)
-			execCtx.mu.Unlock()
+// findUnresolvedNodes 查找从未被调度或跳过的节点
+func (e *WorkflowEngine) findUnresolvedNodes(execCtx *ExecutionContext) []string {
+	execCtx.mu.RLock()
+	defer execCtx.mu.RUnlock()
 
-			// 检查并激活下游节点
-			e.activateDownstreamNodes(execCtx, nodeID)
+	var unresolved []string
+	for nodeID := range execCtx.Workflow.Nodes {
+		if !execCtx.ScheduledNodes[nodeID] {
+			unresolved = append(unresolved, nodeID)
 		}
 	}
+	return unresolved
 }
 
-// executeNode 执行节点 - 重构为使用节点插件系统
-func (e *WorkflowEngine) executeNode(execCtx *ExecutionContext, nodeID string, node *models.Node) error {
-	log.Printf("Executing node: %s (type: %s, plugin: %s)", nodeID, node.Type, node.Plugin)
-
-	// 更新节点状态为运行中
+// enqueueNode 将节点加入准备队列并登记待处理工作
+func (e *WorkflowEngine) enqueueNode(execCtx *ExecutionContext, nodeID string) {
 	execCtx.mu.Lock()
-	execCtx.NodeStates[nodeID] = models.ExecutionStatusRunning
+	execCtx.pendingWork++
+	execCtx.readyTimes[nodeID] = time.Now()
 	execCtx.mu.Unlock()
 
-	// 获取节点执行超时时间
-	timeout := node.GetExecutionTimeout()
-	if timeout == 0 {
-		timeout = time.Second * 30 // 默认30秒超时
-	}
+	// 每个节点只入队一次，通道容量等于节点数，不会阻塞
+	execCtx.ReadyNodes <- nodeID
+}
 
-	// 创建节点执行上下文
-	nodeCtx, cancel := context.WithTimeout(execCtx.ctx, timeout)
-	defer cancel()
+// finishWork 节点处理完成（含下游决议）后注销待处理工作
+func (e *WorkflowEngine) finishWork(execCtx *ExecutionContext) {
+	execCtx.mu.Lock()
+	defer execCtx.mu.Unlock()
 
-	// 准备输入数据
-	inputData := e.prepareNodeInput(execCtx, nodeID, node)
+	execCtx.pendingWork--
+	if execCtx.pendingWork == 0 {
+		close(execCtx.workDone)
+	}
+}
 
-	// 通过节点注册系统获取节点插件
+// acquireWorkerSlot 获取全局节点执行槽位，上下文取消或 stop 关闭时放弃等待
+func (e *WorkflowEngine) acquireWorkerSlot(ctx context.Context, stop <-chan struct{}) error {
+	select {
+	case e.workerSlots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-stop:
+		return errExecutionStopped
+	}
+}
+
+// releaseWorkerSlot 释放全局节点执行槽位
+func (e *WorkflowEngine) releaseWorkerSlot() {
+	<-e.workerSlots
+}
+
+// nodeExecutorWorker 节点执行工作协程
+func (e *WorkflowEngine) nodeExecutorWorker(execCtx *ExecutionContext, errorChan chan<- error) {
+	for {
+		// 已停止时优先退出，避免与就绪节点竞争
+		select {
+		case <-execCtx.stopCh:
+			return
+		default:
+		}
+
+		// 暂停期间不领取新节点，准备队列保持不变
+		if !e.waitWhilePaused(execCtx) {
+			return
+		}
+
+		select {
+		case <-execCtx.ctx.Done():
+			return
+		case <-execCtx.stopCh:
+			return
+		case nodeID := <-execCtx.ReadyNodes:
+			// 空闲协程可能在暂停前已等在队列上，领取后再次检查，停止时放回准备队列
+			if !e.waitWhilePaused(execCtx) {
+				execCtx.ReadyNodes <- nodeID
+				return
+			}
+			e.processReadyNode(execCtx, nodeID, errorChan)
+		}
+	}
+}
+
+// processReadyNode 执行就绪节点并决议其下游边
+func (e *WorkflowEngine) processReadyNode(execCtx *ExecutionContext, nodeID string, errorChan chan<- error) {
+	defer e.finishWork(execCtx)
+
+	// 边延迟和延迟/定时器节点等待期间不占用工作协程，唤醒后重新入队
+	node := execCtx.Workflow.Nodes[nodeID]
+	if e.deferUntilWake(execCtx, nodeID, node) {
+		return
+	}
+
+	// 控制节点只负责调度子图，不占用执行槽位，避免与子图节点争用槽位而死锁
+	if !isControlNode(node) {
+		// 先获取并发池槽位再获取全局槽位，等待资源期间不占用全局槽位
+		release, err := e.pools.Acquire(execCtx.ctx, execCtx.stopCh, execCtx.Workflow.ID, nodeID, node)
+		if err != nil {
+			if execCtx.ctx.Err() != nil || errors.Is(err, errExecutionStopped) {
+				return
+			}
+			e.failNode(execCtx, nodeID, fmt.Errorf("failed to acquire concurrency pool: %w", err), errorChan)
+			return
+		}
+		defer release()
+
+		if err := e.acquireWorkerSlot(execCtx.ctx, execCtx.stopCh); err != nil {
+			return
+		}
+		defer e.releaseWorkerSlot()
+
+		// 等待槽位期间 DAG 已因节点失败停止时不再执行
+		select {
+		case <-execCtx.stopCh:
+			return
+		default:
+		}
+
+		// 等待槽位期间引擎开始排空时不再执行，恢复后重新调度
+		if e.drainable(execCtx) && e.IsDraining() {
+			return
+		}
+	}
+
+	execCtx.mu.Lock()
+	execCtx.ExecutingNodes[nodeID] = true
+	execCtx.mu.Unlock()
+
+	// 执行节点
+	if err := e.executeNode(execCtx, nodeID, node); err != nil {
+		// 执行被取消或超时时节点视为取消，不再上报节点错误，由取消流程决定最终状态
+		if execCtx.ctx.Err() != nil {
+			log.Printf("Node execution interrupted: %s, error: %v", nodeID, err)
+			e.cancelNode(execCtx, nodeID, execCtx.ctx.Err())
+			return
+		}
+
+		// 引擎排空导致节点无法完成（如无法启动子执行）时节点视为取消，恢复后重新执行
+		if errors.Is(err, ErrEngineDraining) {
+			log.Printf("Node execution interrupted by drain: %s", nodeID)
+			e.cancelNode(execCtx, nodeID, err)
+			return
+		}
+
+		e.failNode(execCtx, nodeID, err, errorChan)
+		return
+	}
+
+	// 节点执行成功
+	execCtx.mu.Lock()
+	execCtx.NodeStates[nodeID] = models.NodeStatusCompleted
+	execCtx.CompletedNodes[nodeID] = true
+	delete(execCtx.ExecutingNodes, nodeID)
+	execCtx.mu.Unlock()
+
+	// 检查并激活下游节点
+	e.resolveOutgoingEdges(execCtx, nodeID, models.NodeStatusCompleted, nil)
+	e.markCheckpointDirty(execCtx)
+}
+
+// failNode 将节点标记为失败，存在错误/超时处理路径时继续执行，否则终止整个DAG
+func (e *WorkflowEngine) failNode(execCtx *ExecutionContext, nodeID string, err error, errorChan chan<- error) {
+	log.Printf("Node execution failed: %s, error: %v", nodeID, err)
+
+	// 更新节点状态为失败
+	execCtx.mu.Lock()
+	execCtx.NodeStates[nodeID] = models.NodeStatusFailed
+	delete(execCtx.ExecutingNodes, nodeID)
+	execCtx.mu.Unlock()
+
+	if !e.activateFailureEdges(execCtx, nodeID, err) {
+		errorChan <- &NodeExecutionError{NodeID: nodeID, Err: err}
+		return
+	}
+	e.markCheckpointDirty(execCtx)
+}
+
+// cancelNode 将被取消或超时中断的节点及其执行记录标记为取消
+func (e *WorkflowEngine) cancelNode(execCtx *ExecutionContext, nodeID string, reason error) {
+	execCtx.mu.Lock()
+	execCtx.NodeStates[nodeID] = models.NodeStatusCancelled
+	delete(execCtx.ExecutingNodes, nodeID)
+	record, exists := execCtx.NodeRecords[nodeID]
+	if !exists {
+		execCtx.mu.Unlock()
+		return
+	}
+	record.Status = models.ExecutionStatusCancelled
+	record.ErrorMsg = reason.Error()
+	snapshot := *record
+	execCtx.mu.Unlock()
+
+	e.saveNodeRecord(execCtx, &snapshot)
+}
+
+// cancelPendingNodes 执行被取消或超时后，将尚未结束的节点标记为取消（调用方需持有锁）
+func (e *WorkflowEngine) cancelPendingNodes(execCtx *ExecutionContext) {
+	for nodeID, state := range execCtx.NodeStates {
+		switch state {
+		case models.NodeStatusPending, models.NodeStatusRunning, models.NodeStatusRetrying:
+			execCtx.NodeStates[nodeID] = models.NodeStatusCancelled
+		}
+	}
+}
+
+// executeNode 执行节点 - 重构为使用节点插件系统，按节点重试配置进行重试
+func (e *WorkflowEngine) executeNode(execCtx *ExecutionContext, nodeID string, node *models.Node) error {
+	log.Printf("Executing node: %s (type: %s, plugin: %s)", nodeID, node.Type, node.Plugin)
+
+	// 更新节点状态为运行中
+	e.setNodeState(execCtx, nodeID, models.NodeStatusRunning)
+
+	// 获取节点执行超时时间
+	timeout := e.getNodeTimeout(node)
+
+	// 准备输入数据
+	inputData, err := e.prepareNodeInput(execCtx, nodeID, node)
+
+	// 记录节点开始执行
+	e.startNodeRecord(execCtx, nodeID, node, inputData)
+	if err != nil {
+		err = fmt.Errorf("failed to prepare node input: %w", err)
+		e.finishNodeRecord(execCtx, nodeID, nil, err)
+		return err
+	}
+
+	// 渲染插件配置中的 {{ }} 模板
+	node, err = e.renderNodeConfig(execCtx.ctx, execCtx, node)
+	if err != nil {
+		err = fmt.Errorf("failed to render node config: %w", err)
+		e.finishNodeRecord(execCtx, nodeID, nil, err)
+		return err
+	}
+
+	// 未配置重试时只执行一次
+	var retryConfig *models.NodeRetryConfig
+	if node.Config != nil {
+		retryConfig = node.Config.RetryConfig
+	}
+	maxRetries := 0
+	if retryConfig != nil {
+		maxRetries = retryConfig.MaxRetries
+	}
+
+	var nodeOutput *nodes.NodeOutput
+	for attempt := 0; ; attempt++ {
+		// 每次尝试使用独立的超时上下文
+		nodeCtx, cancel := context.WithCancel(execCtx.ctx)
+		if timeout > 0 {
+			nodeCtx, cancel = context.WithTimeout(execCtx.ctx, timeout)
+		}
+		nodeOutput, err = e.runNode(nodeCtx, execCtx, nodeID, node, inputData)
+		if err != nil && nodeCtx.Err() == context.DeadlineExceeded && !errors.Is(err, context.DeadlineExceeded) {
+			// 插件未透传超时错误时补充超时标记，便于路由到超时处理边
+			err = fmt.Errorf("%w: %v", context.DeadlineExceeded, err)
+		}
+		cancel()
+
+		if err == nil || attempt >= maxRetries || execCtx.ctx.Err() != nil {
+			break
+		}
+
+		// 检查重试条件和停止条件
+		if !retryConfig.ShouldRetry(err.Error()) {
+			log.Printf("Node %s error does not match retry conditions, giving up: %v", nodeID, err)
+			break
+		}
+
+		delay := retryConfig.GetRetryDelay(attempt + 1)
+		log.Printf("Node %s failed (attempt %d/%d), retrying in %v: %v", nodeID, attempt+1, maxRetries+1, delay, err)
+		e.markNodeRetrying(execCtx, nodeID, attempt+1, err)
+
+		// 等待退避时间，执行被取消时立即退出
+		timer := time.NewTimer(delay)
+		select {
+		case <-execCtx.ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if execCtx.ctx.Err() != nil {
+			break
+		}
+
+		e.setNodeState(execCtx, nodeID, models.NodeStatusRunning)
+	}
+
+	// 记录节点执行结果
+	e.finishNodeRecord(execCtx, nodeID, nodeOutput, err)
+
+	if err != nil {
+		return err
+	}
+
+	// 处理输出数据
+	e.processNodeOutput(execCtx, nodeID, nodeOutput.Data)
+
+	log.Printf("Node %s executed successfully", nodeID)
+	return nil
+}
+
+// getNodeTimeout 获取节点单次执行超时时间，控制节点未显式配置时不限制，返回0表示不设超时
+func (e *WorkflowEngine) getNodeTimeout(node *models.Node) time.Duration {
+	if isControlNode(node) && (node.Config == nil || node.Config.TimeoutConfig == nil || node.Config.TimeoutConfig.ExecutionTimeout <= 0) {
+		return 0
+	}
+
+	timeout := node.GetExecutionTimeout()
+	if timeout == 0 {
+		timeout = time.Second * 30 // 默认30秒超时
+	}
+	return timeout
+}
+
+// runNode 执行节点，控制节点由引擎直接执行，其余节点调用节点插件
+func (e *WorkflowEngine) runNode(ctx context.Context, execCtx *ExecutionContext, nodeID string, node *models.Node, inputData map[string]interface{}) (*nodes.NodeOutput, error) {
+	if execCtx.simulator != nil {
+		if output, simulated := execCtx.simulator.simulateNode(e.nodeRegistry, nodeID, node, inputData); simulated {
+			return output, nil
+		}
+	}
+
+	switch node.Type {
+	case models.NodeTypeLoop:
+		return e.executeLoopNode(ctx, execCtx, nodeID, node, inputData)
+	case models.NodeTypeCondition:
+		return e.executeConditionNode(ctx, execCtx, node, inputData)
+	case models.NodeTypeSubDAG:
+		return e.executeSubWorkflowNode(ctx, execCtx, nodeID, node, inputData)
+	case models.NodeTypeDelay, models.NodeTypeTimer:
+		return e.executeWaitNode(execCtx, nodeID, inputData)
+	default:
+		return e.runNodePlugin(ctx, execCtx, node, inputData)
+	}
+}
+
+// setNodeState 更新节点状态
+func (e *WorkflowEngine) setNodeState(execCtx *ExecutionContext, nodeID string, state models.NodeStatusEnum) {
+	execCtx.mu.Lock()
+	execCtx.NodeStates[nodeID] = state
+	execCtx.mu.Unlock()
+}
+
+// markNodeRetrying 标记节点进入重试状态并记录重试次数
+func (e *WorkflowEngine) markNodeRetrying(execCtx *ExecutionContext, nodeID string, retryCount int, lastErr error) {
+	execCtx.mu.Lock()
+	execCtx.NodeStates[nodeID] = models.NodeStatusRetrying
+	record, exists := execCtx.NodeRecords[nodeID]
+	if !exists {
+		execCtx.mu.Unlock()
+		return
+	}
+	record.RetryCount = retryCount
+	record.ErrorMsg = lastErr.Error()
+	record.Logs = append(record.Logs, fmt.Sprintf("第 %d 次执行失败，准备重试: %v", retryCount, lastErr))
+	snapshot := *record
+	execCtx.mu.Unlock()
+
+	e.saveNodeRecord(execCtx, &snapshot)
+}
+
+// runNodePlugin 通过节点注册系统调用节点插件
+func (e *WorkflowEngine) runNodePlugin(ctx context.Context, execCtx *ExecutionContext, node *models.Node, inputData map[string]interface{}) (*nodes.NodeOutput, error) {
+	// 通过节点注册系统获取节点插件
 	nodePlugin, err := e.nodeRegistry.Get(node.Plugin)
 	if err != nil {
-		return fmt.Errorf("failed to get node plugin %s: %w", node.Plugin, err)
+		return nil, fmt.Errorf("failed to get node plugin %s: %w", node.Plugin, err)
+	}
+
+	var pluginConfig map[string]interface{}
+	if node.Config != nil {
+		pluginConfig = node.Config.PluginConfig
 	}
 
 	// 准备节点输入
 	nodeInput := &nodes.NodeInput{
 		Data:      inputData,
-		Config:    node.Config.PluginConfig,
+		Config:    pluginConfig,
 		Context:   make(map[string]interface{}),
 		Variables: execCtx.Variables,
 	}
 
 	// 执行节点
-	nodeOutput, err := nodePlugin.Execute(nodeCtx, nodeInput)
+	nodeOutput, err := nodePlugin.Execute(ctx, nodeInput)
 	if err != nil {
-		return fmt.Errorf("node plugin execution failed: %w", err)
+		return nodeOutput, fmt.Errorf("node plugin execution failed: %w", err)
+	}
+
+	// 检查执行结果
+	if nodeOutput == nil {
+		return nil, fmt.Errorf("node plugin returned nil output")
+	}
+
+	if !nodeOutput.Success {
+		return nodeOutput, fmt.Errorf("node execution failed: %s", nodeOutput.Error)
+	}
+
+	return nodeOutput, nil
+}
+
+// startNodeRecord 创建节点执行记录
+func (e *WorkflowEngine) startNodeRecord(execCtx *ExecutionContext, nodeID string, node *models.Node, inputData map[string]interface{}) {
+	now := time.Now()
+	record := &models.ExecutionNodeRecord{
+		NodeID:    nodeID + execCtx.recordSuffix,
+		NodeName:  node.Name,
+		Status:    models.ExecutionStatusRunning,
+		StartTime: &now,
+		Input:     inputData,
+	}
+
+	execCtx.mu.Lock()
+	// 节点就绪后经历过暂停时记录暂停和恢复时间
+	if readyAt, exists := execCtx.readyTimes[nodeID]; exists && !execCtx.resumedAt.IsZero() && execCtx.resumedAt.After(readyAt) {
+		pausedAt := execCtx.pausedAt
+		if pausedAt.Before(readyAt) {
+			pausedAt = readyAt
+		}
+		resumedAt := execCtx.resumedAt
+		record.PausedAt = &pausedAt
+		record.ResumedAt = &resumedAt
+	}
+	execCtx.NodeRecords[nodeID] = record
+	snapshot := *record
+	execCtx.mu.Unlock()
+
+	e.saveNodeRecord(execCtx, &snapshot)
+}
+
+// finishNodeRecord 完成节点执行记录
+func (e *WorkflowEngine) finishNodeRecord(execCtx *ExecutionContext, nodeID string, nodeOutput *nodes.NodeOutput, execErr error) {
+	now := time.Now()
+
+	execCtx.mu.Lock()
+	record, exists := execCtx.NodeRecords[nodeID]
+	if !exists {
+		execCtx.mu.Unlock()
+		return
+	}
+
+	record.EndTime = &now
+	if record.StartTime != nil {
+		record.Duration = now.Sub(*record.StartTime)
+	}
+
+	if nodeOutput != nil {
+		record.Output = nodeOutput.Data
+		record.Logs = append(record.Logs, nodeOutput.Logs...)
+		record.Metrics = nodeOutput.Metrics
+	}
+
+	if execErr != nil {
+		record.Status = models.ExecutionStatusFailed
+		record.ErrorMsg = execErr.Error()
+	} else {
+		record.Status = models.ExecutionStatusCompleted
+		record.ErrorMsg = ""
+	}
+	snapshot := *record
+	execCtx.mu.Unlock()
+
+	e.saveNodeRecord(execCtx, &snapshot)
+}
+
+// skipNodeRecord 为被跳过的节点写入执行记录，使执行指标和进度能统计到该节点
+func (e *WorkflowEngine) skipNodeRecord(execCtx *ExecutionContext, nodeID string) {
+	now := time.Now()
+	record := &models.ExecutionNodeRecord{
+		NodeID:  nodeID + execCtx.recordSuffix,
+		Status:  models.ExecutionStatusSkipped,
+		EndTime: &now,
+	}
+	if node, exists := execCtx.Workflow.Nodes[nodeID]; exists {
+		record.NodeName = node.Name
+	}
+
+	execCtx.mu.Lock()
+	execCtx.NodeRecords[nodeID] = record
+	snapshot := *record
+	execCtx.mu.Unlock()
+
+	e.saveNodeRecord(execCtx, &snapshot)
+}
+
+// saveNodeRecord 通过回调持久化节点执行记录
+func (e *WorkflowEngine) saveNodeRecord(execCtx *ExecutionContext, record *models.ExecutionNodeRecord) {
+	callback := e.callbackFor(execCtx)
+	if callback == nil {
+		return
+	}
+
+	if err := callback.SaveNodeRecord(execCtx.ExecutionID, record); err != nil {
+		log.Printf("Failed to save node record %s for execution %s: %v", record.NodeID, execCtx.ExecutionID, err)
+	}
+}
+
+// finishExecution 汇总执行结果并通过回调持久化最终状态
+func (e *WorkflowEngine) finishExecution(execCtx *ExecutionContext, execErr error) {
+	result := &ExecutionResult{
+		Status: models.ExecutionStatusCompleted,
+	}
+
+	switch {
+	case execCtx.ctx.Err() == context.Canceled:
+		result.Status = models.ExecutionStatusCancelled
+	case execCtx.ctx.Err() == context.DeadlineExceeded:
+		result.Status = models.ExecutionStatusTimeout
+		result.ErrorMsg = fmt.Sprintf("execution exceeded timeout of %v", e.getExecutionTimeout(execCtx.Workflow, execCtx.Execution))
+		result.ErrorCode = ErrorCodeTimeout
+	case execErr != nil:
+		result.Status = models.ExecutionStatusFailed
+		result.ErrorMsg = execErr.Error()
+		result.ErrorCode = ErrorCodeWorkflowFailed
+
+		var nodeErr *NodeExecutionError
+		if errors.As(execErr, &nodeErr) {
+			result.ErrorCode = ErrorCodeNodeFailed
+		}
+	}
+
+	execCtx.mu.Lock()
+	if execCtx.ctx.Err() != nil {
+		e.cancelPendingNodes(execCtx)
+	}
+	result.Metrics = e.collectExecutionMetrics(execCtx)
+	switch {
+	case len(execCtx.Workflow.Outputs) == 0:
+		result.Output = e.collectExecutionOutput(execCtx)
+	case result.Status == models.ExecutionStatusCompleted:
+		// 声明了输出的工作流只在执行完成时求值输出，求值失败视为执行失败
+		output, err := e.evaluateWorkflowOutputs(execCtx)
+		if err != nil {
+			result.Status = models.ExecutionStatusFailed
+			result.ErrorMsg = err.Error()
+			result.ErrorCode = ErrorCodeOutputFailed
+		} else {
+			result.Output = output
+		}
+	}
+	execCtx.Execution.Status = result.Status
+	execCtx.result = result
+	execCtx.mu.Unlock()
+
+	// 保存最终检查点，供从失败节点重试或从指定节点重新运行
+	e.saveCheckpoint(execCtx)
+
+	log.Printf("Execution %s finished with status: %s", execCtx.ExecutionID, result.Status)
+
+	callback := e.callbackFor(execCtx)
+	if callback == nil {
+		return
+	}
+
+	if err := callback.FinishExecution(execCtx.ExecutionID, result); err != nil {
+		log.Printf("Failed to save execution result %s: %v", execCtx.ExecutionID, err)
+	}
+}
+
+// collectExecutionMetrics 根据节点状态统计执行指标（调用方需持有锁）
+func (e *WorkflowEngine) collectExecutionMetrics(execCtx *ExecutionContext) *models.ExecutionMetrics {
+	metrics := &models.ExecutionMetrics{
+		TotalNodes: len(execCtx.Workflow.Nodes),
+	}
+
+	for _, status := range execCtx.NodeStates {
+		switch status {
+		case models.NodeStatusCompleted:
+			metrics.CompletedNodes++
+		case models.NodeStatusFailed:
+			metrics.FailedNodes++
+		case models.NodeStatusSkipped:
+			metrics.SkippedNodes++
+		}
+	}
+
+	if execCtx.Execution.StartedAt != nil {
+		metrics.ExecutionTime = time.Since(*execCtx.Execution.StartedAt)
+	}
+
+	return metrics
+}
+
+// collectExecutionOutput 汇总出口节点（没有出边的已完成节点）的输出：单个出口时为其输出，多个出口时按节点ID索引（调用方需持有锁）
+func (e *WorkflowEngine) collectExecutionOutput(execCtx *ExecutionContext) map[string]interface{} {
+	hasOutgoing := make(map[string]bool)
+	for _, edge := range execCtx.Workflow.Edges {
+		if edge.IsEnabled() {
+			hasOutgoing[edge.FromNodeID] = true
+		}
+	}
+
+	output := make(map[string]interface{})
+	var last map[string]interface{}
+	for nodeID := range execCtx.Workflow.Nodes {
+		outputs, exists := execCtx.NodeOutputs[nodeID]
+		if hasOutgoing[nodeID] || !exists || execCtx.NodeStates[nodeID] != models.NodeStatusCompleted {
+			continue
+		}
+		output[nodeID] = outputs
+		last = outputs
+	}
+
+	if len(output) == 1 {
+		return last
+	}
+	return output
+}
+
+// evaluateWorkflowOutputs 按工作流输出定义求值执行结果，节点端口未产生输出时为 null（调用方需持有锁）
+func (e *WorkflowEngine) evaluateWorkflowOutputs(execCtx *ExecutionContext) (map[string]interface{}, error) {
+	env := e.buildExpressionEnv(execCtx)
+
+	output := make(map[string]interface{}, len(execCtx.Workflow.Outputs))
+	for _, declared := range execCtx.Workflow.Outputs {
+		if declared.Expression != "" {
+			value, err := expression.Evaluate(execCtx.ctx, declared.Expression, env)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate output %s: %w", declared.Name, err)
+			}
+			output[declared.Name] = value
+			continue
+		}
+
+		nodeOutputs := execCtx.NodeOutputs[declared.NodeID]
+		if declared.Port == "" {
+			output[declared.Name] = nodeOutputs
+		} else {
+			output[declared.Name] = nodeOutputs[declared.Port]
+		}
+	}
+	return output, nil
+}
+
+// getCallback 获取执行结果回调
+func (e *WorkflowEngine) getCallback() ExecutionCallback {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.callback
+}
+
+// callbackFor 获取执行上下文使用的回调，模拟运行使用模拟器代替执行服务
+func (e *WorkflowEngine) callbackFor(execCtx *ExecutionContext) ExecutionCallback {
+	if execCtx.simulator != nil {
+		return execCtx.simulator
+	}
+	return e.getCallback()
+}
+
+// resolveOutgoingEdges 根据节点结果决议出边状态，并检查下游节点是否就绪或应被跳过
+//   - 节点完成：普通边激活，条件边按条件激活，错误/超时/跳过边剪枝
+//   - 节点失败：只激活选中的错误/超时处理边，其它边剪枝
+//   - 节点跳过：只激活跳过边，其它边剪枝
+func (e *WorkflowEngine) resolveOutgoingEdges(execCtx *ExecutionContext, nodeID string, outcome models.NodeStatusEnum, handlerEdges map[string]bool) {
+	var targets []string
+	seen := make(map[string]bool)
+
+	for _, edge := range execCtx.Workflow.Edges {
+		if edge.FromNodeID != nodeID || !edge.IsEnabled() {
+			continue
+		}
+		if _, exists := execCtx.Workflow.Nodes[edge.ToNodeID]; !exists {
+			continue
+		}
+
+		state := EdgeStatePruned
+		switch outcome {
+		case models.NodeStatusCompleted:
+			if edge.IsFailureHandler() || edge.Type == models.EdgeTypeSkip {
+				break
+			}
+			if !e.isSelectedBranch(execCtx, edge) {
+				log.Printf("Branch not selected, pruning edge %s to node: %s", edge.ID, edge.ToNodeID)
+				break
+			}
+			if !edge.IsConditional() {
+				state = EdgeStateTaken
+				break
+			}
+			// 求值失败时按条件的默认值决议
+			shouldExecute, err := e.evaluateEdgeCondition(execCtx, edge)
+			if err != nil {
+				log.Printf("Failed to evaluate condition of edge %s, using default value %v: %v", edge.ID, shouldExecute, err)
+			}
+			if shouldExecute {
+				state = EdgeStateTaken
+			} else {
+				log.Printf("Edge condition not met, pruning edge %s to node: %s", edge.ID, edge.ToNodeID)
+			}
+		case models.NodeStatusFailed:
+			if handlerEdges[edge.ID] {
+				state = EdgeStateTaken
+			}
+		case models.NodeStatusSkipped:
+			if edge.Type == models.EdgeTypeSkip {
+				state = EdgeStateTaken
+			}
+		}
+
+		execCtx.mu.Lock()
+		execCtx.EdgeStates[edge.ID] = state
+		execCtx.mu.Unlock()
+
+		if !seen[edge.ToNodeID] {
+			seen[edge.ToNodeID] = true
+			targets = append(targets, edge.ToNodeID)
+		}
+	}
+
+	for _, target := range targets {
+		e.scheduleIfResolved(execCtx, target)
+	}
+}
+
+// activateFailureEdges 节点失败时激活错误/超时处理边，返回是否存在处理路径
+func (e *WorkflowEngine) activateFailureEdges(execCtx *ExecutionContext, failedNodeID string, execErr error) bool {
+	timedOut := errors.Is(execErr, context.DeadlineExceeded)
+
+	// 超时优先走超时边，没有超时边时回退到错误边
+	var handlers []*models.Edge
+	if timedOut {
+		handlers = e.findOutgoingEdges(execCtx, failedNodeID, models.EdgeTypeTimeout)
+	}
+	if len(handlers) == 0 {
+		handlers = e.findOutgoingEdges(execCtx, failedNodeID, models.EdgeTypeError)
+	}
+	if len(handlers) == 0 {
+		return false
+	}
+
+	execCtx.mu.Lock()
+	attempts := 1
+	if record, exists := execCtx.NodeRecords[failedNodeID]; exists {
+		attempts = record.RetryCount + 1
 	}
-
-	// 检查执行结果
-	if nodeOutput == nil {
-		return fmt.Errorf("node plugin returned nil output")
+	failure := map[string]interface{}{
+		"node_id":   failedNodeID,
+		"node_name": execCtx.Workflow.Nodes[failedNodeID].Name,
+		"error":     execErr.Error(),
+		"attempts":  attempts,
+		"timeout":   timedOut,
 	}
+	execCtx.NodeFailures[failedNodeID] = failure
+	execCtx.Variables[fmt.Sprintf("%s_error", failedNodeID)] = failure
+	execCtx.mu.Unlock()
 
-	if !nodeOutput.Success {
-		return fmt.Errorf("node execution failed: %s", nodeOutput.Error)
+	handlerEdges := make(map[string]bool, len(handlers))
+	for _, edge := range handlers {
+		log.Printf("Node %s failed, routing to %s handler: %s", failedNodeID, edge.Type, edge.ToNodeID)
+		handlerEdges[edge.ID] = true
 	}
 
-	// 处理输出数据
-	e.processNodeOutput(execCtx, nodeID, nodeOutput.Data)
+	e.resolveOutgoingEdges(execCtx, failedNodeID, models.NodeStatusFailed, handlerEdges)
+	return true
+}
 
-	log.Printf("Node %s executed successfully", nodeID)
-	return nil
+// findOutgoingEdges 查找节点指定类型的已启用出边
+func (e *WorkflowEngine) findOutgoingEdges(execCtx *ExecutionContext, nodeID string, edgeType models.EdgeTypeEnum) []*models.Edge {
+	var edges []*models.Edge
+	for _, edge := range execCtx.Workflow.Edges {
+		if edge.FromNodeID == nodeID && edge.Type == edgeType && edge.IsEnabled() {
+			if _, exists := execCtx.Workflow.Nodes[edge.ToNodeID]; exists {
+				edges = append(edges, edge)
+			}
+		}
+	}
+	return edges
 }
 
-// activateDownstreamNodes 激活下游节点
-func (e *WorkflowEngine) activateDownstreamNodes(execCtx *ExecutionContext, completedNodeID string) {
-	if execCtx.Workflow.Edges == nil {
+// scheduleIfResolved 节点所有入边都已决议时进行调度：
+// 至少一条入边被激活则加入准备队列，全部被剪枝则跳过该节点并向下游传播
+func (e *WorkflowEngine) scheduleIfResolved(execCtx *ExecutionContext, nodeID string) {
+	execCtx.mu.Lock()
+	if execCtx.ScheduledNodes[nodeID] {
+		execCtx.mu.Unlock()
 		return
 	}
 
+	taken := false
 	for _, edge := range execCtx.Workflow.Edges {
-		if edge.FromNodeID != completedNodeID || !edge.IsEnabled() {
+		if edge.ToNodeID != nodeID || !edge.IsEnabled() {
 			continue
 		}
-
-		toNodeID := edge.ToNodeID
-
-		// 检查是否为条件边
-		if edge.IsConditional() {
-			shouldExecute, err := e.evaluateEdgeCondition(execCtx, edge)
-			if err != nil {
-				log.Printf("Failed to evaluate edge condition: %v", err)
-				continue
-			}
-			if !shouldExecute {
-				log.Printf("Edge condition not met, skipping node: %s", toNodeID)
-				continue
-			}
+		if _, exists := execCtx.Workflow.Nodes[edge.FromNodeID]; !exists {
+			continue
 		}
 
-		// 检查目标节点的所有依赖是否都已完成
-		if e.areAllDependenciesCompleted(execCtx, toNodeID) {
-			// 将节点加入准备队列
-			select {
-			case execCtx.ReadyNodes <- toNodeID:
-				log.Printf("Node %s is ready for execution", toNodeID)
-			case <-execCtx.ctx.Done():
-				return
-			default:
-				// 通道满了，忽略（节点可能已经在队列中）
-			}
+		state, resolved := execCtx.EdgeStates[edge.ID]
+		if !resolved {
+			// 仍有上游未完成
+			execCtx.mu.Unlock()
+			return
+		}
+		if state == EdgeStateTaken {
+			taken = true
 		}
 	}
-}
 
-// areAllDependenciesCompleted 检查所有依赖是否都已完成
-func (e *WorkflowEngine) areAllDependenciesCompleted(execCtx *ExecutionContext, nodeID string) bool {
-	execCtx.mu.RLock()
-	defer execCtx.mu.RUnlock()
+	execCtx.ScheduledNodes[nodeID] = true
+	if !taken {
+		execCtx.NodeStates[nodeID] = models.NodeStatusSkipped
+	}
+	execCtx.mu.Unlock()
 
-	dependencies := execCtx.NodeDependencies[nodeID]
-	for _, depNodeID := range dependencies {
-		if !execCtx.CompletedNodes[depNodeID] {
-			return false
-		}
+	if !taken {
+		log.Printf("All incoming edges pruned, skipping node: %s", nodeID)
+		e.skipNodeRecord(execCtx, nodeID)
+		e.resolveOutgoingEdges(execCtx, nodeID, models.NodeStatusSkipped, nil)
+		return
 	}
-	return true
+
+	e.enqueueNode(execCtx, nodeID)
+	log.Printf("Node %s is ready for execution", nodeID)
 }
 
 // evaluateEdgeCondition 评估边条件
@@ -493,28 +1632,68 @@ func (e *WorkflowEngine) evaluateEdgeCondition(execCtx *ExecutionContext, edge *
 		return true, nil
 	}
 
-	// 使用当前的执行变量作为上下文
+	// 使用当前的执行变量和节点输出作为上下文
 	execCtx.mu.RLock()
-	context := make(map[string]interface{})
-	for k, v := range execCtx.Variables {
-		context[k] = v
-	}
+	env := e.buildExpressionEnv(execCtx)
 	execCtx.mu.RUnlock()
 
 	// 调用边的条件评估方法
-	return edge.EvaluateCondition(context)
+	return edge.EvaluateCondition(env)
 }
 
-// prepareNodeInput 准备节点输入数据
-func (e *WorkflowEngine) prepareNodeInput(execCtx *ExecutionContext, nodeID string, node *models.Node) map[string]interface{} {
+// prepareNodeInput 准备节点输入数据，沿已激活的入边将上游输出端口数据传递到本节点输入端口
+func (e *WorkflowEngine) prepareNodeInput(execCtx *ExecutionContext, nodeID string, node *models.Node) (map[string]interface{}, error) {
 	execCtx.mu.RLock()
 	defer execCtx.mu.RUnlock()
 
 	inputData := make(map[string]interface{})
 
-	// 复制全局变量
-	for k, v := range execCtx.Variables {
-		inputData[k] = v
+	// 多值输入端口汇总所有入边的数据
+	multiplePorts := make(map[string]bool)
+	for _, port := range e.getNodePorts(node, false) {
+		if port.Multiple {
+			multiplePorts[port.ID] = true
+		}
+	}
+
+	for _, edge := range execCtx.Workflow.Edges {
+		if edge.ToNodeID != nodeID || execCtx.EdgeStates[edge.ID] != EdgeStateTaken {
+			continue
+		}
+
+		// 错误/超时处理节点接收上游失败详情
+		if edge.IsFailureHandler() {
+			if failure, exists := execCtx.NodeFailures[edge.FromNodeID]; exists {
+				inputData["error"] = failure
+			}
+			continue
+		}
+
+		outputs, exists := execCtx.NodeOutputs[edge.FromNodeID]
+		if !exists {
+			continue
+		}
+
+		payload, err := e.transferEdgeData(execCtx, edge, outputs)
+		if err != nil {
+			if errors.Is(err, models.ErrEdgeDataFiltered) {
+				log.Printf("Edge %s data filtered out, nothing passed to node %s", edge.ID, nodeID)
+				continue
+			}
+			return nil, fmt.Errorf("edge %s: %w", edge.ID, err)
+		}
+
+		for port, value := range payload {
+			if multiplePorts[port] {
+				list, _ := inputData[port].([]interface{})
+				inputData[port] = append(list, value)
+				continue
+			}
+			if _, exists := inputData[port]; exists {
+				log.Printf("Input port %s of node %s received data from multiple edges, edge %s overrides", port, nodeID, edge.ID)
+			}
+			inputData[port] = value
+		}
 	}
 
 	// 应用输入映射
@@ -523,8 +1702,9 @@ func (e *WorkflowEngine) prepareNodeInput(execCtx *ExecutionContext, nodeID stri
 
 		// 应用映射
 		if inputConfig.Mapping != nil {
+			variables := e.buildVariableContext(execCtx)
 			for targetKey, sourceKey := range inputConfig.Mapping {
-				if value, exists := execCtx.Variables[sourceKey]; exists {
+				if value, exists := variables[sourceKey]; exists {
 					inputData[targetKey] = value
 				}
 			}
@@ -540,7 +1720,175 @@ func (e *WorkflowEngine) prepareNodeInput(execCtx *ExecutionContext, nodeID stri
 		}
 	}
 
-	return inputData
+	return inputData, nil
+}
+
+// transferEdgeData 计算一条边传递给目标节点的数据，返回以目标输入端口ID为键的数据（调用方需持有锁）
+func (e *WorkflowEngine) transferEdgeData(execCtx *ExecutionContext, edge *models.Edge, outputs map[string]interface{}) (map[string]interface{}, error) {
+	// 配置了字段映射时，由映射直接从源输出端口选取数据并命名目标端口
+	if edge.HasFieldMapping() {
+		return edge.TransformData(outputs)
+	}
+
+	fromPort := edge.FromPort
+	if fromPort == "" && edge.Type == models.EdgeTypeLoop {
+		// 循环边默认传递当前迭代元素
+		fromPort = nodes.PortLoopItem
+	}
+	if fromPort == "" {
+		fromPort = e.defaultPortID(execCtx.Workflow.Nodes[edge.FromNodeID], true)
+	}
+	toPort := edge.ToPort
+	if toPort == "" {
+		toPort = e.defaultPortID(execCtx.Workflow.Nodes[edge.ToNodeID], false)
+	}
+
+	// 源节点未声明输出端口时传递完整输出
+	var value interface{} = outputs
+	if fromPort != "" {
+		portValue, exists := outputs[fromPort]
+		if !exists {
+			return nil, nil
+		}
+		value = portValue
+	}
+
+	value, err := e.applyEdgeRules(edge, value)
+	if err != nil {
+		return nil, err
+	}
+
+	if toPort == "" {
+		toPort = fromPort
+	}
+	if toPort == "" {
+		// 两端均未声明端口，按输出键直接合并
+		if merged, ok := value.(map[string]interface{}); ok {
+			return merged, nil
+		}
+		return nil, nil
+	}
+
+	return map[string]interface{}{toPort: value}, nil
+}
+
+// applyEdgeRules 对端口数据应用边的转换和过滤规则，数组数据按记录逐条处理
+func (e *WorkflowEngine) applyEdgeRules(edge *models.Edge, value interface{}) (interface{}, error) {
+	if edge.Config == nil || edge.Config.DataMapping == nil || edge.Config.DataMapping.PassThrough {
+		return value, nil
+	}
+
+	switch data := value.(type) {
+	case map[string]interface{}:
+		return edge.TransformData(data)
+	case []map[string]interface{}:
+		records := make([]interface{}, len(data))
+		for i, record := range data {
+			records[i] = record
+		}
+		return e.applyEdgeRules(edge, records)
+	case []interface{}:
+		result := make([]interface{}, 0, len(data))
+		for _, item := range data {
+			record, ok := item.(map[string]interface{})
+			if !ok {
+				result = append(result, item)
+				continue
+			}
+			transformed, err := edge.TransformData(record)
+			if errors.Is(err, models.ErrEdgeDataFiltered) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, transformed)
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+// getNodePorts 获取节点插件声明的输入或输出端口
+func (e *WorkflowEngine) getNodePorts(node *models.Node, output bool) []nodes.PortDefinition {
+	if node == nil {
+		return nil
+	}
+
+	plugin, err := e.nodeRegistry.Get(node.Plugin)
+	if err != nil {
+		return nil
+	}
+
+	metadata := plugin.GetMetadata()
+	if metadata == nil {
+		return nil
+	}
+	if output {
+		return metadata.OutputPorts
+	}
+	return metadata.InputPorts
+}
+
+// defaultPortID 边未指定端口时使用节点声明的第一个端口
+func (e *WorkflowEngine) defaultPortID(node *models.Node, output bool) string {
+	ports := e.getNodePorts(node, output)
+	if len(ports) == 0 {
+		return ""
+	}
+	return ports[0].ID
+}
+
+// buildVariableContext 合并执行变量和节点输出，节点输出以 <nodeID>_<port> 和 <nodeID>_output 为键（调用方需持有锁）
+func (e *WorkflowEngine) buildVariableContext(execCtx *ExecutionContext) map[string]interface{} {
+	context := make(map[string]interface{}, len(execCtx.Variables))
+	for k, v := range execCtx.Variables {
+		context[k] = v
+	}
+
+	for nodeID, outputs := range execCtx.NodeOutputs {
+		for port, value := range outputs {
+			context[fmt.Sprintf("%s_%s", nodeID, port)] = value
+		}
+		context[fmt.Sprintf("%s_output", nodeID)] = outputs
+	}
+
+	return context
+}
+
+// buildExpressionEnv 构建条件表达式的求值环境：在变量上下文基础上，
+// 节点输出可通过 nodes.<节点ID>.<端口> 或 nodes.<节点ID>.output.<端口> 访问，
+// 未与变量重名时也可直接以 <节点ID>.<端口>、<节点ID>.output.<端口> 访问
+func (e *WorkflowEngine) buildExpressionEnv(execCtx *ExecutionContext) map[string]interface{} {
+	env := e.buildVariableContext(execCtx)
+
+	nodeOutputs := make(map[string]interface{}, len(execCtx.NodeOutputs))
+	for nodeID, outputs := range execCtx.NodeOutputs {
+		entry := nodeOutputEntry(outputs)
+		nodeOutputs[nodeID] = entry
+		if _, exists := env[nodeID]; !exists {
+			env[nodeID] = entry
+		}
+	}
+	if _, exists := env["nodes"]; !exists {
+		env["nodes"] = nodeOutputs
+	}
+
+	return env
+}
+
+// nodeOutputEntry 构造表达式中节点的访问入口：各端口的输出，以及指向全部端口输出的 output；
+// 节点自身有 output 端口时（如子流程节点）output 为该端口的输出
+func nodeOutputEntry(outputs map[string]interface{}) map[string]interface{} {
+	entry := make(map[string]interface{}, len(outputs)+1)
+	for port, value := range outputs {
+		entry[port] = value
+	}
+	if _, exists := entry["output"]; !exists {
+		entry["output"] = outputs
+	}
+	return entry
 }
 
 // processNodeOutput 处理节点输出数据
@@ -552,15 +1900,178 @@ func (e *WorkflowEngine) processNodeOutput(execCtx *ExecutionContext, nodeID str
 	execCtx.mu.Lock()
 	defer execCtx.mu.Unlock()
 
-	// 将输出数据添加到全局变量中
-	for key, value := range outputData {
-		// 使用节点ID作为前缀避免冲突
-		globalKey := fmt.Sprintf("%s_%s", nodeID, key)
-		execCtx.Variables[globalKey] = value
+	// 按端口保存节点输出，由出边传递给下游节点
+	execCtx.NodeOutputs[nodeID] = outputData
+}
+
+// markCheckpointDirty 标记检查点需要保存，未配置持久化间隔时立即保存
+func (e *WorkflowEngine) markCheckpointDirty(execCtx *ExecutionContext) {
+	// 子图状态随所属控制节点整体保存
+	if execCtx.parent != nil {
+		return
+	}
+
+	if e.config.Storage.StatePersistInterval <= 0 {
+		e.saveCheckpoint(execCtx)
+		return
+	}
+
+	execCtx.mu.Lock()
+	execCtx.checkpointDirty = true
+	execCtx.mu.Unlock()
+}
+
+// runCheckpointLoop 按持久化间隔保存有变化的检查点
+func (e *WorkflowEngine) runCheckpointLoop(execCtx *ExecutionContext) {
+	ticker := time.NewTicker(e.config.Storage.StatePersistInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-execCtx.ctx.Done():
+			return
+		case <-execCtx.stopCh:
+			return
+		case <-ticker.C:
+			execCtx.mu.RLock()
+			dirty := execCtx.checkpointDirty
+			execCtx.mu.RUnlock()
+
+			if dirty {
+				e.saveCheckpoint(execCtx)
+			}
+		}
+	}
+}
+
+// saveCheckpoint 保存已结束节点的状态、输出和边状态
+func (e *WorkflowEngine) saveCheckpoint(execCtx *ExecutionContext) {
+	callback := e.callbackFor(execCtx)
+	if callback == nil {
+		return
+	}
+
+	execCtx.mu.Lock()
+	checkpoint := &models.ExecutionCheckpoint{
+		NodeStates:   make(map[string]models.NodeStatusEnum),
+		EdgeStates:   make(map[string]string, len(execCtx.EdgeStates)),
+		NodeOutputs:  execCtx.NodeOutputs,
+		NodeFailures: execCtx.NodeFailures,
+		Variables:    execCtx.Variables,
+		WakeTimes:    execCtx.wakeTimes,
+		SavedAt:      time.Now(),
+	}
+	for nodeID, state := range execCtx.NodeStates {
+		// 执行中的节点恢复后重新执行
+		switch state {
+		case models.NodeStatusCompleted, models.NodeStatusFailed, models.NodeStatusSkipped:
+			checkpoint.NodeStates[nodeID] = state
+		}
+	}
+	for edgeID, state := range execCtx.EdgeStates {
+		checkpoint.EdgeStates[edgeID] = string(state)
+	}
+	data, err := checkpoint.Encode(e.config.Storage.StateCompression)
+	execCtx.checkpointDirty = false
+	execCtx.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Failed to encode checkpoint for execution %s: %v", execCtx.ExecutionID, err)
+		return
+	}
+
+	if err := callback.SaveCheckpoint(execCtx.ExecutionID, data); err != nil {
+		log.Printf("Failed to save checkpoint for execution %s: %v", execCtx.ExecutionID, err)
+	}
+}
+
+// restoreCheckpoint 从检查点恢复已结束节点，出边未全部决议的节点视为未完成并重新执行
+func (e *WorkflowEngine) restoreCheckpoint(execCtx *ExecutionContext, checkpoint *models.ExecutionCheckpoint) {
+	execCtx.mu.Lock()
+	defer execCtx.mu.Unlock()
+
+	for key, value := range checkpoint.Variables {
+		execCtx.Variables[key] = value
+	}
+	for nodeID, wakeAt := range checkpoint.WakeTimes {
+		execCtx.wakeTimes[nodeID] = wakeAt
+	}
+
+	for nodeID, state := range checkpoint.NodeStates {
+		if _, exists := execCtx.Workflow.Nodes[nodeID]; !exists {
+			continue
+		}
+
+		var outgoing []*models.Edge
+		resolved := true
+		for _, edge := range execCtx.Workflow.Edges {
+			if edge.FromNodeID != nodeID || !edge.IsEnabled() {
+				continue
+			}
+			if _, exists := execCtx.Workflow.Nodes[edge.ToNodeID]; !exists {
+				continue
+			}
+			if _, ok := checkpoint.EdgeStates[edge.ID]; !ok {
+				resolved = false
+				break
+			}
+			outgoing = append(outgoing, edge)
+		}
+		if !resolved {
+			continue
+		}
+
+		execCtx.NodeStates[nodeID] = state
+		execCtx.ScheduledNodes[nodeID] = true
+		for _, edge := range outgoing {
+			execCtx.EdgeStates[edge.ID] = EdgeState(checkpoint.EdgeStates[edge.ID])
+		}
+
+		switch state {
+		case models.NodeStatusCompleted:
+			execCtx.CompletedNodes[nodeID] = true
+			if outputs, exists := checkpoint.NodeOutputs[nodeID]; exists {
+				execCtx.NodeOutputs[nodeID] = outputs
+			}
+		case models.NodeStatusFailed:
+			if failure, exists := checkpoint.NodeFailures[nodeID]; exists {
+				execCtx.NodeFailures[nodeID] = failure
+			}
+		}
+	}
+
+	log.Printf("Restored %d finished nodes for execution %s", len(execCtx.ScheduledNodes), execCtx.ExecutionID)
+}
+
+// scheduleResumedNodes 调度恢复后尚未结束的节点
+func (e *WorkflowEngine) scheduleResumedNodes(execCtx *ExecutionContext, startNodes []string) {
+	isStart := make(map[string]bool, len(startNodes))
+	for _, nodeID := range startNodes {
+		isStart[nodeID] = true
+	}
+
+	for nodeID := range execCtx.Workflow.Nodes {
+		if !isStart[nodeID] {
+			e.scheduleIfResolved(execCtx, nodeID)
+			continue
+		}
+
+		execCtx.mu.Lock()
+		scheduled := execCtx.ScheduledNodes[nodeID]
+		execCtx.ScheduledNodes[nodeID] = true
+		execCtx.mu.Unlock()
+
+		if !scheduled {
+			e.enqueueNode(execCtx, nodeID)
+		}
 	}
 
-	// 同时保存原始输出
-	execCtx.Variables[fmt.Sprintf("%s_output", nodeID)] = outputData
+	// 所有节点在中断前均已结束时直接完成
+	execCtx.mu.Lock()
+	if execCtx.pendingWork == 0 {
+		close(execCtx.workDone)
+	}
+	execCtx.mu.Unlock()
 }
 
 // GetActiveExecutions 获取活跃的执行列表
@@ -575,6 +2086,96 @@ func (e *WorkflowEngine) GetActiveExecutions() []string {
 	return executions
 }
 
+// HasCapacity 检查是否还能接纳新的执行
+func (e *WorkflowEngine) HasCapacity() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.admittedCount() < e.maxConcurrency
+}
+
+// GetActiveExecutionCounts 按工作流统计占用并发槽位的执行数
+func (e *WorkflowEngine) GetActiveExecutionCounts() map[string]int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, execCtx := range e.executions {
+		if execCtx.Execution.ParentExecutionID == "" {
+			counts[execCtx.WorkflowID]++
+		}
+	}
+	return counts
+}
+
+// admittedCount 统计占用并发槽位的执行数，子执行不占用槽位（调用方需持有锁）
+func (e *WorkflowEngine) admittedCount() int {
+	count := 0
+	for _, execCtx := range e.executions {
+		if execCtx.Execution.ParentExecutionID == "" {
+			count++
+		}
+	}
+	return count
+}
+
+// IsDraining 判断引擎是否正在排空
+func (e *WorkflowEngine) IsDraining() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.status == EngineStatusDraining
+}
+
+// drainable 判断执行上下文是否随引擎排空停止派发：模拟运行和子图不排空
+func (e *WorkflowEngine) drainable(execCtx *ExecutionContext) bool {
+	return execCtx.parent == nil && execCtx.simulator == nil
+}
+
+// Drain 排空引擎：不再接收新执行，所有执行停止派发新节点，等待在途节点结束后保存检查点。
+// ctx 结束时仍未排空的执行立即保存检查点（在途节点不计入，恢复后重新执行），返回这些执行的ID
+func (e *WorkflowEngine) Drain(ctx context.Context) []string {
+	e.mu.Lock()
+	if e.status != EngineStatusRunning {
+		e.mu.Unlock()
+		return nil
+	}
+	e.status = EngineStatusDraining
+	close(e.drainCh)
+	e.mu.Unlock()
+
+	log.Println("WorkflowEngine draining")
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		e.mu.RLock()
+		remaining := make([]*ExecutionContext, 0, len(e.executions))
+		for _, execCtx := range e.executions {
+			remaining = append(remaining, execCtx)
+		}
+		e.mu.RUnlock()
+
+		if len(remaining) == 0 {
+			log.Println("WorkflowEngine drained")
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+			continue
+		case <-ctx.Done():
+		}
+
+		ids := make([]string, 0, len(remaining))
+		for _, execCtx := range remaining {
+			e.saveCheckpoint(execCtx)
+			ids = append(ids, execCtx.ExecutionID)
+		}
+		log.Printf("WorkflowEngine drain timed out, %d executions still running: %v", len(ids), ids)
+		return ids
+	}
+}
+
 // GetStatus 获取引擎状态
 func (e *WorkflowEngine) GetStatus() EngineStatus {
 	e.mu.RLock()
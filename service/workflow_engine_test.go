@@ -0,0 +1,1083 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"flow-service/service/config"
+	"flow-service/service/models"
+	"flow-service/service/nodes"
+)
+
+// engineTestTimeout 等待执行结束的上限，超过视为引擎挂起
+const engineTestTimeout = 5 * time.Second
+
+// engineTestRun 测试插件的执行函数，nodeID 为调用该插件的节点ID
+type engineTestRun func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error)
+
+// engineTestPlugin 引擎测试用插件，执行逻辑由测试提供，成功时附带 logs 和 metrics
+type engineTestPlugin struct {
+	id      string
+	run     engineTestRun
+	inputs  []nodes.PortDefinition
+	outputs []nodes.PortDefinition
+	logs    []string
+	metrics map[string]interface{}
+}
+
+func (p *engineTestPlugin) GetMetadata() *nodes.NodeMetadata {
+	return &nodes.NodeMetadata{
+		ID:           p.id,
+		Name:         p.id,
+		Category:     nodes.CategoryTransform,
+		Type:         "transform",
+		InputPorts:   p.inputs,
+		OutputPorts:  p.outputs,
+		ConfigSchema: &nodes.ConfigSchema{Type: "object"},
+	}
+}
+
+func (p *engineTestPlugin) Validate(config map[string]interface{}) error {
+	return nil
+}
+
+func (p *engineTestPlugin) Execute(ctx context.Context, input *nodes.NodeInput) (*nodes.NodeOutput, error) {
+	nodeID, _ := input.Config["node"].(string)
+	data, err := p.run(ctx, nodeID, input)
+	if err != nil {
+		return nil, err
+	}
+	return &nodes.NodeOutput{Data: data, Logs: p.logs, Metrics: p.metrics, Success: true}, nil
+}
+
+func (p *engineTestPlugin) GetDynamicData(method string, params map[string]interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+// registerEngineTestPlugin 为当前测试注册插件，测试结束后注销
+func registerEngineTestPlugin(t *testing.T, plugin *engineTestPlugin) string {
+	t.Helper()
+	plugin.id = "engine_test:" + strings.ReplaceAll(t.Name(), "/", ":")
+	registry := nodes.GetRegistry()
+	if err := registry.Register(plugin); err != nil {
+		t.Fatalf("failed to register test plugin: %v", err)
+	}
+	t.Cleanup(func() { registry.Unregister(plugin.id) })
+	return plugin.id
+}
+
+// fakeExecutionCallback 在内存中收集引擎回调的执行回调
+type fakeExecutionCallback struct {
+	mu          sync.Mutex
+	records     map[string]*models.ExecutionNodeRecord
+	checkpoints []string
+	finished    chan *ExecutionResult
+	released    chan string
+}
+
+func newFakeExecutionCallback() *fakeExecutionCallback {
+	return &fakeExecutionCallback{
+		records:  make(map[string]*models.ExecutionNodeRecord),
+		finished: make(chan *ExecutionResult, 1),
+		released: make(chan string, 1),
+	}
+}
+
+func (c *fakeExecutionCallback) SaveNodeRecord(executionID string, record *models.ExecutionNodeRecord) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records[record.NodeID] = record
+	return nil
+}
+
+func (c *fakeExecutionCallback) FinishExecution(executionID string, result *ExecutionResult) error {
+	c.finished <- result
+	return nil
+}
+
+func (c *fakeExecutionCallback) SaveCheckpoint(executionID string, checkpoint string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checkpoints = append(c.checkpoints, checkpoint)
+	return nil
+}
+
+func (c *fakeExecutionCallback) StartSubExecution(request *SubExecutionRequest) (string, error) {
+	return "", fmt.Errorf("sub executions are not supported in engine tests")
+}
+
+func (c *fakeExecutionCallback) GetExecution(executionID string) (*models.Execution, error) {
+	return nil, fmt.Errorf("execution not found: %s", executionID)
+}
+
+func (c *fakeExecutionCallback) CancelExecution(executionID string) error {
+	return nil
+}
+
+func (c *fakeExecutionCallback) ExecutionReleased(executionID string) {
+	c.released <- executionID
+}
+
+// record 返回节点最近一次保存的执行记录
+func (c *fakeExecutionCallback) record(nodeID string) *models.ExecutionNodeRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.records[nodeID]
+}
+
+// lastCheckpoint 解码最近一次保存的检查点
+func (c *fakeExecutionCallback) lastCheckpoint(t *testing.T) *models.ExecutionCheckpoint {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.checkpoints) == 0 {
+		t.Fatal("no checkpoint was saved")
+	}
+	checkpoint, err := models.DecodeExecutionCheckpoint(c.checkpoints[len(c.checkpoints)-1])
+	if err != nil {
+		t.Fatalf("DecodeExecutionCheckpoint() error = %v", err)
+	}
+	return checkpoint
+}
+
+// wait 等待执行结束并释放，返回执行结果
+func (c *fakeExecutionCallback) wait(t *testing.T) *ExecutionResult {
+	t.Helper()
+	var result *ExecutionResult
+	select {
+	case result = <-c.finished:
+	case <-time.After(engineTestTimeout):
+		t.Fatal("execution did not finish")
+	}
+	c.waitReleased(t)
+	return result
+}
+
+// waitReleased 等待执行释放并发槽位
+func (c *fakeExecutionCallback) waitReleased(t *testing.T) {
+	t.Helper()
+	select {
+	case <-c.released:
+	case <-time.After(engineTestTimeout):
+		t.Fatal("execution was not released")
+	}
+}
+
+// newTestEngine 创建并启动使用内存回调的引擎，workers 为全局节点执行槽位数，configure 在启动前调整其余配置
+func newTestEngine(t *testing.T, workers int, configure ...func(cfg *config.EngineConfig)) (*WorkflowEngine, *fakeExecutionCallback) {
+	t.Helper()
+	cfg := *config.DefaultEngineConfig
+	cfg.WorkerPool.CoreWorkers = workers
+	cfg.WorkerPool.AutoScaling.Enabled = false
+	cfg.Storage.StatePersistInterval = 0
+	cfg.ConcurrencyPools = nil
+	for _, fn := range configure {
+		fn(&cfg)
+	}
+
+	engine := NewWorkflowEngineWithConfig(&cfg)
+	callback := newFakeExecutionCallback()
+	engine.SetCallback(callback)
+	if err := engine.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { engine.Stop() })
+	return engine, callback
+}
+
+// testWorkflow 构造由同一插件节点和普通边组成的工作流，edges 形如 "a->b"
+func testWorkflow(plugin string, nodeIDs []string, edges ...string) *models.Workflow {
+	workflow := &models.Workflow{ID: "wf-test", Nodes: make(map[string]*models.Node)}
+	for _, nodeID := range nodeIDs {
+		workflow.Nodes[nodeID] = &models.Node{
+			ID:     nodeID,
+			Name:   nodeID,
+			Plugin: plugin,
+			Config: &models.NodeConfig{PluginConfig: map[string]interface{}{"node": nodeID}},
+		}
+	}
+	for _, spec := range edges {
+		from, to, _ := strings.Cut(spec, "->")
+		workflow.Edges = append(workflow.Edges, &models.Edge{
+			ID:         from + "-" + to,
+			FromNodeID: from,
+			ToNodeID:   to,
+			Type:       models.EdgeTypeNormal,
+		})
+	}
+	return workflow
+}
+
+// executeTestWorkflow 启动执行并等待结束
+func executeTestWorkflow(t *testing.T, engine *WorkflowEngine, callback *fakeExecutionCallback, workflow *models.Workflow) *ExecutionResult {
+	t.Helper()
+	execution := &models.Execution{ID: "exec-" + workflow.ID, WorkflowID: workflow.ID}
+	if err := engine.ExecuteWorkflow(context.Background(), workflow, execution); err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+	return callback.wait(t)
+}
+
+func TestWorkflowEngineDiamondCompletes(t *testing.T) {
+	// b 和 c 互相等待对方开始，只有并行派发时才能完成
+	var mu sync.Mutex
+	var order []string
+	started := map[string]chan struct{}{"b": make(chan struct{}), "c": make(chan struct{})}
+
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			if ch, exists := started[nodeID]; exists {
+				close(ch)
+				other := started[map[string]string{"b": "c", "c": "b"}[nodeID]]
+				select {
+				case <-other:
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+			mu.Lock()
+			order = append(order, nodeID)
+			mu.Unlock()
+			return map[string]interface{}{nodeID: true}, nil
+		},
+	})
+
+	engine, callback := newTestEngine(t, 4)
+	workflow := testWorkflow(plugin, []string{"a", "b", "c", "d"}, "a->b", "a->c", "b->d", "c->d")
+	result := executeTestWorkflow(t, engine, callback, workflow)
+
+	if result.Status != models.ExecutionStatusCompleted {
+		t.Fatalf("status = %s (%s), want completed", result.Status, result.ErrorMsg)
+	}
+	if result.Metrics.TotalNodes != 4 || result.Metrics.CompletedNodes != 4 {
+		t.Errorf("metrics = %+v, want 4 of 4 completed", result.Metrics)
+	}
+	if len(order) != 4 || order[0] != "a" || order[3] != "d" {
+		t.Errorf("execution order = %v, want a first and d last", order)
+	}
+
+	// d 汇合两条分支的输出，且只执行一次
+	input := callback.record("d").Input
+	if input["b"] != true || input["c"] != true {
+		t.Errorf("d input = %v, want outputs of b and c", input)
+	}
+	if want := map[string]interface{}{"d": true}; !reflect.DeepEqual(result.Output, want) {
+		t.Errorf("output = %v, want %v", result.Output, want)
+	}
+}
+
+func TestWorkflowEngineWorkerSlotsLimitRunningNodes(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			mu.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil, nil
+		},
+	})
+
+	engine, callback := newTestEngine(t, 2)
+	workflow := testWorkflow(plugin, []string{"root", "n1", "n2", "n3", "n4", "n5"},
+		"root->n1", "root->n2", "root->n3", "root->n4", "root->n5")
+	result := executeTestWorkflow(t, engine, callback, workflow)
+
+	if result.Status != models.ExecutionStatusCompleted {
+		t.Fatalf("status = %s (%s), want completed", result.Status, result.ErrorMsg)
+	}
+	if peak != 2 {
+		t.Errorf("peak running nodes = %d, want 2", peak)
+	}
+}
+
+func TestWorkflowEngineUnhandledFailureStopsDispatch(t *testing.T) {
+	var mu sync.Mutex
+	ran := map[string]bool{}
+
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			mu.Lock()
+			ran[nodeID] = true
+			mu.Unlock()
+			if nodeID == "b" {
+				return nil, fmt.Errorf("boom")
+			}
+			return nil, nil
+		},
+	})
+
+	engine, callback := newTestEngine(t, 1)
+	workflow := testWorkflow(plugin, []string{"a", "b", "c"}, "a->b", "b->c")
+	result := executeTestWorkflow(t, engine, callback, workflow)
+
+	if result.Status != models.ExecutionStatusFailed || result.ErrorCode != ErrorCodeNodeFailed {
+		t.Fatalf("status = %s/%s, want failed/%s", result.Status, result.ErrorCode, ErrorCodeNodeFailed)
+	}
+	if ran["c"] {
+		t.Error("node c ran after its upstream failed")
+	}
+	if record := callback.record("b"); record == nil || record.Status != models.ExecutionStatusFailed {
+		t.Errorf("record of b = %+v, want failed", record)
+	}
+	if len(engine.GetActiveExecutions()) != 0 {
+		t.Errorf("active executions = %v, want none", engine.GetActiveExecutions())
+	}
+}
+
+func TestWorkflowEngineSkipsUntakenBranch(t *testing.T) {
+	var mu sync.Mutex
+	ran := map[string]bool{}
+
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			mu.Lock()
+			ran[nodeID] = true
+			mu.Unlock()
+			return map[string]interface{}{"go": false, nodeID: true}, nil
+		},
+	})
+
+	// a 的条件边不满足：b、c 被跳过，跳过边通知 notify，汇合节点 join 只依赖已执行的 d
+	engine, callback := newTestEngine(t, 2)
+	workflow := testWorkflow(plugin, []string{"a", "b", "c", "d", "join", "notify"},
+		"a->b", "b->c", "a->d", "c->join", "d->join", "b->notify")
+	for _, edge := range workflow.Edges {
+		switch edge.ID {
+		case "a-b":
+			edge.Type = models.EdgeTypeConditional
+			edge.Config = &models.EdgeConfig{Enabled: true, Condition: &models.EdgeCondition{Expression: "nodes.a.go == true", Enabled: true}}
+		case "b-notify":
+			edge.Type = models.EdgeTypeSkip
+		}
+	}
+	result := executeTestWorkflow(t, engine, callback, workflow)
+
+	if result.Status != models.ExecutionStatusCompleted {
+		t.Fatalf("status = %s (%s), want completed", result.Status, result.ErrorMsg)
+	}
+	for nodeID, want := range map[string]bool{"a": true, "b": false, "c": false, "d": true, "join": true, "notify": true} {
+		if ran[nodeID] != want {
+			t.Errorf("node %s ran = %v, want %v", nodeID, ran[nodeID], want)
+		}
+	}
+	if result.Metrics.SkippedNodes != 2 || result.Metrics.CompletedNodes != 4 {
+		t.Errorf("metrics = %+v, want 4 completed and 2 skipped", result.Metrics)
+	}
+
+	// 被跳过的节点写入 skipped 记录，执行服务据此统计跳过数，进度计入跳过的节点
+	execution := &models.Execution{Workflow: workflow}
+	for _, nodeID := range []string{"a", "b", "c", "d", "join", "notify"} {
+		record := callback.record(nodeID)
+		if record == nil {
+			t.Fatalf("no record saved for node %s", nodeID)
+		}
+		execution.SetNodeRecord(record)
+	}
+	for _, nodeID := range []string{"b", "c"} {
+		if record := callback.record(nodeID); record.Status != models.ExecutionStatusSkipped || record.StartTime != nil {
+			t.Errorf("record of %s = %s (started %v), want skipped without start time", nodeID, record.Status, record.StartTime)
+		}
+	}
+	(&ExecutionService{}).updateExecutionMetrics(execution)
+	if execution.Metrics.SkippedNodes != 2 {
+		t.Errorf("service metrics skipped = %d, want 2", execution.Metrics.SkippedNodes)
+	}
+	if progress := execution.GetProgress(); progress != 100 {
+		t.Errorf("GetProgress() = %v, want 100", progress)
+	}
+}
+
+func TestWorkflowEngineErrorEdgeHandlesFailure(t *testing.T) {
+	var mu sync.Mutex
+	ran := map[string]bool{}
+
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			mu.Lock()
+			ran[nodeID] = true
+			mu.Unlock()
+			if nodeID == "extract" {
+				return nil, fmt.Errorf("connection refused")
+			}
+			return map[string]interface{}{"handled": input.Data["error"]}, nil
+		},
+	})
+
+	engine, callback := newTestEngine(t, 2)
+	workflow := testWorkflow(plugin, []string{"extract", "load", "alert"}, "extract->load", "extract->alert")
+	for _, edge := range workflow.Edges {
+		if edge.ToNodeID == "alert" {
+			edge.Type = models.EdgeTypeError
+		}
+	}
+	result := executeTestWorkflow(t, engine, callback, workflow)
+
+	// 存在处理路径时执行不算失败，正常下游被跳过
+	if result.Status != models.ExecutionStatusCompleted {
+		t.Fatalf("status = %s (%s), want completed", result.Status, result.ErrorMsg)
+	}
+	if ran["load"] || !ran["alert"] {
+		t.Errorf("ran = %v, want alert only after extract", ran)
+	}
+	if result.Metrics.FailedNodes != 1 || result.Metrics.SkippedNodes != 1 {
+		t.Errorf("metrics = %+v, want 1 failed and 1 skipped", result.Metrics)
+	}
+
+	failure, _ := callback.record("alert").Input["error"].(map[string]interface{})
+	if failure["node_id"] != "extract" || failure["attempts"] != 1 || failure["timeout"] != false ||
+		!strings.Contains(fmt.Sprint(failure["error"]), "connection refused") {
+		t.Errorf("alert input error = %v, want failure details of extract", failure)
+	}
+}
+
+func TestWorkflowEngineTimeoutEdgePreferredOverErrorEdge(t *testing.T) {
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			if nodeID == "slow" {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return nil, nil
+		},
+	})
+
+	engine, callback := newTestEngine(t, 2)
+	workflow := testWorkflow(plugin, []string{"slow", "on_error", "on_timeout"}, "slow->on_error", "slow->on_timeout")
+	workflow.Nodes["slow"].Config.TimeoutConfig = &models.TimeoutConfig{ExecutionTimeout: 20 * time.Millisecond}
+	workflow.Edges[0].Type = models.EdgeTypeError
+	workflow.Edges[1].Type = models.EdgeTypeTimeout
+	result := executeTestWorkflow(t, engine, callback, workflow)
+
+	if result.Status != models.ExecutionStatusCompleted {
+		t.Fatalf("status = %s (%s), want completed", result.Status, result.ErrorMsg)
+	}
+	if record := callback.record("on_timeout"); record == nil || record.Status != models.ExecutionStatusCompleted {
+		t.Errorf("record of on_timeout = %+v, want completed", record)
+	}
+	if record := callback.record("on_error"); record == nil || record.Status != models.ExecutionStatusSkipped {
+		t.Errorf("record of on_error = %+v, want skipped", record)
+	}
+}
+
+func TestWorkflowEnginePauseHoldsDispatchUntilResume(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan string, 2)
+
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			started <- nodeID
+			if nodeID == "a" {
+				<-release
+			}
+			return nil, nil
+		},
+	})
+
+	engine, callback := newTestEngine(t, 2)
+	execution := &models.Execution{ID: "exec-pause", WorkflowID: "wf-test"}
+	if err := engine.ExecuteWorkflow(context.Background(), testWorkflow(plugin, []string{"a", "b"}, "a->b"), execution); err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+	if nodeID := <-started; nodeID != "a" {
+		t.Fatalf("first node = %s, want a", nodeID)
+	}
+
+	if err := engine.PauseExecution(execution.ID); err != nil {
+		t.Fatalf("PauseExecution() error = %v", err)
+	}
+	if err := engine.PauseExecution(execution.ID); err == nil {
+		t.Error("PauseExecution() on a paused execution expected error")
+	}
+
+	// 在途节点照常完成，但暂停期间不派发下游
+	close(release)
+	select {
+	case nodeID := <-started:
+		t.Fatalf("node %s dispatched while paused", nodeID)
+	case <-time.After(200 * time.Millisecond):
+	}
+	if record := callback.record("a"); record == nil || record.Status != models.ExecutionStatusCompleted {
+		t.Fatalf("record of a = %+v, want completed while paused", record)
+	}
+	if status, err := engine.GetExecutionStatus(execution.ID); err != nil || status.Status != models.ExecutionStatusPaused {
+		t.Fatalf("GetExecutionStatus() = %+v, %v, want paused", status, err)
+	}
+
+	if err := engine.ResumeExecution(execution.ID); err != nil {
+		t.Fatalf("ResumeExecution() error = %v", err)
+	}
+	result := callback.wait(t)
+
+	if result.Status != models.ExecutionStatusCompleted {
+		t.Fatalf("status = %s (%s), want completed", result.Status, result.ErrorMsg)
+	}
+	record := callback.record("b")
+	if record.PausedAt == nil || record.ResumedAt == nil || record.ResumedAt.After(*record.StartTime) {
+		t.Errorf("record of b paused at %v, resumed at %v, started at %v, want pause before start", record.PausedAt, record.ResumedAt, record.StartTime)
+	}
+	if record := callback.record("a"); record.PausedAt != nil {
+		t.Errorf("record of a paused at %v, want nil for a node started before the pause", record.PausedAt)
+	}
+}
+
+func TestWorkflowEngineDelayWaitsWithoutHoldingWorker(t *testing.T) {
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			return map[string]interface{}{"from": nodeID}, nil
+		},
+	})
+
+	engine, callback := newTestEngine(t, 1)
+	workflow := testWorkflow(plugin, []string{"a", "wait", "b", "c"}, "a->wait", "a->c", "wait->b")
+	// 单个工作协程：等待中的节点若占用协程，c 要等到唤醒之后才能执行
+	workflow.Config = &models.WorkflowConfig{MaxConcurrency: 1}
+	workflow.Nodes["wait"].Type = models.NodeTypeDelay
+	workflow.Nodes["wait"].Plugin = nodes.TypeDelay
+	workflow.Nodes["wait"].Config.PluginConfig = map[string]interface{}{"duration": "100ms"}
+	workflow.Edges[0].Config = &models.EdgeConfig{Enabled: true, Delay: 50 * time.Millisecond}
+	result := executeTestWorkflow(t, engine, callback, workflow)
+
+	if result.Status != models.ExecutionStatusCompleted {
+		t.Fatalf("status = %s (%s), want completed", result.Status, result.ErrorMsg)
+	}
+
+	a, wait, b, c := callback.record("a"), callback.record("wait"), callback.record("b"), callback.record("c")
+	if waited := wait.StartTime.Sub(*a.EndTime); waited < 150*time.Millisecond {
+		t.Errorf("wait woke %v after a, want at least edge delay plus duration (150ms)", waited)
+	}
+	if !c.EndTime.Before(*wait.StartTime) {
+		t.Errorf("c ended at %v after wait woke at %v, want c to run while wait is pending", c.EndTime, wait.StartTime)
+	}
+	if want := map[string]interface{}{"from": "a"}; !reflect.DeepEqual(b.Input[nodes.PortWaitOutput], want) {
+		t.Errorf("input of b = %v, want output of a %v passed through wait", b.Input, want)
+	}
+
+	// 唤醒时间写入检查点，恢复后不会重新计时
+	checkpoint := callback.lastCheckpoint(t)
+	if wakeAt, exists := checkpoint.WakeTimes["wait"]; !exists || wakeAt.Before(*a.EndTime) {
+		t.Errorf("checkpoint wake time of wait = %v (exists %v), want after a ended", wakeAt, exists)
+	}
+}
+
+func TestWorkflowEngineTimeoutInterruptsDelay(t *testing.T) {
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			return nil, nil
+		},
+	})
+
+	engine, callback := newTestEngine(t, 2)
+	workflow := testWorkflow(plugin, []string{"a", "wait", "b"}, "a->wait", "wait->b")
+	workflow.Nodes["wait"].Type = models.NodeTypeDelay
+	workflow.Nodes["wait"].Plugin = nodes.TypeDelay
+	workflow.Nodes["wait"].Config.PluginConfig = map[string]interface{}{"duration": "1h"}
+	// 触发时指定的超时覆盖工作流配置
+	workflow.Config = &models.WorkflowConfig{Timeout: time.Hour}
+
+	execution := &models.Execution{ID: "exec-timeout", WorkflowID: workflow.ID, Timeout: 100 * time.Millisecond}
+	started := time.Now()
+	if err := engine.ExecuteWorkflow(context.Background(), workflow, execution); err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+	result := callback.wait(t)
+
+	if result.Status != models.ExecutionStatusTimeout || result.ErrorCode != ErrorCodeTimeout {
+		t.Fatalf("result = %s/%s (%s), want %s/%s", result.Status, result.ErrorCode, result.ErrorMsg, models.ExecutionStatusTimeout, ErrorCodeTimeout)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("execution ended after %v, want the timeout to interrupt the wait", elapsed)
+	}
+	if callback.record("wait") != nil || callback.record("b") != nil {
+		t.Error("wait or b ran after the execution timed out")
+	}
+	if active := engine.GetActiveExecutions(); len(active) != 0 {
+		t.Errorf("active executions = %v, want none", active)
+	}
+}
+
+func TestWorkflowEngineDrainWithNodeInFlight(t *testing.T) {
+	tests := []struct {
+		name          string
+		finishInTime  bool // 在途节点是否在排空期限内完成
+		wantRemaining bool
+	}{
+		{name: "in-flight node finishes", finishInTime: true},
+		{name: "deadline passes", finishInTime: false, wantRemaining: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			running := make(chan struct{})
+			plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+				run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+					if nodeID == "b" {
+						close(running)
+						<-release
+					}
+					return map[string]interface{}{"done": nodeID}, nil
+				},
+			})
+
+			engine, callback := newTestEngine(t, 2)
+			workflow := testWorkflow(plugin, []string{"a", "b", "c"}, "a->b", "b->c")
+			execution := &models.Execution{ID: "exec-drain", WorkflowID: workflow.ID}
+			if err := engine.ExecuteWorkflow(context.Background(), workflow, execution); err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+			<-running
+
+			drainTimeout := engineTestTimeout
+			if !tt.finishInTime {
+				drainTimeout = 100 * time.Millisecond
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+			defer cancel()
+
+			remaining := make(chan []string, 1)
+			go func() { remaining <- engine.Drain(ctx) }()
+
+			// 排空开始后拒绝新执行
+			for deadline := time.Now().Add(engineTestTimeout); !engine.IsDraining(); time.Sleep(time.Millisecond) {
+				if time.Now().After(deadline) {
+					t.Fatal("engine did not start draining")
+				}
+			}
+			late := &models.Execution{ID: "exec-late", WorkflowID: workflow.ID}
+			if err := engine.ExecuteWorkflow(context.Background(), workflow, late); !errors.Is(err, ErrEngineDraining) {
+				t.Fatalf("ExecuteWorkflow() during drain error = %v, want %v", err, ErrEngineDraining)
+			}
+
+			if tt.finishInTime {
+				close(release)
+			}
+			ids := <-remaining
+			if got := len(ids) != 0; got != tt.wantRemaining {
+				t.Fatalf("Drain() = %v, want remaining executions = %v", ids, tt.wantRemaining)
+			}
+
+			checkpoint := callback.lastCheckpoint(t)
+			if checkpoint.NodeStates["a"] != models.NodeStatusCompleted {
+				t.Errorf("checkpoint state of a = %s, want completed", checkpoint.NodeStates["a"])
+			}
+			if got := checkpoint.NodeStates["b"] == models.NodeStatusCompleted; got != tt.finishInTime {
+				t.Errorf("checkpoint state of b = %s, want completed = %v", checkpoint.NodeStates["b"], tt.finishInTime)
+			}
+			if checkpoint.NodeStates["c"] == models.NodeStatusCompleted || callback.record("c") != nil {
+				t.Error("c was dispatched after the drain started")
+			}
+
+			if !tt.finishInTime {
+				close(release)
+			}
+			// 排空的执行不回写最终状态，只释放并发槽位
+			callback.waitReleased(t)
+			select {
+			case result := <-callback.finished:
+				t.Errorf("drained execution finished with %s, want no final status", result.Status)
+			default:
+			}
+		})
+	}
+}
+
+func TestWorkflowEngineConcurrencyPoolLimitsAcrossExecutions(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		loading int
+		peak    int
+	)
+	holding := make(chan struct{}, 2)
+	release := make(chan struct{})
+
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			if nodeID != "load" {
+				return nil, nil
+			}
+			mu.Lock()
+			loading++
+			if loading > peak {
+				peak = loading
+			}
+			mu.Unlock()
+
+			holding <- struct{}{}
+			<-release
+
+			mu.Lock()
+			loading--
+			mu.Unlock()
+			return nil, nil
+		},
+	})
+
+	// 全局槽位充足，只有并发池限制 load 节点
+	engine, callback := newTestEngine(t, 4, func(cfg *config.EngineConfig) {
+		cfg.ConcurrencyPools = []config.ConcurrencyPoolConfig{{Name: "warehouse-db", MaxConcurrency: 1}}
+	})
+	for _, workflowID := range []string{"wf-nightly", "wf-hourly"} {
+		workflow := testWorkflow(plugin, []string{"extract", "load"}, "extract->load")
+		workflow.ID = workflowID
+		workflow.Nodes["load"].Config.ResourceConfig = &models.ResourceConfig{ConcurrencyPool: "warehouse-db"}
+		execution := &models.Execution{ID: "exec-" + workflowID, WorkflowID: workflowID}
+		if err := engine.ExecuteWorkflow(context.Background(), workflow, execution); err != nil {
+			t.Fatalf("ExecuteWorkflow(%s) error = %v", workflowID, err)
+		}
+	}
+
+	<-holding
+	// 第二个 load 节点应在池上等待，而不是占用全局槽位执行
+	want := ConcurrencyPoolUsage{Name: "warehouse-db", Source: ConcurrencyPoolSourceConfig, InUse: 1, Max: 1, Waiting: 1}
+	for deadline := time.Now().Add(engineTestTimeout); ; time.Sleep(time.Millisecond) {
+		usage := engine.GetConcurrencyPoolUsage()
+		if len(usage) == 1 && usage[0] == want {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("GetConcurrencyPoolUsage() = %+v, want [%+v]", usage, want)
+		}
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if result := callback.wait(t); result.Status != models.ExecutionStatusCompleted {
+			t.Errorf("status = %s (%s), want completed", result.Status, result.ErrorMsg)
+		}
+	}
+	if peak != 1 {
+		t.Errorf("peak concurrent load nodes = %d, want 1", peak)
+	}
+	if usage := engine.GetConcurrencyPoolUsage(); usage[0].InUse != 0 || usage[0].Waiting != 0 {
+		t.Errorf("usage after completion = %+v, want pool released", usage[0])
+	}
+}
+
+func TestWorkflowEngineReportsNodeRecordsAndResult(t *testing.T) {
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			time.Sleep(5 * time.Millisecond)
+			if nodeID == "load" {
+				return map[string]interface{}{"rows": input.Data["rows"]}, nil
+			}
+			return map[string]interface{}{"rows": 42}, nil
+		},
+		logs:    []string{"connected"},
+		metrics: map[string]interface{}{"bytes": 1024},
+	})
+
+	engine, callback := newTestEngine(t, 2)
+	workflow := testWorkflow(plugin, []string{"extract", "load"}, "extract->load")
+	workflow.Nodes["load"].Name = "Load warehouse"
+
+	// 执行时间从服务写入的开始时间起算
+	startedAt := time.Now()
+	execution := &models.Execution{ID: "exec-records", WorkflowID: workflow.ID, StartedAt: &startedAt}
+	if err := engine.ExecuteWorkflow(context.Background(), workflow, execution); err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+	result := callback.wait(t)
+
+	if result.Status != models.ExecutionStatusCompleted || result.ErrorMsg != "" {
+		t.Fatalf("result = %s (%s), want completed without error", result.Status, result.ErrorMsg)
+	}
+	if m := result.Metrics; m.TotalNodes != 2 || m.CompletedNodes != 2 || m.FailedNodes != 0 || m.ExecutionTime < 10*time.Millisecond {
+		t.Errorf("metrics = %+v, want 2 of 2 nodes completed with execution time", m)
+	}
+	if !reflect.DeepEqual(result.Output, map[string]interface{}{"rows": 42}) {
+		t.Errorf("output = %v, want output of the sink node", result.Output)
+	}
+
+	record := callback.record("load")
+	if record.NodeName != "Load warehouse" || record.Status != models.ExecutionStatusCompleted {
+		t.Errorf("record = %s/%s, want Load warehouse/completed", record.NodeName, record.Status)
+	}
+	if record.StartTime == nil || record.EndTime == nil || record.EndTime.Before(*record.StartTime) ||
+		record.Duration != record.EndTime.Sub(*record.StartTime) || record.Duration < 5*time.Millisecond {
+		t.Errorf("record times = %v..%v (%v), want a measured duration", record.StartTime, record.EndTime, record.Duration)
+	}
+	if record.Input["rows"] != 42 || record.Output["rows"] != 42 {
+		t.Errorf("record input = %v, output = %v, want rows passed from extract", record.Input, record.Output)
+	}
+	if !reflect.DeepEqual(record.Logs, []string{"connected"}) || record.Metrics["bytes"] != 1024 {
+		t.Errorf("record logs = %v, metrics = %v, want plugin logs and metrics", record.Logs, record.Metrics)
+	}
+}
+
+func TestWorkflowEngineRetriesFailedNode(t *testing.T) {
+	tests := []struct {
+		name         string
+		retry        *models.NodeRetryConfig
+		failures     int // 前几次执行返回错误
+		errMsg       string
+		wantStatus   models.ExecutionStatus
+		wantAttempts int
+		minElapsed   time.Duration
+	}{
+		{
+			name:         "flaky upstream recovers with linear backoff",
+			retry:        &models.NodeRetryConfig{MaxRetries: 3, RetryInterval: 30 * time.Millisecond, BackoffStrategy: "linear"},
+			failures:     2,
+			errMsg:       "connection reset by peer",
+			wantStatus:   models.ExecutionStatusCompleted,
+			wantAttempts: 3,
+			minElapsed:   90 * time.Millisecond, // 30ms + 60ms
+		},
+		{
+			name:         "retries exhausted",
+			retry:        &models.NodeRetryConfig{MaxRetries: 2, RetryInterval: time.Millisecond, BackoffStrategy: "fixed"},
+			failures:     5,
+			errMsg:       "503 service unavailable",
+			wantStatus:   models.ExecutionStatusFailed,
+			wantAttempts: 3,
+		},
+		{
+			name:         "stop condition gives up immediately",
+			retry:        &models.NodeRetryConfig{MaxRetries: 3, RetryInterval: time.Millisecond, StopConditions: []string{"invalid"}},
+			failures:     5,
+			errMsg:       "invalid api token",
+			wantStatus:   models.ExecutionStatusFailed,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			attempts := 0
+			plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+				run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					attempts++
+					if attempts <= tt.failures {
+						return nil, fmt.Errorf("%s", tt.errMsg)
+					}
+					return map[string]interface{}{"ok": true}, nil
+				},
+			})
+
+			engine, callback := newTestEngine(t, 1)
+			workflow := testWorkflow(plugin, []string{"api"})
+			workflow.Nodes["api"].Config.RetryConfig = tt.retry
+			execution := &models.Execution{ID: "exec-retry", WorkflowID: workflow.ID}
+			started := time.Now()
+			if err := engine.ExecuteWorkflow(context.Background(), workflow, execution); err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+
+			// 退避等待期间节点状态可见为重试中
+			if tt.minElapsed > 0 {
+				for deadline := time.Now().Add(engineTestTimeout); ; time.Sleep(time.Millisecond) {
+					status, err := engine.GetExecutionStatus(execution.ID)
+					if err == nil && status.NodeStates["api"] == models.NodeStatusRetrying {
+						break
+					}
+					if err != nil || time.Now().After(deadline) {
+						t.Fatalf("node api never reported %s: %v", models.NodeStatusRetrying, err)
+					}
+				}
+			}
+
+			result := callback.wait(t)
+			if result.Status != tt.wantStatus {
+				t.Fatalf("status = %s (%s), want %s", result.Status, result.ErrorMsg, tt.wantStatus)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if elapsed := time.Since(started); elapsed < tt.minElapsed {
+				t.Errorf("execution took %v, want backoff of at least %v", elapsed, tt.minElapsed)
+			}
+
+			record := callback.record("api")
+			if record.RetryCount != tt.wantAttempts-1 {
+				t.Errorf("record retry count = %d, want %d", record.RetryCount, tt.wantAttempts-1)
+			}
+			if failed := tt.wantStatus == models.ExecutionStatusFailed; failed != strings.Contains(record.ErrorMsg, tt.errMsg) {
+				t.Errorf("record error = %q, want last error only when the node failed", record.ErrorMsg)
+			}
+		})
+	}
+}
+
+func TestWorkflowEnginePassesDataBetweenPorts(t *testing.T) {
+	rows := map[string][]interface{}{
+		"extract_eu": {map[string]interface{}{"id": 1, "amount": "10"}, map[string]interface{}{"id": 2, "amount": "250"}},
+		"extract_us": {map[string]interface{}{"id": 3, "amount": "40"}, map[string]interface{}{"id": 4, "amount": "900"}},
+	}
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			return map[string]interface{}{"rows": rows[nodeID], "count": len(rows[nodeID])}, nil
+		},
+		inputs: []nodes.PortDefinition{{ID: "records", DataType: nodes.DataTypeArray, Multiple: true}},
+		outputs: []nodes.PortDefinition{
+			{ID: "rows", DataType: nodes.DataTypeArray},
+			{ID: "count", DataType: nodes.DataTypeNumber},
+		},
+	})
+
+	engine, callback := newTestEngine(t, 2)
+	workflow := testWorkflow(plugin, []string{"extract_eu", "extract_us", "merge", "report"},
+		"extract_eu->merge", "extract_us->merge", "extract_eu->report")
+	// 美区数据沿边转换金额类型并过滤小额记录
+	workflow.Edges[1].Config = &models.EdgeConfig{Enabled: true, DataMapping: &models.EdgeDataMapping{
+		TransformRules: []models.TransformRule{{Field: "amount", Type: "convert", Expression: "float"}},
+		FilterRules:    []models.FilterRule{{Field: "amount", Operator: "gt", Value: 100}},
+	}}
+	workflow.Edges[2].FromPort = "count"
+	workflow.Edges[2].ToPort = "total"
+	result := executeTestWorkflow(t, engine, callback, workflow)
+
+	if result.Status != models.ExecutionStatusCompleted {
+		t.Fatalf("status = %s (%s), want completed", result.Status, result.ErrorMsg)
+	}
+
+	// 未指定端口时从首个输出端口传到首个输入端口，多值端口按边顺序汇总为列表
+	wantMerge := map[string]interface{}{"records": []interface{}{
+		rows["extract_eu"],
+		[]interface{}{map[string]interface{}{"id": 4, "amount": 900.0}},
+	}}
+	if got := callback.record("merge").Input; !reflect.DeepEqual(got, wantMerge) {
+		t.Errorf("input of merge = %v, want %v", got, wantMerge)
+	}
+	if got, want := callback.record("report").Input, map[string]interface{}{"total": 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("input of report = %v, want %v", got, want)
+	}
+}
+
+func TestWorkflowEngineResumesFromCheckpointOnNewEngine(t *testing.T) {
+	var mu sync.Mutex
+	runs := map[string]int{}
+	release := make(chan struct{})
+	running := make(chan struct{}, 1)
+
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			mu.Lock()
+			runs[nodeID]++
+			first := runs[nodeID] == 1
+			mu.Unlock()
+			// 第一个引擎上 b 一直在途，模拟重启时被中断的节点
+			if nodeID == "b" && first {
+				running <- struct{}{}
+				<-release
+			}
+			return map[string]interface{}{"path": fmt.Sprint(input.Data["path"], "/", nodeID)}, nil
+		},
+	})
+
+	workflow := testWorkflow(plugin, []string{"a", "b", "c"}, "a->b", "b->c")
+	execution := &models.Execution{ID: "exec-resume", WorkflowID: workflow.ID}
+
+	before, beforeCallback := newTestEngine(t, 2)
+	if err := before.ExecuteWorkflow(context.Background(), workflow, execution); err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+	<-running
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if ids := before.Drain(ctx); len(ids) != 1 {
+		t.Fatalf("Drain() = %v, want the execution left in flight", ids)
+	}
+	data, err := beforeCallback.lastCheckpoint(t).Encode(true)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	close(release)
+	beforeCallback.waitReleased(t)
+
+	// 新引擎从持久化的检查点恢复：已完成的 a 不再执行，其输出仍传给 b
+	after, afterCallback := newTestEngine(t, 2)
+	execution.CheckpointData = data
+	if err := after.ResumeWorkflow(context.Background(), workflow, execution); err != nil {
+		t.Fatalf("ResumeWorkflow() error = %v", err)
+	}
+	result := afterCallback.wait(t)
+
+	if result.Status != models.ExecutionStatusCompleted {
+		t.Fatalf("status = %s (%s), want completed", result.Status, result.ErrorMsg)
+	}
+	if want := map[string]int{"a": 1, "b": 2, "c": 1}; !reflect.DeepEqual(runs, want) {
+		t.Errorf("runs = %v, want %v", runs, want)
+	}
+	if got := result.Output["path"]; got != "<nil>/a/b/c" {
+		t.Errorf("output path = %v, want data carried through the restart", got)
+	}
+	if afterCallback.record("a") != nil {
+		t.Error("resumed execution rewrote the record of completed node a")
+	}
+}
+
+func TestWorkflowEngineEvaluatesEdgeExpressions(t *testing.T) {
+	plugin := registerEngineTestPlugin(t, &engineTestPlugin{
+		run: func(ctx context.Context, nodeID string, input *nodes.NodeInput) (map[string]interface{}, error) {
+			if nodeID != "score" {
+				return nil, nil
+			}
+			return map[string]interface{}{
+				"data": []interface{}{map[string]interface{}{"amount": 120.75, "region": "eu"}},
+				"tier": "Gold",
+			}, nil
+		},
+	})
+
+	conditions := map[string]*models.EdgeCondition{
+		"large": {
+			Expression: `score.output.data[0].amount > params.threshold && nodes.score.data[0].region in ["eu", "uk"]`,
+			Parameters: map[string]interface{}{"threshold": 100.5},
+		},
+		"small":  {Expression: `score.output.data[0].amount <= threshold`, Parameters: map[string]interface{}{"threshold": 100.5}},
+		"vip":    {Expression: `lower(score.tier) == "gold" && !isNull(score.tier)`},
+		"review": {Expression: `score.tier > 1`, DefaultValue: true}, // 类型不匹配求值失败时取默认值
+		"audit":  {Expression: `score.tier > 1`, DefaultValue: false},
+	}
+	targets := []string{"large", "small", "vip", "review", "audit"}
+
+	engine, callback := newTestEngine(t, 2)
+	edges := make([]string, 0, len(targets))
+	for _, target := range targets {
+		edges = append(edges, "score->"+target)
+	}
+	workflow := testWorkflow(plugin, append([]string{"score"}, targets...), edges...)
+	for _, edge := range workflow.Edges {
+		condition := conditions[edge.ToNodeID]
+		condition.Enabled = true
+		edge.Type = models.EdgeTypeConditional
+		edge.Config = &models.EdgeConfig{Enabled: true, Condition: condition}
+	}
+	result := executeTestWorkflow(t, engine, callback, workflow)
+
+	if result.Status != models.ExecutionStatusCompleted {
+		t.Fatalf("status = %s (%s), want completed", result.Status, result.ErrorMsg)
+	}
+	want := map[string]models.ExecutionStatus{
+		"large":  models.ExecutionStatusCompleted,
+		"small":  models.ExecutionStatusSkipped,
+		"vip":    models.ExecutionStatusCompleted,
+		"review": models.ExecutionStatusCompleted,
+		"audit":  models.ExecutionStatusSkipped,
+	}
+	for nodeID, status := range want {
+		if record := callback.record(nodeID); record == nil || record.Status != status {
+			t.Errorf("record of %s = %+v, want %s", nodeID, record, status)
+		}
+	}
+}
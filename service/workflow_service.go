@@ -6,7 +6,7 @@
  * @stateFlow workflow_states: inactive -> active -> paused -> disabled
  * @rules 工作流状态变更必须遵循状态机规则，调度配置必须经过验证
  * @dependencies service/models/workflow.go, service/database/database.go
- * @refs service/execution_service.go, pkg/scheduler/scheduler.go
+ * @refs service/execution_service.go, service/simple_scheduler.go
  */
 
 package service
@@ -14,6 +14,7 @@ package service
 import (
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"flow-service/service/models"
@@ -25,6 +26,7 @@ import (
 type WorkflowService struct {
 	db        *gorm.DB
 	scheduler *SimpleScheduler
+	validator *WorkflowValidator
 }
 
 // NewWorkflowService 创建工作流服务实例
@@ -32,9 +34,24 @@ func NewWorkflowService(db *gorm.DB, scheduler *SimpleScheduler) *WorkflowServic
 	return &WorkflowService{
 		db:        db,
 		scheduler: scheduler,
+		validator: NewWorkflowValidator(),
 	}
 }
 
+// ValidateWorkflow 校验工作流图，返回按节点和边定位的错误和警告
+func (s *WorkflowService) ValidateWorkflow(workflow *models.Workflow) *WorkflowValidationResult {
+	return s.validator.Validate(workflow)
+}
+
+// validateGraph 校验工作流图，存在错误时返回 WorkflowValidationError
+func (s *WorkflowService) validateGraph(workflow *models.Workflow) error {
+	result := s.validator.Validate(workflow)
+	if !result.Valid {
+		return &WorkflowValidationError{Result: result}
+	}
+	return nil
+}
+
 // CreateWorkflow 创建工作流
 func (s *WorkflowService) CreateWorkflow(workflow *models.Workflow) error {
 	// 验证工作流
@@ -42,6 +59,11 @@ func (s *WorkflowService) CreateWorkflow(workflow *models.Workflow) error {
 		return fmt.Errorf("workflow validation failed: %w", err)
 	}
 
+	// 验证工作流图
+	if err := s.validateGraph(workflow); err != nil {
+		return err
+	}
+
 	// 设置默认值
 	if workflow.Status == "" {
 		workflow.Status = models.WorkflowStatusInactive
@@ -124,6 +146,12 @@ func (s *WorkflowService) UpdateWorkflow(workflow *models.Workflow) error {
 	if workflow.Edges != nil {
 		existingWorkflow.Edges = workflow.Edges
 	}
+	if workflow.Parameters != nil {
+		existingWorkflow.Parameters = workflow.Parameters
+	}
+	if workflow.Outputs != nil {
+		existingWorkflow.Outputs = workflow.Outputs
+	}
 	if workflow.Schedule != nil {
 		existingWorkflow.Schedule = workflow.Schedule
 	}
@@ -142,6 +170,11 @@ func (s *WorkflowService) UpdateWorkflow(workflow *models.Workflow) error {
 		return fmt.Errorf("workflow validation failed: %w", err)
 	}
 
+	// 验证更新后的工作流图
+	if err := s.validateGraph(existingWorkflow); err != nil {
+		return err
+	}
+
 	// 更新时间
 	existingWorkflow.UpdatedAt = time.Now()
 
@@ -246,6 +279,11 @@ func (s *WorkflowService) ActivateWorkflow(id string) error {
 		return fmt.Errorf("invalid state transition: %w", err)
 	}
 
+	// 激活前验证工作流图
+	if err := s.validateGraph(workflow); err != nil {
+		return err
+	}
+
 	oldStatus := workflow.Status
 	// 更新状态
 	workflow.Status = models.WorkflowStatusActive
@@ -435,6 +473,19 @@ func (s *WorkflowService) UpdateWorkflowStatistics(id string, execTime time.Dura
 	return nil
 }
 
+// GetWorkflowParameters 获取工作流输入参数定义
+func (s *WorkflowService) GetWorkflowParameters(id string) ([]*models.WorkflowParameter, error) {
+	workflow, err := s.GetWorkflow(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if workflow.Parameters == nil {
+		return []*models.WorkflowParameter{}, nil
+	}
+	return workflow.Parameters, nil
+}
+
 // GetWorkflowStatistics 获取工作流统计信息
 func (s *WorkflowService) GetWorkflowStatistics(id string) (*models.WorkflowStatistics, error) {
 	workflow, err := s.GetWorkflow(id)
@@ -445,22 +496,45 @@ func (s *WorkflowService) GetWorkflowStatistics(id string) (*models.WorkflowStat
 	return workflow.Statistics, nil
 }
 
-// scheduleWorkflow 调度工作流
+// scheduleWorkflow 将工作流加入调度器，已调度时按新配置替换，手动调度的工作流不加入调度器
 func (s *WorkflowService) scheduleWorkflow(workflow *models.Workflow) error {
-	if workflow.Schedule == nil || !workflow.Schedule.Enabled {
+	if workflow.Schedule == nil || !workflow.Schedule.Enabled || workflow.Schedule.Type == models.ScheduleTypeManual {
+		return nil
+	}
+	if s.scheduler == nil {
 		return nil
 	}
 
-	// 创建任务对象用于调度（临时解决方案，后续需要调整）
-	// 注意：这里需要根据实际的调度器接口进行调整
-	// 目前暂时跳过实际调度，返回nil
-	return nil
+	return s.scheduler.AddTask(workflow.ID, workflow)
 }
 
-// unscheduleWorkflow 取消工作流调度
+// unscheduleWorkflow 取消工作流调度，未调度的工作流直接返回
 func (s *WorkflowService) unscheduleWorkflow(workflow *models.Workflow) error {
-	// 目前暂时跳过实际取消调度，返回nil
-	// 后续需要根据调度器接口调整
+	if s.scheduler == nil {
+		return nil
+	}
+
+	if err := s.scheduler.RemoveTask(workflow.ID); err != nil {
+		log.Printf("Workflow %s is not scheduled: %v", workflow.ID, err)
+	}
+	return nil
+}
+
+// RestoreSchedules 服务启动时将活跃且启用调度的工作流重新加入调度器
+func (s *WorkflowService) RestoreSchedules() error {
+	var workflows []*models.Workflow
+	if err := s.db.Where("status = ?", models.WorkflowStatusActive).Find(&workflows).Error; err != nil {
+		return fmt.Errorf("failed to list active workflows: %w", err)
+	}
+
+	for _, workflow := range workflows {
+		if !workflow.CanExecute() {
+			continue
+		}
+		if err := s.scheduleWorkflow(workflow); err != nil {
+			log.Printf("Failed to restore schedule of workflow %s: %v", workflow.ID, err)
+		}
+	}
 	return nil
 }
 
@@ -475,7 +549,13 @@ func (s *WorkflowService) validateSchedule(schedule *models.WorkflowSchedule) er
 		if schedule.CronExpression == "" {
 			return errors.New("cron_expression is required for cron schedule")
 		}
-		// 这里可以添加 cron 表达式验证逻辑
+		cron, err := parseCronExpression(schedule.CronExpression)
+		if err != nil {
+			return err
+		}
+		if cron.Next(time.Now()).IsZero() {
+			return fmt.Errorf("cron expression %q never fires", schedule.CronExpression)
+		}
 	case models.ScheduleTypeInterval:
 		if schedule.Interval <= 0 {
 			return errors.New("interval must be positive for interval schedule")
@@ -497,6 +577,12 @@ func (s *WorkflowService) validateSchedule(schedule *models.WorkflowSchedule) er
 		return errors.New("max_instances must be positive")
 	}
 
+	if schedule.Timezone != "" {
+		if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", schedule.Timezone, err)
+		}
+	}
+
 	if schedule.StartTime != nil && schedule.EndTime != nil {
 		if schedule.EndTime.Before(*schedule.StartTime) {
 			return errors.New("end_time must be after start_time")
@@ -0,0 +1,247 @@
+/**
+ * @module workflow_simulator
+ * @description 工作流模拟运行，使用真实调度逻辑执行工作流，指定节点返回模拟输出，不持久化任何数据
+ * @architecture 执行引擎扩展，模拟器替代执行回调收集节点记录，并在节点执行前拦截需要模拟或屏蔽的节点
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow simulation_flow: prepare -> schedule -> mock/stub/execute nodes -> collect result
+ * @rules 模拟执行不注册到引擎、不写检查点和执行记录；输出类节点、子流程节点和非只读的数据源节点未提供模拟输出时被屏蔽；
+ *        延迟不实际等待
+ * @dependencies service/workflow_engine.go, service/nodes/registry.go
+ * @refs service/execution_service.go, api/controllers/workflow_controller.go
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"flow-service/service/models"
+	"flow-service/service/nodes"
+)
+
+// defaultSimulationTimeout 请求和工作流均未配置超时时模拟运行的超时时间
+const defaultSimulationTimeout = 5 * time.Minute
+
+// 模拟节点的处理方式
+const (
+	SimulationModeMock = "mock" // 使用请求提供的模拟输出
+	SimulationModeStub = "stub" // 屏蔽对外写入，原样输出输入数据
+)
+
+// SimulationResult 模拟运行结果
+type SimulationResult struct {
+	Status         models.ExecutionStatus           `json:"status"`
+	ErrorMsg       string                           `json:"error_msg,omitempty"`
+	Output         map[string]interface{}           `json:"output,omitempty"`
+	NodeStates     map[string]models.NodeStatusEnum `json:"node_states"`
+	EdgeStates     map[string]EdgeState             `json:"edge_states"`               // 边ID -> taken/pruned，未出现的边未被决议
+	Branches       map[string]string                `json:"branches,omitempty"`        // 条件节点ID -> 选中的分支
+	SimulatedNodes map[string]string                `json:"simulated_nodes,omitempty"` // 节点ID -> mock/stub
+	Records        []*models.ExecutionNodeRecord    `json:"records"`                   // 按首次写入顺序排列的节点执行记录，含输入、输出和被跳过的节点
+	Duration       time.Duration                    `json:"duration" swaggertype:"integer"`
+}
+
+// executionSimulator 模拟运行状态，同时作为执行回调在内存中收集节点记录
+type executionSimulator struct {
+	mocks     map[string]map[string]interface{}
+	mu        sync.Mutex
+	records   map[string]*models.ExecutionNodeRecord
+	order     []string
+	simulated map[string]string
+}
+
+// newExecutionSimulator 创建模拟运行状态
+func newExecutionSimulator(mocks map[string]map[string]interface{}) *executionSimulator {
+	return &executionSimulator{
+		mocks:     mocks,
+		records:   make(map[string]*models.ExecutionNodeRecord),
+		simulated: make(map[string]string),
+	}
+}
+
+// SaveNodeRecord 在内存中保存节点执行记录
+func (s *executionSimulator) SaveNodeRecord(executionID string, record *models.ExecutionNodeRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.records[record.NodeID]; !exists {
+		s.order = append(s.order, record.NodeID)
+	}
+	s.records[record.NodeID] = record
+	return nil
+}
+
+// FinishExecution 模拟运行结果由调用方直接读取，不持久化
+func (s *executionSimulator) FinishExecution(executionID string, result *ExecutionResult) error {
+	return nil
+}
+
+// SaveCheckpoint 模拟运行不保存检查点
+func (s *executionSimulator) SaveCheckpoint(executionID string, checkpoint string) error {
+	return nil
+}
+
+// StartSubExecution 模拟运行不创建子执行
+func (s *executionSimulator) StartSubExecution(request *SubExecutionRequest) (string, error) {
+	return "", fmt.Errorf("sub executions are not started during simulation, mock node %s instead", request.ParentNodeID)
+}
+
+// GetExecution 模拟运行不查询执行记录
+func (s *executionSimulator) GetExecution(executionID string) (*models.Execution, error) {
+	return nil, fmt.Errorf("execution %s is not available during simulation", executionID)
+}
+
+// CancelExecution 模拟运行没有子执行需要取消
+func (s *executionSimulator) CancelExecution(executionID string) error {
+	return nil
+}
+
+// ExecutionReleased 模拟执行不占用并发槽位
+func (s *executionSimulator) ExecutionReleased(executionID string) {}
+
+// simulateNode 节点有模拟输出时返回模拟输出，可能对外写入的节点被屏蔽，返回false表示正常执行节点
+func (s *executionSimulator) simulateNode(registry *nodes.NodeRegistry, nodeID string, node *models.Node, inputData map[string]interface{}) (*nodes.NodeOutput, bool) {
+	if mock, exists := s.mocks[nodeID]; exists {
+		s.markSimulated(nodeID, SimulationModeMock)
+		data := make(map[string]interface{}, len(mock))
+		for key, value := range mock {
+			data[key] = value
+		}
+		return &nodes.NodeOutput{
+			Data:    data,
+			Logs:    []string{"模拟运行：使用模拟输出"},
+			Success: true,
+		}, true
+	}
+
+	if node.Type != models.NodeTypeSubDAG && !hasSideEffects(registry, node) {
+		return nil, false
+	}
+
+	s.markSimulated(nodeID, SimulationModeStub)
+	data := make(map[string]interface{}, len(inputData))
+	for key, value := range inputData {
+		data[key] = value
+	}
+	if node.Type == models.NodeTypeSubDAG {
+		var payload interface{} = inputData
+		if value, ok := inputData[nodes.PortSubWorkflowInput]; ok {
+			payload = value
+		}
+		data = map[string]interface{}{
+			nodes.PortSubWorkflowOutput:      payload,
+			nodes.PortSubWorkflowExecutionID: "",
+		}
+	}
+	return &nodes.NodeOutput{
+		Data:    data,
+		Logs:    []string{"模拟运行：节点未实际执行，原样输出输入数据"},
+		Success: true,
+	}, true
+}
+
+// hasSideEffects 判断节点执行是否可能对外写入：输出类节点总是写入，数据处理和控制节点不访问外部系统，
+// 其余节点只有插件按当前配置声明只读时才实际执行
+func hasSideEffects(registry *nodes.NodeRegistry, node *models.Node) bool {
+	plugin, err := registry.Get(node.Plugin)
+	if err != nil {
+		// 未注册的插件执行时报错，不会对外写入
+		return false
+	}
+
+	switch plugin.GetMetadata().Category {
+	case nodes.CategoryTransform, nodes.CategoryControl, nodes.CategoryLogic:
+		return false
+	case nodes.CategoryOutput:
+		return true
+	}
+
+	checker, ok := plugin.(nodes.ReadOnlyChecker)
+	if !ok {
+		return true
+	}
+	var pluginConfig map[string]interface{}
+	if node.Config != nil {
+		pluginConfig = node.Config.PluginConfig
+	}
+	return !checker.IsReadOnly(pluginConfig)
+}
+
+// markSimulated 记录节点的模拟方式
+func (s *executionSimulator) markSimulated(nodeID string, mode string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.simulated[nodeID] = mode
+}
+
+// SimulateWorkflow 模拟运行工作流并同步返回结果：调度逻辑与正式执行一致，
+// mocks 中的节点直接返回模拟输出（按输出端口ID索引），执行不注册到引擎、不持久化
+func (e *WorkflowEngine) SimulateWorkflow(ctx context.Context, workflow *models.Workflow, execution *models.Execution, mocks map[string]map[string]interface{}) (*SimulationResult, error) {
+	if e.GetStatus() != EngineStatusRunning {
+		return nil, fmt.Errorf("engine is not running")
+	}
+	for nodeID := range mocks {
+		if _, exists := workflow.Nodes[nodeID]; !exists {
+			return nil, fmt.Errorf("mocked node not found in workflow: %s", nodeID)
+		}
+	}
+
+	if execution.StartedAt == nil {
+		now := time.Now()
+		execution.StartedAt = &now
+	}
+	if e.getExecutionTimeout(workflow, execution) == 0 {
+		execution.Timeout = defaultSimulationTimeout
+	}
+
+	simulator := newExecutionSimulator(mocks)
+	execCtx := newExecutionContext(ctx, workflow, execution)
+	execCtx.simulator = simulator
+	e.initExecutionContext(execCtx)
+	defer execCtx.cancel()
+
+	log.Printf("Simulating workflow %s as execution %s", workflow.ID, execution.ID)
+	err := e.executeWorkflowInternal(execCtx, nil)
+	e.finishExecution(execCtx, err)
+
+	execCtx.mu.RLock()
+	defer execCtx.mu.RUnlock()
+
+	result := &SimulationResult{
+		Status:     execCtx.result.Status,
+		ErrorMsg:   execCtx.result.ErrorMsg,
+		Output:     execCtx.result.Output,
+		NodeStates: make(map[string]models.NodeStatusEnum, len(execCtx.NodeStates)),
+		EdgeStates: make(map[string]EdgeState, len(execCtx.EdgeStates)),
+		Branches:   make(map[string]string),
+		Duration:   time.Since(*execution.StartedAt),
+	}
+	for nodeID, state := range execCtx.NodeStates {
+		result.NodeStates[nodeID] = state
+	}
+	for edgeID, state := range execCtx.EdgeStates {
+		result.EdgeStates[edgeID] = state
+	}
+	for nodeID, node := range workflow.Nodes {
+		if node.Type != models.NodeTypeCondition {
+			continue
+		}
+		if branch, ok := execCtx.NodeOutputs[nodeID][nodes.PortConditionBranch].(string); ok {
+			result.Branches[nodeID] = branch
+		}
+	}
+
+	simulator.mu.Lock()
+	defer simulator.mu.Unlock()
+	result.SimulatedNodes = simulator.simulated
+	result.Records = make([]*models.ExecutionNodeRecord, 0, len(simulator.order))
+	for _, nodeID := range simulator.order {
+		result.Records = append(result.Records, simulator.records[nodeID])
+	}
+
+	return result, nil
+}
@@ -0,0 +1,785 @@
+/**
+ * @module workflow_validator
+ * @description 工作流图校验器，在保存、激活前和编辑器校验时检查节点、边、端口和插件配置
+ * @architecture 服务层校验组件，基于节点插件注册表的元数据对工作流图做静态检查，不访问数据库
+ * @documentReference ai_docs/refactor_plan.md
+ * @stateFlow validation_flow: nodes -> edges -> ports -> cycles -> outputs -> references -> result
+ * @rules 错误阻止保存和激活，警告只提示；每个问题都关联节点ID或边ID，便于编辑器定位
+ * @dependencies service/models/workflow.go, service/nodes/registry.go, service/expression
+ * @refs service/workflow_service.go, service/workflow_engine.go, api/controllers/workflow_controller.go
+ */
+
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"flow-service/service/config"
+	"flow-service/service/expression"
+	"flow-service/service/models"
+	"flow-service/service/nodes"
+)
+
+// 校验问题严重程度
+const (
+	ValidationSeverityError   = "error"
+	ValidationSeverityWarning = "warning"
+)
+
+// 校验问题代码
+const (
+	ValidationCodeInvalidNode          = "invalid_node"
+	ValidationCodeInvalidEdge          = "invalid_edge"
+	ValidationCodeDanglingEdge         = "dangling_edge"
+	ValidationCodeUnknownPlugin        = "unknown_plugin"
+	ValidationCodeInvalidConfig        = "invalid_config"
+	ValidationCodeUnknownPort          = "unknown_port"
+	ValidationCodeUnwiredRequiredPort  = "unwired_required_port"
+	ValidationCodeIncompatiblePortType = "incompatible_port_type"
+	ValidationCodeMultipleInputs       = "multiple_inputs"
+	ValidationCodeCycle                = "cycle"
+	ValidationCodeIsolatedNode         = "isolated_node"
+	ValidationCodeEmptyWorkflow        = "empty_workflow"
+	ValidationCodeUnknownPool          = "unknown_concurrency_pool"
+	ValidationCodeInvalidExpression    = "invalid_expression"
+	ValidationCodeInvalidTemplate      = "invalid_template"
+	ValidationCodeInvalidOutput        = "invalid_output"
+	ValidationCodeUnknownReference     = "unknown_reference"
+)
+
+// ValidationIssue 校验问题，NodeID/EdgeID 指向出问题的节点或边
+type ValidationIssue struct {
+	Code     string `json:"code" example:"cycle"`
+	Severity string `json:"severity" example:"error"`
+	Message  string `json:"message"`
+	NodeID   string `json:"node_id,omitempty"`
+	EdgeID   string `json:"edge_id,omitempty"`
+	Port     string `json:"port,omitempty"`
+}
+
+// WorkflowValidationResult 工作流校验结果
+type WorkflowValidationResult struct {
+	Valid    bool               `json:"valid"`
+	Errors   []*ValidationIssue `json:"errors"`
+	Warnings []*ValidationIssue `json:"warnings"`
+}
+
+// addError 添加错误
+func (r *WorkflowValidationResult) addError(issue *ValidationIssue) {
+	issue.Severity = ValidationSeverityError
+	r.Errors = append(r.Errors, issue)
+	r.Valid = false
+}
+
+// addWarning 添加警告
+func (r *WorkflowValidationResult) addWarning(issue *ValidationIssue) {
+	issue.Severity = ValidationSeverityWarning
+	r.Warnings = append(r.Warnings, issue)
+}
+
+// WorkflowValidationError 工作流图校验未通过，携带完整的校验结果
+type WorkflowValidationError struct {
+	Result *WorkflowValidationResult
+}
+
+// Error 实现error接口，汇总所有错误信息
+func (e *WorkflowValidationError) Error() string {
+	messages := make([]string, 0, len(e.Result.Errors))
+	for _, issue := range e.Result.Errors {
+		switch {
+		case issue.NodeID != "":
+			messages = append(messages, fmt.Sprintf("node %s: %s", issue.NodeID, issue.Message))
+		case issue.EdgeID != "":
+			messages = append(messages, fmt.Sprintf("edge %s: %s", issue.EdgeID, issue.Message))
+		default:
+			messages = append(messages, issue.Message)
+		}
+	}
+	return fmt.Sprintf("workflow graph is invalid: %s", strings.Join(messages, "; "))
+}
+
+// WorkflowValidator 工作流图校验器
+type WorkflowValidator struct {
+	nodeRegistry     *nodes.NodeRegistry
+	concurrencyPools map[string]bool // 引擎配置中定义的并发池
+}
+
+// NewWorkflowValidator 创建工作流图校验器
+func NewWorkflowValidator() *WorkflowValidator {
+	pools := make(map[string]bool)
+	for _, pool := range config.LoadEngineConfig().ConcurrencyPools {
+		pools[pool.Name] = true
+	}
+	return &WorkflowValidator{
+		nodeRegistry:     nodes.GetRegistry(),
+		concurrencyPools: pools,
+	}
+}
+
+// Validate 校验工作流图，返回按节点/边定位的错误和警告
+func (v *WorkflowValidator) Validate(workflow *models.Workflow) *WorkflowValidationResult {
+	result := &WorkflowValidationResult{
+		Valid:    true,
+		Errors:   []*ValidationIssue{},
+		Warnings: []*ValidationIssue{},
+	}
+
+	if len(workflow.Nodes) == 0 {
+		result.addWarning(&ValidationIssue{
+			Code:    ValidationCodeEmptyWorkflow,
+			Message: "workflow has no nodes",
+		})
+		for _, edge := range workflow.Edges {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeDanglingEdge,
+				Message: "edge references nodes that do not exist",
+				EdgeID:  edge.ID,
+			})
+		}
+		v.validateOutputs(result, workflow, nil)
+		return result
+	}
+
+	nodeIDs := v.sortedNodeIDs(workflow)
+	metadata := make(map[string]*nodes.NodeMetadata, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		if meta := v.validateNode(result, nodeID, workflow.Nodes[nodeID]); meta != nil {
+			metadata[nodeID] = meta
+		}
+	}
+
+	validEdges := v.validateEdges(result, workflow, metadata)
+	v.validateInputPorts(result, workflow, nodeIDs, validEdges, metadata)
+	v.validateCycles(result, nodeIDs, validEdges)
+	v.validateOutputs(result, workflow, metadata)
+	v.validateReferences(result, workflow)
+
+	// 多节点工作流中没有任何连线的节点
+	if len(nodeIDs) > 1 {
+		connected := make(map[string]bool)
+		for _, edge := range validEdges {
+			connected[edge.FromNodeID] = true
+			connected[edge.ToNodeID] = true
+		}
+		for _, nodeID := range nodeIDs {
+			if !connected[nodeID] {
+				result.addWarning(&ValidationIssue{
+					Code:    ValidationCodeIsolatedNode,
+					Message: "node is not connected to any other node",
+					NodeID:  nodeID,
+				})
+			}
+		}
+	}
+
+	return result
+}
+
+// sortedNodeIDs 按ID排序节点，保证校验结果顺序稳定
+func (v *WorkflowValidator) sortedNodeIDs(workflow *models.Workflow) []string {
+	nodeIDs := make([]string, 0, len(workflow.Nodes))
+	for nodeID := range workflow.Nodes {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Strings(nodeIDs)
+	return nodeIDs
+}
+
+// validateNode 校验节点基础字段、插件和插件配置，插件已注册时返回其元数据
+func (v *WorkflowValidator) validateNode(result *WorkflowValidationResult, nodeID string, node *models.Node) *nodes.NodeMetadata {
+	if node == nil {
+		result.addError(&ValidationIssue{
+			Code:    ValidationCodeInvalidNode,
+			Message: "node definition is empty",
+			NodeID:  nodeID,
+		})
+		return nil
+	}
+
+	if node.ID != "" && node.ID != nodeID {
+		result.addError(&ValidationIssue{
+			Code:    ValidationCodeInvalidNode,
+			Message: fmt.Sprintf("node ID %s does not match its key", node.ID),
+			NodeID:  nodeID,
+		})
+	}
+	if node.Type != "" && !node.Type.IsValid() {
+		result.addError(&ValidationIssue{
+			Code:    ValidationCodeInvalidNode,
+			Message: fmt.Sprintf("invalid node type: %s", node.Type),
+			NodeID:  nodeID,
+		})
+	}
+
+	if node.Plugin == "" {
+		result.addError(&ValidationIssue{
+			Code:    ValidationCodeUnknownPlugin,
+			Message: "node plugin cannot be empty",
+			NodeID:  nodeID,
+		})
+		return nil
+	}
+
+	plugin, err := v.nodeRegistry.Get(node.Plugin)
+	if err != nil {
+		result.addError(&ValidationIssue{
+			Code:    ValidationCodeUnknownPlugin,
+			Message: fmt.Sprintf("unknown node plugin: %s", node.Plugin),
+			NodeID:  nodeID,
+		})
+		return nil
+	}
+
+	pluginConfig := map[string]interface{}{}
+	if node.Config != nil && node.Config.PluginConfig != nil {
+		pluginConfig = node.Config.PluginConfig
+	}
+	if err := plugin.Validate(pluginConfig); err != nil {
+		result.addError(&ValidationIssue{
+			Code:    ValidationCodeInvalidConfig,
+			Message: err.Error(),
+			NodeID:  nodeID,
+		})
+	}
+
+	v.validateConfigTemplates(result, nodeID, pluginConfig, plugin.GetMetadata())
+
+	if node.Config != nil && node.Config.ResourceConfig != nil {
+		v.validateResourceConfig(result, nodeID, node.Config.ResourceConfig)
+	}
+	v.validateNodeExpressions(result, nodeID, node)
+
+	return plugin.GetMetadata()
+}
+
+// validateConfigTemplates 检查插件配置中 {{ }} 模板的语法，不支持模板的字段不得包含模板
+func (v *WorkflowValidator) validateConfigTemplates(result *WorkflowValidationResult, nodeID string, pluginConfig map[string]interface{}, metadata *nodes.NodeMetadata) {
+	templateModes := make(map[string]string)
+	if metadata != nil && metadata.ConfigSchema != nil {
+		for _, field := range metadata.ConfigSchema.Properties {
+			templateModes[field.Name] = field.Template
+		}
+	}
+
+	keys := make([]string, 0, len(pluginConfig))
+	for key := range pluginConfig {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := pluginConfig[key]
+		if templateModes[key] == nodes.TemplateNone {
+			if containsTemplate(value) {
+				result.addError(&ValidationIssue{
+					Code:    ValidationCodeInvalidTemplate,
+					Message: fmt.Sprintf("config field %s does not support templates", key),
+					NodeID:  nodeID,
+				})
+			}
+			continue
+		}
+		if path, err := checkTemplateValue(key, value); err != nil {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeInvalidTemplate,
+				Message: fmt.Sprintf("invalid template in config field %s: %v", path, err),
+				NodeID:  nodeID,
+			})
+		}
+	}
+}
+
+// validateResourceConfig 校验节点的并发限制和并发池声明
+func (v *WorkflowValidator) validateResourceConfig(result *WorkflowValidationResult, nodeID string, resource *models.ResourceConfig) {
+	if resource.ConcurrencyLimit < 0 {
+		result.addError(&ValidationIssue{
+			Code:    ValidationCodeInvalidConfig,
+			Message: fmt.Sprintf("concurrency limit cannot be negative: %d", resource.ConcurrencyLimit),
+			NodeID:  nodeID,
+		})
+		return
+	}
+
+	// 未在引擎配置中定义的并发池需要节点声明上限
+	if resource.ConcurrencyPool != "" && !v.concurrencyPools[resource.ConcurrencyPool] && resource.ConcurrencyLimit == 0 {
+		result.addError(&ValidationIssue{
+			Code:    ValidationCodeUnknownPool,
+			Message: fmt.Sprintf("concurrency pool %s is not configured, set concurrency_limit to declare it", resource.ConcurrencyPool),
+			NodeID:  nodeID,
+		})
+	}
+}
+
+// validateNodeExpressions 检查条件节点和 while 循环节点结构化配置中的表达式语法，插件配置中的表达式由插件校验
+func (v *WorkflowValidator) validateNodeExpressions(result *WorkflowValidationResult, nodeID string, node *models.Node) {
+	if node.Config == nil {
+		return
+	}
+
+	check := func(field, source string) {
+		if source == "" {
+			return
+		}
+		if err := expression.Check(source); err != nil {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeInvalidExpression,
+				Message: fmt.Sprintf("invalid %s: %v", field, err),
+				NodeID:  nodeID,
+			})
+		}
+	}
+
+	if conditionConfig := node.Config.ConditionConfig; conditionConfig != nil {
+		check("condition expression", conditionConfig.Expression)
+		for i, branchCase := range conditionConfig.Cases {
+			check(fmt.Sprintf("cases[%d] expression", i), branchCase.Expression)
+		}
+	}
+	if loopConfig := node.Config.LoopConfig; loopConfig != nil {
+		check("loop condition", loopConfig.Condition)
+	}
+}
+
+// validateOutputs 校验工作流输出定义：来源节点和端口必须存在，表达式语法正确
+func (v *WorkflowValidator) validateOutputs(result *WorkflowValidationResult, workflow *models.Workflow, metadata map[string]*nodes.NodeMetadata) {
+	names := make(map[string]bool, len(workflow.Outputs))
+	for i, output := range workflow.Outputs {
+		if output == nil {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeInvalidOutput,
+				Message: fmt.Sprintf("outputs[%d] is empty", i),
+			})
+			continue
+		}
+		if names[output.Name] {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeInvalidOutput,
+				Message: fmt.Sprintf("duplicate output name: %s", output.Name),
+			})
+		}
+		names[output.Name] = true
+
+		if err := output.Validate(); err != nil {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeInvalidOutput,
+				Message: err.Error(),
+				NodeID:  output.NodeID,
+			})
+			continue
+		}
+		if output.NodeID == "" {
+			continue
+		}
+
+		node, exists := workflow.Nodes[output.NodeID]
+		if !exists {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeInvalidOutput,
+				Message: fmt.Sprintf("output %s references node %s that does not exist", output.Name, output.NodeID),
+			})
+			continue
+		}
+
+		// 条件节点的输出端口可以是自定义分支名
+		meta := metadata[output.NodeID]
+		if output.Port == "" || meta == nil || node.Type == models.NodeTypeCondition {
+			continue
+		}
+		if findPort(meta.OutputPorts, output.Port) == nil {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeUnknownPort,
+				Message: fmt.Sprintf("output %s references unknown output port %q", output.Name, output.Port),
+				NodeID:  output.NodeID,
+				Port:    output.Port,
+			})
+		}
+	}
+}
+
+// validateReferences 检查边条件、节点表达式、配置模板和输出表达式中引用的节点是否存在，
+// 按 nodes.<节点ID> 和 <节点ID>.output 两种形式识别节点引用，与变量或参数同名的根变量不视为节点
+func (v *WorkflowValidator) validateReferences(result *WorkflowValidationResult, workflow *models.Workflow) {
+	variables := make(map[string]bool)
+	if workflow.Config != nil {
+		for name := range workflow.Config.Variables {
+			variables[name] = true
+		}
+	}
+	for _, parameter := range workflow.Parameters {
+		if parameter != nil {
+			variables[parameter.Name] = true
+		}
+	}
+
+	// missingNodes 返回引用路径中不存在的节点，params 为边条件参数
+	missingNodes := func(refs [][]string, params map[string]interface{}) []string {
+		var missing []string
+		reported := make(map[string]bool)
+		for _, ref := range refs {
+			if len(ref) < 2 {
+				continue
+			}
+			nodeID := ref[1]
+			if ref[0] != "nodes" {
+				if _, isParam := params[ref[0]]; ref[1] != "output" || variables[ref[0]] || isParam {
+					continue
+				}
+				nodeID = ref[0]
+			}
+			if _, exists := workflow.Nodes[nodeID]; !exists && !reported[nodeID] {
+				reported[nodeID] = true
+				missing = append(missing, nodeID)
+			}
+		}
+		return missing
+	}
+	references := func(source string) [][]string {
+		if source == "" {
+			return nil
+		}
+		program, err := expression.Compile(source)
+		if err != nil {
+			return nil // 语法错误已单独报告
+		}
+		return program.References()
+	}
+
+	for _, edge := range workflow.Edges {
+		if edge == nil || edge.Config == nil || edge.Config.Condition == nil {
+			continue
+		}
+		condition := edge.Config.Condition
+		for _, nodeID := range missingNodes(references(condition.Expression), condition.Parameters) {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeUnknownReference,
+				Message: fmt.Sprintf("condition references node %s that does not exist", nodeID),
+				EdgeID:  edge.ID,
+			})
+		}
+	}
+
+	for _, nodeID := range v.sortedNodeIDs(workflow) {
+		node := workflow.Nodes[nodeID]
+		if node == nil || node.Config == nil {
+			continue
+		}
+		refs := templateReferences(node.Config.PluginConfig)
+		if conditionConfig := node.Config.ConditionConfig; conditionConfig != nil {
+			refs = append(refs, references(conditionConfig.Expression)...)
+			for _, branchCase := range conditionConfig.Cases {
+				refs = append(refs, references(branchCase.Expression)...)
+			}
+		}
+		if loopConfig := node.Config.LoopConfig; loopConfig != nil {
+			refs = append(refs, references(loopConfig.Condition)...)
+		}
+		for _, missing := range missingNodes(refs, nil) {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeUnknownReference,
+				Message: fmt.Sprintf("expression references node %s that does not exist", missing),
+				NodeID:  nodeID,
+			})
+		}
+	}
+
+	for _, output := range workflow.Outputs {
+		if output == nil {
+			continue
+		}
+		for _, missing := range missingNodes(references(output.Expression), nil) {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeUnknownReference,
+				Message: fmt.Sprintf("output %s references node %s that does not exist", output.Name, missing),
+			})
+		}
+	}
+}
+
+// validateEdges 校验边的连接、类型和端口，返回两端节点都存在的启用边
+func (v *WorkflowValidator) validateEdges(result *WorkflowValidationResult, workflow *models.Workflow, metadata map[string]*nodes.NodeMetadata) []*models.Edge {
+	validEdges := make([]*models.Edge, 0, len(workflow.Edges))
+	edgeIDs := make(map[string]bool, len(workflow.Edges))
+
+	for i, edge := range workflow.Edges {
+		if edge == nil {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeInvalidEdge,
+				Message: fmt.Sprintf("edge at index %d is empty", i),
+			})
+			continue
+		}
+
+		if edge.ID == "" {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeInvalidEdge,
+				Message: fmt.Sprintf("edge at index %d has no ID", i),
+			})
+		} else if edgeIDs[edge.ID] {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeInvalidEdge,
+				Message: "duplicate edge ID",
+				EdgeID:  edge.ID,
+			})
+		}
+		edgeIDs[edge.ID] = true
+
+		if edge.Type != "" && !edge.Type.IsValid() {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeInvalidEdge,
+				Message: fmt.Sprintf("invalid edge type: %s", edge.Type),
+				EdgeID:  edge.ID,
+			})
+		}
+		if edge.IsConditional() {
+			if edge.Config == nil || edge.Config.Condition == nil || edge.Config.Condition.Expression == "" {
+				result.addError(&ValidationIssue{
+					Code:    ValidationCodeInvalidEdge,
+					Message: "conditional edge must have condition expression",
+					EdgeID:  edge.ID,
+				})
+			} else if err := edge.Config.Condition.Check(); err != nil {
+				result.addError(&ValidationIssue{
+					Code:    ValidationCodeInvalidExpression,
+					Message: fmt.Sprintf("invalid condition: %v", err),
+					EdgeID:  edge.ID,
+				})
+			}
+		}
+
+		_, fromExists := workflow.Nodes[edge.FromNodeID]
+		_, toExists := workflow.Nodes[edge.ToNodeID]
+		if !fromExists {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeDanglingEdge,
+				Message: fmt.Sprintf("source node %q does not exist", edge.FromNodeID),
+				EdgeID:  edge.ID,
+			})
+		}
+		if !toExists {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeDanglingEdge,
+				Message: fmt.Sprintf("target node %q does not exist", edge.ToNodeID),
+				EdgeID:  edge.ID,
+			})
+		}
+		if !fromExists || !toExists {
+			continue
+		}
+
+		if edge.FromNodeID == edge.ToNodeID {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeCycle,
+				Message: "edge cannot connect node to itself",
+				EdgeID:  edge.ID,
+				NodeID:  edge.FromNodeID,
+			})
+			continue
+		}
+
+		if !edge.IsEnabled() {
+			continue
+		}
+		validEdges = append(validEdges, edge)
+
+		// 失败处理边传递失败详情，字段映射边由映射命名目标端口，均不按端口校验
+		if edge.IsFailureHandler() || edge.HasFieldMapping() {
+			continue
+		}
+		v.validateEdgePorts(result, workflow, edge, metadata)
+	}
+
+	return validEdges
+}
+
+// validateEdgePorts 校验边两端的端口存在且数据类型兼容，端口解析规则与执行引擎一致
+func (v *WorkflowValidator) validateEdgePorts(result *WorkflowValidationResult, workflow *models.Workflow, edge *models.Edge, metadata map[string]*nodes.NodeMetadata) {
+	fromMeta := metadata[edge.FromNodeID]
+	toMeta := metadata[edge.ToNodeID]
+
+	var fromPort *nodes.PortDefinition
+	if fromMeta != nil {
+		fromPortID := edge.FromPort
+		if fromPortID == "" && edge.Type == models.EdgeTypeLoop {
+			fromPortID = nodes.PortLoopItem
+		}
+
+		switch {
+		case fromPortID == "":
+			if len(fromMeta.OutputPorts) > 0 {
+				fromPort = &fromMeta.OutputPorts[0]
+			}
+		case workflow.Nodes[edge.FromNodeID].Type == models.NodeTypeCondition:
+			// 条件节点的出边端口可以是自定义分支名，分支数据类型与输入一致
+			fromPort = findPort(fromMeta.OutputPorts, fromPortID)
+		default:
+			fromPort = findPort(fromMeta.OutputPorts, fromPortID)
+			if fromPort == nil {
+				result.addError(&ValidationIssue{
+					Code:    ValidationCodeUnknownPort,
+					Message: fmt.Sprintf("source node %s has no output port %q", edge.FromNodeID, fromPortID),
+					EdgeID:  edge.ID,
+					NodeID:  edge.FromNodeID,
+					Port:    fromPortID,
+				})
+			}
+		}
+	}
+
+	var toPort *nodes.PortDefinition
+	if toMeta != nil {
+		if edge.ToPort == "" {
+			if len(toMeta.InputPorts) > 0 {
+				toPort = &toMeta.InputPorts[0]
+			}
+		} else {
+			toPort = findPort(toMeta.InputPorts, edge.ToPort)
+			if toPort == nil {
+				result.addError(&ValidationIssue{
+					Code:    ValidationCodeUnknownPort,
+					Message: fmt.Sprintf("target node %s has no input port %q", edge.ToNodeID, edge.ToPort),
+					EdgeID:  edge.ID,
+					NodeID:  edge.ToNodeID,
+					Port:    edge.ToPort,
+				})
+			}
+		}
+	}
+
+	if fromPort == nil || toPort == nil || compatibleDataTypes(fromPort.DataType, toPort.DataType) {
+		return
+	}
+
+	issue := &ValidationIssue{
+		Code:    ValidationCodeIncompatiblePortType,
+		Message: fmt.Sprintf("output port %s (%s) is not compatible with input port %s (%s)", fromPort.ID, fromPort.DataType, toPort.ID, toPort.DataType),
+		EdgeID:  edge.ID,
+		NodeID:  edge.ToNodeID,
+		Port:    toPort.ID,
+	}
+	// 边上配置了转换规则时数据形状可能被改变，只提示
+	if edge.Config != nil && edge.Config.DataMapping != nil && !edge.Config.DataMapping.PassThrough {
+		result.addWarning(issue)
+		return
+	}
+	result.addError(issue)
+}
+
+// validateInputPorts 校验必需输入端口都有数据来源，非多值端口不被多条边重复写入
+func (v *WorkflowValidator) validateInputPorts(result *WorkflowValidationResult, workflow *models.Workflow, nodeIDs []string, edges []*models.Edge, metadata map[string]*nodes.NodeMetadata) {
+	for _, nodeID := range nodeIDs {
+		meta := metadata[nodeID]
+		if meta == nil || len(meta.InputPorts) == 0 {
+			continue
+		}
+
+		wired := make(map[string]int)
+		mapped := false
+		for _, edge := range edges {
+			if edge.ToNodeID != nodeID || edge.IsFailureHandler() {
+				continue
+			}
+			if edge.HasFieldMapping() {
+				// 字段映射决定目标端口，无法静态确定
+				mapped = true
+				continue
+			}
+			portID := edge.ToPort
+			if portID == "" {
+				portID = meta.InputPorts[0].ID
+			}
+			wired[portID]++
+		}
+
+		node := workflow.Nodes[nodeID]
+		if node.Config != nil && node.Config.InputConfig != nil {
+			for key := range node.Config.InputConfig.Mapping {
+				wired[key]++
+			}
+			for key := range node.Config.InputConfig.Defaults {
+				wired[key]++
+			}
+		}
+
+		for _, port := range meta.InputPorts {
+			if port.Required && wired[port.ID] == 0 && !mapped {
+				result.addError(&ValidationIssue{
+					Code:    ValidationCodeUnwiredRequiredPort,
+					Message: fmt.Sprintf("required input port %q is not connected", port.ID),
+					NodeID:  nodeID,
+					Port:    port.ID,
+				})
+			}
+			if !port.Multiple && wired[port.ID] > 1 {
+				result.addWarning(&ValidationIssue{
+					Code:    ValidationCodeMultipleInputs,
+					Message: fmt.Sprintf("input port %q receives data from multiple sources, only one value is kept", port.ID),
+					NodeID:  nodeID,
+					Port:    port.ID,
+				})
+			}
+		}
+	}
+}
+
+// validateCycles 使用拓扑排序检测环，环上的每个节点报告一个错误
+func (v *WorkflowValidator) validateCycles(result *WorkflowValidationResult, nodeIDs []string, edges []*models.Edge) {
+	inDegree := make(map[string]int, len(nodeIDs))
+	adjacency := make(map[string][]string, len(nodeIDs))
+	for _, edge := range edges {
+		adjacency[edge.FromNodeID] = append(adjacency[edge.FromNodeID], edge.ToNodeID)
+		inDegree[edge.ToNodeID]++
+	}
+
+	queue := make([]string, 0, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		if inDegree[nodeID] == 0 {
+			queue = append(queue, nodeID)
+		}
+	}
+
+	visited := 0
+	for len(queue) > 0 {
+		nodeID := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, next := range adjacency[nodeID] {
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	if visited == len(nodeIDs) {
+		return
+	}
+	for _, nodeID := range nodeIDs {
+		if inDegree[nodeID] > 0 {
+			result.addError(&ValidationIssue{
+				Code:    ValidationCodeCycle,
+				Message: "node is part of a cycle or depends on one",
+				NodeID:  nodeID,
+			})
+		}
+	}
+}
+
+// findPort 按ID查找端口定义
+func findPort(ports []nodes.PortDefinition, portID string) *nodes.PortDefinition {
+	for i := range ports {
+		if ports[i].ID == portID {
+			return &ports[i]
+		}
+	}
+	return nil
+}
+
+// compatibleDataTypes 判断输出端口数据类型能否连接到输入端口，any 或未声明类型与任意类型兼容
+func compatibleDataTypes(from, to string) bool {
+	if from == "" || to == "" || from == nodes.DataTypeAny || to == nodes.DataTypeAny {
+		return true
+	}
+	return from == to
+}
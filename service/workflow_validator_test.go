@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"flow-service/service/models"
+	"flow-service/service/nodes"
+)
+
+// validatorTestPlugin 校验测试用插件，一个输入端口和一个输出端口
+type validatorTestPlugin struct {
+	id string
+}
+
+func (p *validatorTestPlugin) GetMetadata() *nodes.NodeMetadata {
+	return &nodes.NodeMetadata{
+		ID:       p.id,
+		Name:     p.id,
+		Category: "test",
+		Type:     "transform",
+		InputPorts: []nodes.PortDefinition{
+			{ID: "in", DataType: "object"},
+		},
+		OutputPorts: []nodes.PortDefinition{
+			{ID: "out", DataType: "object"},
+		},
+		ConfigSchema: &nodes.ConfigSchema{Type: "object"},
+	}
+}
+
+func (p *validatorTestPlugin) Validate(config map[string]interface{}) error {
+	return nil
+}
+
+func (p *validatorTestPlugin) Execute(ctx context.Context, input *nodes.NodeInput) (*nodes.NodeOutput, error) {
+	return &nodes.NodeOutput{Success: true}, nil
+}
+
+func (p *validatorTestPlugin) GetDynamicData(method string, params map[string]interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+// newTestValidator 创建注册了测试插件的校验器
+func newTestValidator(t *testing.T) *WorkflowValidator {
+	t.Helper()
+	registerValidatorTestPlugin(t)
+	return &WorkflowValidator{nodeRegistry: nodes.GetRegistry(), concurrencyPools: map[string]bool{}}
+}
+
+// registerValidatorTestPlugin 注册校验测试用插件，多个测试共用
+func registerValidatorTestPlugin(t *testing.T) {
+	t.Helper()
+	registry := nodes.GetRegistry()
+	if !registry.IsRegistered("validator_test") {
+		if err := registry.Register(&validatorTestPlugin{id: "validator_test"}); err != nil {
+			t.Fatalf("failed to register test plugin: %v", err)
+		}
+	}
+}
+
+func TestWorkflowValidatorValidate(t *testing.T) {
+	node := func(id string) *models.Node {
+		return &models.Node{ID: id, Plugin: "validator_test"}
+	}
+	edge := func(id, from, to string) *models.Edge {
+		return &models.Edge{ID: id, FromNodeID: from, ToNodeID: to, Type: models.EdgeTypeNormal}
+	}
+
+	type issue struct {
+		code   string
+		nodeID string
+		edgeID string
+	}
+
+	tests := []struct {
+		name         string
+		nodes        map[string]*models.Node
+		edges        []*models.Edge
+		wantErrors   []issue
+		wantWarnings []issue
+	}{
+		{
+			name:  "valid chain",
+			nodes: map[string]*models.Node{"a": node("a"), "b": node("b"), "c": node("c")},
+			edges: []*models.Edge{edge("ab", "a", "b"), edge("bc", "b", "c")},
+		},
+		{
+			name:         "empty workflow",
+			nodes:        map[string]*models.Node{},
+			wantWarnings: []issue{{code: ValidationCodeEmptyWorkflow}},
+		},
+		{
+			name:  "empty workflow with edge",
+			nodes: map[string]*models.Node{},
+			edges: []*models.Edge{edge("ab", "a", "b")},
+			wantErrors: []issue{
+				{code: ValidationCodeDanglingEdge, edgeID: "ab"},
+			},
+			wantWarnings: []issue{{code: ValidationCodeEmptyWorkflow}},
+		},
+		{
+			name:  "dangling source and target",
+			nodes: map[string]*models.Node{"a": node("a"), "b": node("b")},
+			edges: []*models.Edge{edge("ab", "a", "b"), edge("xa", "x", "a"), edge("by", "b", "y")},
+			wantErrors: []issue{
+				{code: ValidationCodeDanglingEdge, edgeID: "xa"},
+				{code: ValidationCodeDanglingEdge, edgeID: "by"},
+			},
+		},
+		{
+			name:  "self loop",
+			nodes: map[string]*models.Node{"a": node("a"), "b": node("b")},
+			edges: []*models.Edge{edge("ab", "a", "b"), edge("bb", "b", "b")},
+			wantErrors: []issue{
+				{code: ValidationCodeCycle, nodeID: "b", edgeID: "bb"},
+			},
+		},
+		{
+			name:  "cycle reports every node on or after it",
+			nodes: map[string]*models.Node{"a": node("a"), "b": node("b"), "c": node("c"), "d": node("d")},
+			edges: []*models.Edge{edge("ab", "a", "b"), edge("bc", "b", "c"), edge("cb", "c", "b"), edge("cd", "c", "d")},
+			wantErrors: []issue{
+				{code: ValidationCodeCycle, nodeID: "b"},
+				{code: ValidationCodeCycle, nodeID: "c"},
+				{code: ValidationCodeCycle, nodeID: "d"},
+			},
+			wantWarnings: []issue{{code: ValidationCodeMultipleInputs, nodeID: "b"}},
+		},
+		{
+			name:  "disabled edge does not form a cycle",
+			nodes: map[string]*models.Node{"a": node("a"), "b": node("b")},
+			edges: []*models.Edge{
+				edge("ab", "a", "b"),
+				{ID: "ba", FromNodeID: "b", ToNodeID: "a", Type: models.EdgeTypeNormal, Status: models.EdgeStatusDisabled},
+			},
+		},
+		{
+			name:  "duplicate edge ID",
+			nodes: map[string]*models.Node{"a": node("a"), "b": node("b"), "c": node("c")},
+			edges: []*models.Edge{edge("e", "a", "b"), edge("e", "b", "c")},
+			wantErrors: []issue{
+				{code: ValidationCodeInvalidEdge, edgeID: "e"},
+			},
+		},
+		{
+			name:  "unknown plugin and isolated node",
+			nodes: map[string]*models.Node{"a": node("a"), "b": node("b"), "c": {ID: "c", Plugin: "missing_plugin"}},
+			edges: []*models.Edge{edge("ab", "a", "b")},
+			wantErrors: []issue{
+				{code: ValidationCodeUnknownPlugin, nodeID: "c"},
+			},
+			wantWarnings: []issue{{code: ValidationCodeIsolatedNode, nodeID: "c"}},
+		},
+		{
+			name:  "unknown ports",
+			nodes: map[string]*models.Node{"a": node("a"), "b": node("b")},
+			edges: []*models.Edge{
+				{ID: "ab", FromNodeID: "a", ToNodeID: "b", FromPort: "nope", ToPort: "in", Type: models.EdgeTypeNormal},
+			},
+			wantErrors: []issue{
+				{code: ValidationCodeUnknownPort, nodeID: "a", edgeID: "ab"},
+			},
+		},
+	}
+
+	validator := newTestValidator(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validator.Validate(&models.Workflow{Nodes: tt.nodes, Edges: tt.edges})
+
+			sortIssues := func(issues []issue) []issue {
+				sort.Slice(issues, func(i, j int) bool {
+					return issues[i].code+issues[i].nodeID+issues[i].edgeID < issues[j].code+issues[j].nodeID+issues[j].edgeID
+				})
+				return issues
+			}
+			collect := func(issues []*ValidationIssue) []issue {
+				got := make([]issue, 0, len(issues))
+				for _, i := range issues {
+					got = append(got, issue{code: i.Code, nodeID: i.NodeID, edgeID: i.EdgeID})
+				}
+				return sortIssues(got)
+			}
+			sorted := func(issues []issue) []issue {
+				return sortIssues(append([]issue{}, issues...))
+			}
+
+			if got, want := collect(result.Errors), sorted(tt.wantErrors); !reflect.DeepEqual(got, want) {
+				t.Errorf("errors = %+v, want %+v", got, want)
+			}
+			if got, want := collect(result.Warnings), sorted(tt.wantWarnings); !reflect.DeepEqual(got, want) {
+				t.Errorf("warnings = %+v, want %+v", got, want)
+			}
+			if result.Valid != (len(tt.wantErrors) == 0) {
+				t.Errorf("Valid = %v, want %v", result.Valid, len(tt.wantErrors) == 0)
+			}
+		})
+	}
+}
+
+func TestWorkflowValidatorReferences(t *testing.T) {
+	tests := []struct {
+		name      string
+		outputs   []*models.WorkflowOutput
+		variables map[string]interface{}
+		wantCodes []string
+	}{
+		{
+			name:    "existing node output",
+			outputs: []*models.WorkflowOutput{{Name: "total", Expression: "nodes.a.out + b.output.out"}},
+		},
+		{
+			name:      "unknown node via nodes",
+			outputs:   []*models.WorkflowOutput{{Name: "total", Expression: "nodes.x.out"}},
+			wantCodes: []string{ValidationCodeUnknownReference},
+		},
+		{
+			name:      "unknown node via output",
+			outputs:   []*models.WorkflowOutput{{Name: "total", Expression: "x.output.data"}},
+			wantCodes: []string{ValidationCodeUnknownReference},
+		},
+		{
+			name:      "variable named like a node path",
+			outputs:   []*models.WorkflowOutput{{Name: "total", Expression: "x.output"}},
+			variables: map[string]interface{}{"x": map[string]interface{}{"output": 1}},
+		},
+		{
+			name:      "output node does not exist",
+			outputs:   []*models.WorkflowOutput{{Name: "total", NodeID: "x"}},
+			wantCodes: []string{ValidationCodeInvalidOutput},
+		},
+	}
+
+	validator := newTestValidator(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := &models.Workflow{
+				Nodes: map[string]*models.Node{
+					"a": {ID: "a", Plugin: "validator_test"},
+					"b": {ID: "b", Plugin: "validator_test"},
+				},
+				Edges:   []*models.Edge{{ID: "ab", FromNodeID: "a", ToNodeID: "b", Type: models.EdgeTypeNormal}},
+				Outputs: tt.outputs,
+			}
+			if tt.variables != nil {
+				workflow.Config = &models.WorkflowConfig{Variables: tt.variables}
+			}
+
+			result := validator.Validate(workflow)
+			codes := make([]string, 0, len(result.Errors))
+			for _, issue := range result.Errors {
+				codes = append(codes, issue.Code)
+			}
+			want := tt.wantCodes
+			if want == nil {
+				want = []string{}
+			}
+			if !reflect.DeepEqual(codes, want) {
+				t.Errorf("error codes = %v, want %v (errors: %+v)", codes, want, result.Errors)
+			}
+		})
+	}
+}
+
+func TestWorkflowServiceRejectsInvalidGraphBeforeSave(t *testing.T) {
+	registerValidatorTestPlugin(t)
+
+	// 未连接数据库：校验未拦截时保存会直接 panic
+	workflowService := NewWorkflowService(nil, nil)
+	workflow := &models.Workflow{
+		Name:   "orders",
+		Status: models.WorkflowStatusInactive,
+		Nodes: map[string]*models.Node{
+			"a": {ID: "a", Plugin: "validator_test"},
+			"b": {ID: "b", Plugin: "validator_test"},
+			"c": {ID: "c", Plugin: "no_such_plugin"},
+		},
+		Edges: []*models.Edge{
+			{ID: "a-b", FromNodeID: "a", ToNodeID: "b", Type: models.EdgeTypeNormal},
+			{ID: "b-a", FromNodeID: "b", ToNodeID: "a", Type: models.EdgeTypeNormal},
+			{ID: "b-x", FromNodeID: "b", ToNodeID: "x", Type: models.EdgeTypeNormal},
+		},
+	}
+
+	err := workflowService.CreateWorkflow(workflow)
+	var validationErr *WorkflowValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("CreateWorkflow() error = %v, want *WorkflowValidationError", err)
+	}
+
+	// 校验端点与保存使用同一结果，错误按节点或边定位
+	result := workflowService.ValidateWorkflow(workflow)
+	if !reflect.DeepEqual(result, validationErr.Result) {
+		t.Errorf("ValidateWorkflow() = %+v, want the result returned by CreateWorkflow()", result)
+	}
+	located := make(map[string]bool)
+	for _, issue := range result.Errors {
+		located[issue.Code+":"+issue.NodeID+issue.EdgeID] = true
+	}
+	for _, want := range []string{ValidationCodeDanglingEdge + ":b-x", ValidationCodeUnknownPlugin + ":c"} {
+		if !located[want] {
+			t.Errorf("errors = %v, want %s", located, want)
+		}
+	}
+	if !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("CreateWorkflow() error = %q, want the cycle reported", err)
+	}
+}